@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// snippet은 API가 반환하는 스니펫입니다. 필요한 필드만 디코딩합니다.
+type snippet struct {
+	ID      int       `json:"id"`
+	Author  string    `json:"author"`
+	Title   string    `json:"title"`
+	Content string    `json:"content"`
+	Tags    []string  `json:"tags"`
+	Created time.Time `json:"created"`
+	Expires time.Time `json:"expires"`
+}
+
+type pagination struct {
+	Page         int `json:"page"`
+	LastPage     int `json:"last_page"`
+	TotalRecords int `json:"total_records"`
+}
+
+// apiError는 cmd/web의 apiError와 같은 모양의 오류 응답입니다.
+type apiError struct {
+	Status   int               `json:"-"`
+	Message  string            `json:"error"`
+	Fields   map[string]string `json:"fields"`
+	NonField []string          `json:"non_field"`
+}
+
+func (e *apiError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = strings.ToLower(http.StatusText(e.Status))
+	}
+
+	// 필드 오류는 실행할 때마다 같은 순서로 출력되도록 정렬합니다.
+	fields := make([]string, 0, len(e.Fields))
+	for field := range e.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		msg += fmt.Sprintf("\n  %s: %s", field, e.Fields[field])
+	}
+	for _, s := range e.NonField {
+		msg += "\n  " + s
+	}
+
+	return msg
+}
+
+// client는 Snippetbox JSON API(/api/v1)를 호출합니다.
+type client struct {
+	server string
+	token  string
+	http   *http.Client
+}
+
+func newClient(cfg *config) *client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.Insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	return &client{
+		server: strings.TrimSuffix(cfg.Server, "/"),
+		token:  cfg.Token,
+		http:   &http.Client{Transport: transport, Timeout: 30 * time.Second},
+	}
+}
+
+// viewURL은 스니펫을 브라우저에서 볼 수 있는 URL을 반환합니다.
+func (c *client) viewURL(id int) string {
+	return fmt.Sprintf("%s/snippet/view/%d", c.server, id)
+}
+
+// do는 API 요청을 보내고 응답 본문을 dst로 디코딩합니다. in이 nil이 아니면 JSON 본문으로 보내고,
+// dst가 nil이면 응답 본문을 무시합니다. 2xx가 아닌 응답은 *apiError로 반환합니다.
+func (c *client) do(method, path string, in, dst any) error {
+	var body io.Reader
+	if in != nil {
+		js, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(js)
+	}
+
+	req, err := http.NewRequest(method, c.server+"/api/v1"+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	rs, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode < 200 || rs.StatusCode > 299 {
+		e := &apiError{Status: rs.StatusCode}
+		// 프록시 등이 JSON이 아닌 오류 페이지를 보낼 수도 있으므로 디코딩 오류는 무시합니다.
+		json.NewDecoder(rs.Body).Decode(e)
+		return e
+	}
+
+	if dst == nil {
+		return nil
+	}
+
+	return json.NewDecoder(rs.Body).Decode(dst)
+}
+
+func (c *client) create(title, content string, tags []string, expires int) (*snippet, error) {
+	if c.token == "" {
+		return nil, errNoToken
+	}
+
+	in := map[string]any{
+		"title":   title,
+		"content": content,
+		"tags":    tags,
+		"expires": expires,
+	}
+
+	var out struct {
+		Snippet *snippet `json:"snippet"`
+	}
+
+	err := c.do(http.MethodPost, "/snippets", in, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Snippet, nil
+}
+
+func (c *client) get(id int) (*snippet, error) {
+	var out struct {
+		Snippet *snippet `json:"snippet"`
+	}
+
+	err := c.do(http.MethodGet, "/snippets/"+strconv.Itoa(id), nil, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Snippet, nil
+}
+
+// list는 만료되지 않은 스니펫을 최신순으로 반환합니다. query가 비어 있지 않으면 검색 결과를 반환합니다.
+func (c *client) list(query string, page, pageSize int) ([]*snippet, pagination, error) {
+	qs := url.Values{}
+	if query != "" {
+		qs.Set("q", query)
+	}
+	qs.Set("page", strconv.Itoa(page))
+	qs.Set("page_size", strconv.Itoa(pageSize))
+
+	var out struct {
+		Snippets   []*snippet `json:"snippets"`
+		Pagination pagination `json:"pagination"`
+	}
+
+	err := c.do(http.MethodGet, "/snippets?"+qs.Encode(), nil, &out)
+	if err != nil {
+		return nil, pagination{}, err
+	}
+
+	return out.Snippets, out.Pagination, nil
+}
+
+func (c *client) delete(id int) error {
+	if c.token == "" {
+		return errNoToken
+	}
+
+	return c.do(http.MethodDelete, "/snippets/"+strconv.Itoa(id), nil, nil)
+}
+
+var errNoToken = errors.New("no API token configured; run 'snippet login' first")
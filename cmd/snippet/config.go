@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// 설정 파일이 없을 때 사용할 서버 주소입니다. cmd/web의 기본 -addr 값과 같습니다.
+const defaultServer = "https://localhost:4000"
+
+// config는 $XDG_CONFIG_HOME/snippetbox/config.json에 저장되는 CLI 설정입니다.
+// Insecure가 true이면 개발용 자체 서명 인증서를 검증하지 않습니다.
+type config struct {
+	Server   string `json:"server"`
+	Token    string `json:"token,omitempty"`
+	Insecure bool   `json:"insecure,omitempty"`
+}
+
+// configPath는 설정 파일의 경로를 반환합니다. os.UserConfigDir()은 리눅스에서
+// $XDG_CONFIG_HOME을 따르고, 설정되어 있지 않으면 ~/.config를 사용합니다.
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "snippetbox", "config.json"), nil
+}
+
+// loadConfig는 설정 파일을 읽습니다. 파일이 아직 없으면 기본 설정을 반환합니다.
+func loadConfig(path string) (*config, error) {
+	cfg := &config{Server: defaultServer}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// save는 설정을 파일에 씁니다. 토큰이 들어 있으므로 소유자만 읽을 수 있게 만듭니다.
+func (cfg *config) save(path string) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
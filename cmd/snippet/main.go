@@ -0,0 +1,264 @@
+// snippet 명령은 Snippetbox JSON API를 사용하는 명령줄 클라이언트입니다.
+//
+// 인수 없이 실행하면 표준 입력을 새 스니펫으로 올리고 URL을 출력하므로
+// "kubectl logs mypod | snippet"처럼 파이프로 연결해 쓸 수 있습니다.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const usage = `Usage:
+  snippet [create] [-title T] [-tags a,b] [-expires 1|7|365] [file...]
+  snippet get ID
+  snippet list [-page N] [-n N]
+  snippet search [-page N] [-n N] QUERY...
+  snippet delete ID
+  snippet login [-server URL] [-insecure]
+  snippet logout
+
+With no files, create reads the snippet from standard input.
+Configuration is stored in $XDG_CONFIG_HOME/snippetbox/config.json.
+`
+
+// 제목은 최대 100자까지 허용됩니다. cmd/web의 checkSnippet과 같은 값입니다.
+const maxTitleChars = 100
+
+func main() {
+	path, err := configPath()
+	if err == nil {
+		err = run(os.Args[1:], os.Stdin, os.Stdout, path)
+	}
+
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "snippet: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run은 args에 해당하는 하위 명령을 실행합니다. 테스트에서 표준 입출력과 설정 파일 경로를
+// 바꿀 수 있도록 main과 분리되어 있습니다.
+func run(args []string, stdin io.Reader, stdout io.Writer, cfgPath string) error {
+	cfg, err := loadConfig(cfgPath)
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+
+	cmd := "create"
+	if len(args) > 0 {
+		switch args[0] {
+		case "create", "get", "list", "search", "delete", "login", "logout":
+			cmd, args = args[0], args[1:]
+		case "help", "-h", "-help", "--help":
+			fmt.Fprint(stdout, usage)
+			return nil
+		}
+	}
+
+	c := newClient(cfg)
+
+	switch cmd {
+	case "get":
+		id, err := parseID(args)
+		if err != nil {
+			return err
+		}
+
+		s, err := c.get(id)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprint(stdout, s.Content)
+		if !strings.HasSuffix(s.Content, "\n") {
+			fmt.Fprintln(stdout)
+		}
+		return nil
+
+	case "list", "search":
+		fs := newFlagSet(cmd)
+		page := fs.Int("page", 1, "page number")
+		pageSize := fs.Int("n", 20, "snippets per page")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		query := strings.Join(fs.Args(), " ")
+		if cmd == "search" && query == "" {
+			return errors.New("search requires a query")
+		}
+
+		snippets, p, err := c.list(query, *page, *pageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range snippets {
+			fmt.Fprintf(stdout, "%d\t%s\t%s\n", s.ID, s.Created.Local().Format("2006-01-02 15:04"), s.Title)
+		}
+		if p.LastPage > p.Page {
+			fmt.Fprintf(stdout, "-- page %d of %d; use -page %d for more\n", p.Page, p.LastPage, p.Page+1)
+		}
+		return nil
+
+	case "delete":
+		id, err := parseID(args)
+		if err != nil {
+			return err
+		}
+
+		return c.delete(id)
+
+	case "login":
+		fs := newFlagSet(cmd)
+		server := fs.String("server", cfg.Server, "Snippetbox base URL")
+		insecure := fs.Bool("insecure", cfg.Insecure, "skip TLS certificate verification (self-signed development certificates)")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(stdout, "Create a token at %s/account/tokens and paste it here: ", strings.TrimSuffix(*server, "/"))
+
+		line, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		token := strings.TrimSpace(line)
+		if token == "" {
+			return errors.New("no token given")
+		}
+
+		cfg.Server, cfg.Token, cfg.Insecure = *server, token, *insecure
+
+		err = cfg.save(cfgPath)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(stdout, "\nSaved to %s\n", cfgPath)
+		return nil
+
+	case "logout":
+		cfg.Token = ""
+		return cfg.save(cfgPath)
+
+	default:
+		return create(c, args, stdin, stdout)
+	}
+}
+
+// create는 각 파일을, 파일이 없으면 표준 입력을 스니펫으로 올리고 URL을 한 줄씩 출력합니다.
+func create(c *client, args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("create")
+	title := fs.String("title", "", "snippet title (default: file name or first line)")
+	tags := fs.String("tags", "", "comma-separated tags")
+	expires := fs.Int("expires", 365, "days until the snippet expires: 1, 7 or 365")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var tagList []string
+	for _, tag := range strings.Split(*tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tagList = append(tagList, tag)
+		}
+	}
+
+	post := func(name string, r io.Reader) error {
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+
+		t := *title
+		if t == "" {
+			t = defaultTitle(name, string(content))
+		}
+
+		s, err := c.create(t, string(content), tagList, *expires)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(stdout, c.viewURL(s.ID))
+		return nil
+	}
+
+	if fs.NArg() == 0 {
+		return post("", stdin)
+	}
+
+	for _, name := range fs.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+
+		err = post(filepath.Base(name), f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// defaultTitle은 -title 플래그가 없을 때 사용할 제목을 정합니다. 파일 이름이 있으면 그것을,
+// 없으면 내용의 첫 번째 비어 있지 않은 줄을 최대 길이에 맞게 잘라 사용합니다.
+func defaultTitle(name, content string) string {
+	t := name
+
+	if t == "" {
+		for _, line := range strings.Split(content, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				t = line
+				break
+			}
+		}
+	}
+
+	if utf8.RuneCountInString(t) > maxTitleChars {
+		t = string([]rune(t)[:maxTitleChars-1]) + "…"
+	}
+
+	if t == "" {
+		t = "Untitled"
+	}
+
+	return t
+}
+
+func parseID(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, errors.New("expected exactly one snippet ID")
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid snippet ID %q", args[0])
+	}
+
+	return id, nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("snippet "+name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+	}
+	return fs
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"snippetbox.wook.net/internal/assert"
+)
+
+// newTestAPI는 API 요청을 기록하고 미리 정한 응답을 보내는 TLS 테스트 서버를 시작하고,
+// 그 서버를 가리키는 설정 파일의 경로를 반환합니다.
+func newTestAPI(t *testing.T, token string, handler http.HandlerFunc) string {
+	ts := httptest.NewTLSServer(handler)
+	t.Cleanup(ts.Close)
+
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	cfg := &config{Server: ts.URL, Token: token, Insecure: true}
+	err := cfg.save(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestCreateFromStdin(t *testing.T) {
+	var got map[string]any
+	var auth string
+
+	path := newTestAPI(t, "sbx_valid", func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"snippet":{"id":42}}`))
+	})
+
+	var stdout bytes.Buffer
+	err := run([]string{"-tags", "k8s, logs"}, strings.NewReader("\n  pod started\nready\n"), &stdout, path)
+	assert.NilError(t, err)
+
+	assert.Equal(t, auth, "Bearer sbx_valid")
+	assert.Equal(t, got["title"], any("pod started"))
+	assert.Equal(t, got["content"], any("\n  pod started\nready\n"))
+	assert.Equal(t, got["expires"], any(365.0))
+	assert.StringContains(t, stdout.String(), "/snippet/view/42\n")
+}
+
+func TestCreateWithoutToken(t *testing.T) {
+	path := newTestAPI(t, "", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	})
+
+	err := run(nil, strings.NewReader("hello"), &bytes.Buffer{}, path)
+	assert.Equal(t, err, errNoToken)
+}
+
+func TestAPIError(t *testing.T) {
+	path := newTestAPI(t, "sbx_valid", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"error":"validation failed","fields":{"title":"This field cannot be blank"}}`))
+	})
+
+	err := run([]string{"create"}, strings.NewReader("hello"), &bytes.Buffer{}, path)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	assert.Equal(t, err.Error(), "validation failed\n  title: This field cannot be blank")
+}
+
+func TestSearch(t *testing.T) {
+	var query string
+
+	path := newTestAPI(t, "", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"snippets":[{"id":1,"title":"An old silent pond","created":"2023-01-01T10:00:00Z"}],
+			"pagination":{"page":1,"last_page":2,"total_records":2}}`))
+	})
+
+	var stdout bytes.Buffer
+	err := run([]string{"search", "-n", "1", "silent", "pond"}, nil, &stdout, path)
+	assert.NilError(t, err)
+
+	assert.Equal(t, query, "page=1&page_size=1&q=silent+pond")
+	assert.StringContains(t, stdout.String(), "1\t")
+	assert.StringContains(t, stdout.String(), "\tAn old silent pond\n")
+	assert.StringContains(t, stdout.String(), "use -page 2 for more")
+}
+
+func TestLogin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snippetbox", "config.json")
+
+	err := run([]string{"login", "-server", "https://snippets.example.com"}, strings.NewReader("sbx_new\n"), &bytes.Buffer{}, path)
+	assert.NilError(t, err)
+
+	cfg, err := loadConfig(path)
+	assert.NilError(t, err)
+	assert.Equal(t, *cfg, config{Server: "https://snippets.example.com", Token: "sbx_new"})
+}
+
+func TestConfigPath(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" || runtime.GOOS == "plan9" {
+		t.Skip("XDG_CONFIG_HOME is only used on Unix-like systems")
+	}
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+
+	path, err := configPath()
+	assert.NilError(t, err)
+	assert.Equal(t, path, filepath.Join("/tmp/xdg", "snippetbox", "config.json"))
+}
+
+func TestDefaultTitle(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{name: "File name", file: "app.log", content: "first line", want: "app.log"},
+		{name: "First line", content: "\n\n  first line  \nsecond", want: "first line"},
+		{name: "Empty", content: " \n", want: "Untitled"},
+		{name: "Long line", content: strings.Repeat("가", 150), want: strings.Repeat("가", 99) + "…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, defaultTitle(tt.file, tt.content), tt.want)
+		})
+	}
+}
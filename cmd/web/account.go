@@ -0,0 +1,437 @@
+package main
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"snippetbox.wook.net/internal/models"
+	"snippetbox.wook.net/internal/validator"
+)
+
+// emailChangeTTL은 이메일 주소 변경 확인 링크의 유효 기간입니다.
+const emailChangeTTL = 24 * time.Hour
+
+type accountNameForm struct {
+	Name                string `form:"name"`
+	validator.Validator `form:"-"`
+}
+
+type accountEmailForm struct {
+	Email               string `form:"email"`
+	Password            string `form:"password"`
+	validator.Validator `form:"-"`
+}
+
+type accountDeleteForm struct {
+	Password            string `form:"password"`
+	Snippets            string `form:"snippets"`
+	validator.Validator `form:"-"`
+}
+
+type accountPasswordForm struct {
+	CurrentPassword     string `form:"current_password"`
+	NewPassword         string `form:"new_password"`
+	validator.Validator `form:"-"`
+}
+
+// accountForms는 계정 설정 페이지의 세 양식입니다. 한 양식을 제출하여 오류가 나더라도
+// 나머지 양식은 그대로 다시 표시됩니다.
+type accountForms struct {
+	Name     accountNameForm
+	Email    accountEmailForm
+	Password accountPasswordForm
+}
+
+func (app *application) renderAccount(w http.ResponseWriter, r *http.Request, status int, user *models.User, forms accountForms) {
+	if forms.Name.Name == "" && forms.Name.Valid() {
+		forms.Name.Name = user.Name
+	}
+
+	data := app.newTemplateData(r)
+	data.User = user
+	data.Form = forms
+	app.render(w, r, status, "account.go.tpl", data)
+}
+
+// currentUser는 로그인한 사용자를 반환합니다. protected 미들웨어 체인 뒤에서만 사용합니다.
+func (app *application) currentUser(r *http.Request) (*models.User, error) {
+	return app.users.Get(app.authenticatedUserID(r))
+}
+
+func (app *application) accountView(w http.ResponseWriter, r *http.Request) {
+	user, err := app.currentUser(r)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.renderAccount(w, r, http.StatusOK, user, accountForms{})
+}
+
+func (app *application) accountNamePost(w http.ResponseWriter, r *http.Request) {
+	user, err := app.currentUser(r)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	var form accountNameForm
+
+	err = app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Name), "name", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.Name, 255), "name", "This field cannot be more than 255 characters long")
+
+	if !form.Valid() {
+		app.renderAccount(w, r, http.StatusUnprocessableEntity, user, accountForms{Name: form})
+		return
+	}
+
+	err = app.users.UpdateName(user.ID, form.Name)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	// 계정 정보가 바뀔 때마다 로그인할 때와 같이 세션 ID를 새로 만듭니다.
+	err = app.renewSessionToken(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Your name has been updated.")
+
+	http.Redirect(w, r, "/account", http.StatusSeeOther)
+}
+
+func (app *application) accountPasswordPost(w http.ResponseWriter, r *http.Request) {
+	user, err := app.currentUser(r)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	var form accountPasswordForm
+
+	err = app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.CurrentPassword), "current_password", "This field cannot be blank")
+	form.CheckField(validator.NotBlank(form.NewPassword), "new_password", "This field cannot be blank")
+	form.CheckField(validator.MinChars(form.NewPassword, 8), "new_password", "This field must be at least 8 characters long")
+
+	if form.Valid() {
+		err = app.checkPassword(user, form.CurrentPassword)
+		if errors.Is(err, models.ErrInvalidCredentials) {
+			form.AddFieldError("current_password", "Password is incorrect")
+		} else if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	if !form.Valid() {
+		app.renderAccount(w, r, http.StatusUnprocessableEntity, user, accountForms{Password: form})
+		return
+	}
+
+	err = app.users.UpdatePassword(user.ID, form.NewPassword)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	// 예전 비밀번호로 로그인한 다른 세션은 모두 끝냅니다. 이 요청의 세션은 저장소에서
+	// 지워지지만 값은 그대로 있으므로 새 토큰으로 다시 저장되어 로그인 상태가 유지됩니다.
+	err = app.destroyUserSessions(r.Context(), user.ID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	err = app.renewSessionToken(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Your password has been changed. Any other sessions have been logged out.")
+
+	http.Redirect(w, r, "/account", http.StatusSeeOther)
+}
+
+// checkPassword는 password가 user의 현재 비밀번호인지 확인합니다. 일치하지 않으면
+// models.ErrInvalidCredentials를 반환합니다.
+func (app *application) checkPassword(user *models.User, password string) error {
+	id, err := app.users.Authenticate(user.Email, password)
+	if err != nil {
+		return err
+	}
+	if id != user.ID {
+		return models.ErrInvalidCredentials
+	}
+	return nil
+}
+
+func (app *application) accountEmailPost(w http.ResponseWriter, r *http.Request) {
+	user, err := app.currentUser(r)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	var form accountEmailForm
+
+	err = app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Email), "email", "This field cannot be blank")
+	form.CheckField(validator.Matches(form.Email, validator.EmailRX), "email", "This field must be a valid email address")
+	form.CheckField(form.Email != user.Email, "email", "This is already your email address")
+	form.CheckField(validator.NotBlank(form.Password), "password", "This field cannot be blank")
+
+	if form.Valid() {
+		err = app.checkPassword(user, form.Password)
+		if errors.Is(err, models.ErrInvalidCredentials) {
+			form.AddFieldError("password", "Password is incorrect")
+		} else if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	if form.Valid() {
+		_, err = app.users.GetByEmail(form.Email)
+		if err == nil {
+			form.AddFieldError("email", "Email address is already in use")
+		} else if !errors.Is(err, models.ErrNoRecord) {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	if !form.Valid() {
+		app.renderAccount(w, r, http.StatusUnprocessableEntity, user, accountForms{Email: form})
+		return
+	}
+
+	// 주소는 새 주소로 보낸 확인 링크를 열었을 때 바뀝니다.
+	link, err := app.mailURL("/account/email/confirm?token=")
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	token := app.newEmailChangeToken(user, form.Email, time.Now())
+
+	err = app.sendEmail(form.Email, "email_change.tmpl", map[string]string{
+		"Name": user.Name,
+		"Link": link + url.QueryEscape(token),
+	})
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", fmt.Sprintf("We've sent a confirmation link to %s. Your email address will change once you open it.", form.Email))
+
+	http.Redirect(w, r, "/account", http.StatusSeeOther)
+}
+
+// newEmailChangeToken은 "<사용자 ID>.<만료 시각>.<새 주소>.<서명>" 형식의 토큰을 만듭니다.
+// 새 주소는 base64url로 인코딩합니다. 현재 주소도 서명하므로 주소를 한 번 바꾸고 나면 같은
+// 시점에 보낸 다른 확인 링크는 모두 무효가 됩니다.
+func (app *application) newEmailChangeToken(user *models.User, newEmail string, now time.Time) string {
+	expires := now.Add(emailChangeTTL).Unix()
+	sig := app.linkMAC("change-email", user.ID, expires, user.Email, newEmail)
+
+	return fmt.Sprintf("%d.%d.%s.%s", user.ID, expires,
+		base64.RawURLEncoding.EncodeToString([]byte(newEmail)),
+		base64.RawURLEncoding.EncodeToString(sig))
+}
+
+// checkEmailChangeToken은 토큰을 확인하고 사용자와 새 이메일 주소를 반환합니다.
+// 토큰이 올바르지 않으면 models.ErrNoRecord를 반환합니다.
+func (app *application) checkEmailChangeToken(token string, now time.Time) (*models.User, string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return nil, "", models.ErrNoRecord
+	}
+
+	userID, err := strconv.Atoi(parts[0])
+	if err != nil || userID < 1 {
+		return nil, "", models.ErrNoRecord
+	}
+
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() > expires {
+		return nil, "", models.ErrNoRecord
+	}
+
+	newEmail, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, "", models.ErrNoRecord
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, "", models.ErrNoRecord
+	}
+
+	user, err := app.users.Get(userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !hmac.Equal(sig, app.linkMAC("change-email", user.ID, expires, user.Email, string(newEmail))) {
+		return nil, "", models.ErrNoRecord
+	}
+
+	return user, string(newEmail), nil
+}
+
+// accountEmailConfirm은 확인 링크로 이메일 주소를 바꿉니다. 링크는 다른 브라우저에서 열 수도
+// 있으므로 로그인하지 않아도 되며, 결과는 계정 페이지의 플래시 메시지로 알려 줍니다.
+func (app *application) accountEmailConfirm(w http.ResponseWriter, r *http.Request) {
+	user, newEmail, err := app.checkEmailChangeToken(r.URL.Query().Get("token"), time.Now())
+	if err == nil {
+		err = app.users.UpdateEmail(user.ID, newEmail)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNoRecord):
+			app.sessionManager.Put(r.Context(), "flash", "This confirmation link is invalid or has expired.")
+		case errors.Is(err, models.ErrDupliacteEmail):
+			app.sessionManager.Put(r.Context(), "flash", "That email address is already in use by another account.")
+		default:
+			app.serverError(w, r, err)
+			return
+		}
+		http.Redirect(w, r, "/account", http.StatusSeeOther)
+		return
+	}
+
+	err = app.renewSessionToken(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	// 주소가 바뀌었다는 사실을 예전 주소로도 알려서 본인이 바꾸지 않았다면 알아챌 수 있게 합니다.
+	err = app.sendEmail(user.Email, "email_changed.tmpl", map[string]string{
+		"Name":     user.Name,
+		"NewEmail": newEmail,
+	})
+	if err != nil {
+		app.errorLog.Print(err)
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", fmt.Sprintf("Your email address has been changed to %s.", newEmail))
+
+	http.Redirect(w, r, "/account", http.StatusSeeOther)
+}
+
+// accountExport는 사용자의 모든 데이터를 JSON 파일로 내려받게 합니다.
+func (app *application) accountExport(w http.ResponseWriter, r *http.Request) {
+	export, err := app.users.Export(app.authenticatedUserID(r))
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="snippetbox-account-%d.json"`, export.ID))
+	w.Header().Set("Cache-Control", "no-store")
+	app.writeJSON(w, http.StatusOK, export)
+}
+
+func (app *application) accountDelete(w http.ResponseWriter, r *http.Request) {
+	data := app.newTemplateData(r)
+	data.Form = accountDeleteForm{Snippets: "delete"}
+	app.render(w, r, http.StatusOK, "account_delete.go.tpl", data)
+}
+
+// accountDeletePost는 비밀번호를 확인한 뒤 계정을 영구히 삭제하고 사용자의 모든 세션을
+// 끝냅니다. 사용자의 스니펫은 선택에 따라 함께 삭제하거나 작성자 없는 스니펫으로 남깁니다.
+func (app *application) accountDeletePost(w http.ResponseWriter, r *http.Request) {
+	user, err := app.currentUser(r)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	var form accountDeleteForm
+
+	err = app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.PermittedValue(form.Snippets, "delete", "anonymise"), "snippets", "Choose what to do with your snippets")
+	form.CheckField(validator.NotBlank(form.Password), "password", "This field cannot be blank")
+
+	if form.Valid() {
+		err = app.checkPassword(user, form.Password)
+		if errors.Is(err, models.ErrInvalidCredentials) {
+			form.AddFieldError("password", "Password is incorrect")
+		} else if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	if !form.Valid() {
+		data := app.newTemplateData(r)
+		data.Form = form
+		app.render(w, r, http.StatusUnprocessableEntity, "account_delete.go.tpl", data)
+		return
+	}
+
+	err = app.users.Delete(user.ID, form.Snippets == "anonymise")
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	err = app.destroyUserSessions(r.Context(), user.ID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	// destroyUserSessions()가 지운 이 요청의 세션은 값이 남아 있으므로 로그아웃할 때와
+	// 같이 토큰을 새로 만들고 사용자 ID를 지운 뒤에 플래시 메시지를 남깁니다.
+	err = app.sessionManager.RenewToken(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	err = app.logoutSession(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Your account has been deleted.")
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
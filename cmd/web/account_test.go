@@ -0,0 +1,321 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"snippetbox.wook.net/internal/assert"
+	"snippetbox.wook.net/internal/models"
+)
+
+// sessionCookie는 테스트 서버 클라이언트가 가진 세션 쿠키의 값을 반환합니다.
+func (ts *testServer) sessionCookie(t *testing.T) string {
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range ts.Client().Jar.Cookies(u) {
+		if c.Name == "session" {
+			return c.Value
+		}
+	}
+	return ""
+}
+
+func TestAccountView(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, header, _ := ts.get(t, "/account")
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/user/login")
+
+	ts.login(t)
+
+	code, _, body := ts.get(t, "/account")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<td>Alice Jones</td>")
+	assert.StringContains(t, body, "<td>alice@example.com</td>")
+	assert.StringContains(t, body, "<td>01 Jan 2022 at 09:00</td>")
+	assert.StringContains(t, body, "<input type='text' name='name' value='Alice Jones'>")
+}
+
+func TestAccountNamePost(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	csrfToken := ts.login(t)
+
+	tests := []struct {
+		name     string
+		userName string
+		wantCode int
+	}{
+		{"Empty name", "", http.StatusUnprocessableEntity},
+		{"Long name", strings.Repeat("a", 256), http.StatusUnprocessableEntity},
+		{"Valid name", "Alice Smith", http.StatusSeeOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := ts.sessionCookie(t)
+
+			form := url.Values{}
+			form.Add("name", tt.userName)
+			form.Add("csrf_token", csrfToken)
+
+			code, _, body := ts.postForm(t, "/account/name", form)
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantCode == http.StatusSeeOther {
+				if ts.sessionCookie(t) == before {
+					t.Error("session token was not renewed")
+				}
+			} else {
+				assert.StringContains(t, body, "<form action='/account/name' method='POST' novalidate>")
+			}
+		})
+	}
+}
+
+func TestAccountPasswordPost(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	other := newTestServer(t, app.routes())
+	defer other.Close()
+	other.login(t)
+
+	csrfToken := ts.login(t)
+
+	tests := []struct {
+		name            string
+		currentPassword string
+		newPassword     string
+		wantCode        int
+		wantError       string
+	}{
+		{"Wrong current password", "wrongPa$$word", "newPa$$word", http.StatusUnprocessableEntity, "Password is incorrect"},
+		{"Empty current password", "", "newPa$$word", http.StatusUnprocessableEntity, "This field cannot be blank"},
+		{"Short new password", "pa$$word", "pa$$", http.StatusUnprocessableEntity, "This field must be at least 8 characters long"},
+		{"Valid submission", "pa$$word", "newPa$$word", http.StatusSeeOther, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("current_password", tt.currentPassword)
+			form.Add("new_password", tt.newPassword)
+			form.Add("csrf_token", csrfToken)
+
+			code, _, body := ts.postForm(t, "/account/password", form)
+			assert.Equal(t, code, tt.wantCode)
+			if tt.wantError != "" {
+				assert.StringContains(t, body, tt.wantError)
+			}
+		})
+	}
+
+	// 비밀번호를 바꾼 세션은 로그인 상태를 유지하고, 다른 세션은 로그아웃됩니다.
+	code, _, body := ts.get(t, "/account")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "Any other sessions have been logged out.")
+
+	code, _, _ = other.get(t, "/account")
+	assert.Equal(t, code, http.StatusSeeOther)
+}
+
+func TestAccountEmailChange(t *testing.T) {
+	app := newTestApplication(t)
+	outbox := newRecordingOutboxModel()
+	app.outbox = outbox
+	app.baseURL = "https://snippets.example.com"
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	csrfToken := ts.login(t)
+
+	tests := []struct {
+		name      string
+		email     string
+		password  string
+		wantCode  int
+		wantError string
+	}{
+		{"Wrong password", "alice@example.org", "wrongPa$$word", http.StatusUnprocessableEntity, "Password is incorrect"},
+		{"Same address", "alice@example.com", "pa$$word", http.StatusUnprocessableEntity, "This is already your email address"},
+		{"Address in use", "unverified@example.com", "pa$$word", http.StatusUnprocessableEntity, "Email address is already in use"},
+		{"Invalid address", "alice@example.", "pa$$word", http.StatusUnprocessableEntity, "This field must be a valid email address"},
+		{"Valid submission", "alice@example.org", "pa$$word", http.StatusSeeOther, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("email", tt.email)
+			form.Add("password", tt.password)
+			form.Add("csrf_token", csrfToken)
+
+			code, _, body := ts.postForm(t, "/account/email", form)
+			assert.Equal(t, code, tt.wantCode)
+			if tt.wantError != "" {
+				assert.StringContains(t, body, tt.wantError)
+			}
+		})
+	}
+
+	// 확인 메일은 새 주소로만 보내고, 주소는 링크를 열기 전까지 바뀌지 않습니다.
+	assert.Equal(t, len(outbox.enqueued), 1)
+	assert.Equal(t, outbox.enqueued[0].To, "alice@example.org")
+
+	_, _, body := ts.get(t, "/account")
+	assert.StringContains(t, body, "a confirmation link to alice@example.org.")
+	assert.StringContains(t, body, "<td>alice@example.com</td>")
+
+	_, link, _ := strings.Cut(outbox.enqueued[0].TextBody, "https://snippets.example.com")
+	link, _, _ = strings.Cut(link, "\n")
+	assert.StringContains(t, link, "/account/email/confirm?token=")
+
+	before := ts.sessionCookie(t)
+
+	code, header, _ := ts.get(t, link)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/account")
+	if ts.sessionCookie(t) == before {
+		t.Error("session token was not renewed")
+	}
+
+	_, _, body = ts.get(t, "/account")
+	assert.StringContains(t, body, "Your email address has been changed to alice@example.org.")
+
+	// 예전 주소로 변경 알림을 보냅니다.
+	assert.Equal(t, len(outbox.enqueued), 2)
+	assert.Equal(t, outbox.enqueued[1].To, "alice@example.com")
+	assert.StringContains(t, outbox.enqueued[1].TextBody, "has been changed to alice@example.org")
+}
+
+func TestAccountEmailConfirmInvalid(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	alice, err := app.users.Get(1)
+	assert.NilError(t, err)
+
+	now := time.Now()
+	valid := app.newEmailChangeToken(alice, "alice@example.org", now)
+	parts := strings.Split(valid, ".")
+
+	tests := []struct {
+		name      string
+		token     string
+		wantFlash string
+	}{
+		{"Expired token", app.newEmailChangeToken(alice, "alice@example.org", now.Add(-emailChangeTTL-time.Minute)), "This confirmation link is invalid or has expired."},
+		{"Other address", strings.Join([]string{parts[0], parts[1], "ZXZpbEBleGFtcGxlLmNvbQ", parts[3]}, "."), "This confirmation link is invalid or has expired."},
+		{"Verification token", app.newVerificationToken(1, "alice@example.com", now), "This confirmation link is invalid or has expired."},
+		{"Address taken", app.newEmailChangeToken(alice, "dupe@example.com", now), "That email address is already in use by another account."},
+	}
+
+	ts.login(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, header, _ := ts.get(t, "/account/email/confirm?token="+url.QueryEscape(tt.token))
+			assert.Equal(t, code, http.StatusSeeOther)
+			assert.Equal(t, header.Get("Location"), "/account")
+
+			_, _, body := ts.get(t, "/account")
+			assert.StringContains(t, body, tt.wantFlash)
+			assert.StringContains(t, body, "<td>alice@example.com</td>")
+		})
+	}
+}
+
+func TestAccountExport(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, header, _ := ts.get(t, "/account/export")
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/user/login")
+
+	ts.login(t)
+
+	code, header, body := ts.get(t, "/account/export")
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, header.Get("Content-Type"), "application/json")
+	assert.Equal(t, header.Get("Content-Disposition"), `attachment; filename="snippetbox-account-1.json"`)
+
+	var export models.UserExport
+	err := json.Unmarshal([]byte(body), &export)
+	assert.NilError(t, err)
+	assert.Equal(t, export.Email, "alice@example.com")
+	assert.Equal(t, len(export.Snippets), 1)
+	assert.Equal(t, export.Snippets[0].Title, "An old silent pond")
+}
+
+func TestAccountDeletePost(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	other := newTestServer(t, app.routes())
+	defer other.Close()
+	other.login(t)
+
+	csrfToken := ts.login(t)
+
+	code, _, body := ts.get(t, "/account/delete")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<a href='/account/export'>download all your data</a>")
+
+	tests := []struct {
+		name      string
+		password  string
+		snippets  string
+		wantCode  int
+		wantError string
+	}{
+		{"Wrong password", "wrongPa$$word", "delete", http.StatusUnprocessableEntity, "Password is incorrect"},
+		{"Empty password", "", "delete", http.StatusUnprocessableEntity, "This field cannot be blank"},
+		{"Invalid choice", "pa$$word", "keep", http.StatusUnprocessableEntity, "Choose what to do with your snippets"},
+		{"Valid submission", "pa$$word", "anonymise", http.StatusSeeOther, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("password", tt.password)
+			form.Add("snippets", tt.snippets)
+			form.Add("csrf_token", csrfToken)
+
+			code, header, body := ts.postForm(t, "/account/delete", form)
+			assert.Equal(t, code, tt.wantCode)
+			if tt.wantError != "" {
+				assert.StringContains(t, body, tt.wantError)
+			} else {
+				assert.Equal(t, header.Get("Location"), "/")
+			}
+		})
+	}
+
+	// 계정을 삭제한 세션과 다른 세션 모두 로그아웃됩니다.
+	_, _, body = ts.get(t, "/")
+	assert.StringContains(t, body, "Your account has been deleted.")
+
+	code, _, _ = ts.get(t, "/account")
+	assert.Equal(t, code, http.StatusSeeOther)
+
+	code, _, _ = other.get(t, "/account")
+	assert.Equal(t, code, http.StatusSeeOther)
+}
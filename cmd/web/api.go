@@ -0,0 +1,363 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"snippetbox.wook.net/internal/models"
+	"snippetbox.wook.net/internal/validator"
+	"snippetbox.wook.net/ui"
+)
+
+// JSON 요청 본문의 최대 크기입니다.
+const maxJSONBodySize = 1_048_576
+
+// apiError는 모든 API 오류 응답의 본문입니다. Fields와 NonField는
+// validator.Validator의 오류를 그대로 옮긴 것으로, 유효성 검사 실패 시에만 채워집니다.
+type apiError struct {
+	Error    string            `json:"error"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	NonField []string          `json:"non_field,omitempty"`
+}
+
+// pagination은 목록 응답에 포함되는 페이지 정보입니다.
+type pagination struct {
+	Page         int `json:"page"`
+	PageSize     int `json:"page_size"`
+	LastPage     int `json:"last_page"`
+	TotalRecords int `json:"total_records"`
+}
+
+func newPagination(page, pageSize, total int) pagination {
+	lastPage := (total + pageSize - 1) / pageSize
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	return pagination{
+		Page:         page,
+		PageSize:     pageSize,
+		LastPage:     lastPage,
+		TotalRecords: total,
+	}
+}
+
+// snippetInput은 스니펫을 만들거나 수정하는 요청의 본문입니다. 수정 요청에서는
+// 생략한 필드를 기존 값으로 유지할 수 있도록 포인터를 사용합니다.
+type snippetInput struct {
+	Title   *string   `json:"title"`
+	Content *string   `json:"content"`
+	Tags    *[]string `json:"tags"`
+	Expires *int      `json:"expires"`
+}
+
+func (app *application) apiErrorResponse(w http.ResponseWriter, status int, message string) {
+	app.writeJSON(w, status, apiError{Error: message})
+}
+
+func (app *application) apiNotFound(w http.ResponseWriter) {
+	app.apiErrorResponse(w, http.StatusNotFound, "the requested resource could not be found")
+}
+
+// apiValidationError는 validator.Validator에 쌓인 오류를 422 응답으로 보냅니다.
+func (app *application) apiValidationError(w http.ResponseWriter, v validator.Validator) {
+	app.writeJSON(w, http.StatusUnprocessableEntity, apiError{
+		Error:    "validation failed",
+		Fields:   v.FieldErrors,
+		NonField: v.NonFieldErrors,
+	})
+}
+
+// readJSON은 요청 본문을 dst로 디코딩합니다. Content-Type이 application/json이 아니거나,
+// 본문이 너무 크거나, 알 수 없는 필드가 있으면 클라이언트에게 보여줄 수 있는 오류를 반환합니다.
+//
+// API 경로는 noSurf를 거치지 않으므로 Content-Type 검사가 CSRF 방어 역할을 합니다.
+// 다른 출처의 HTML 양식은 application/json 본문을 보낼 수 없습니다.
+func (app *application) readJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return errors.New("Content-Type must be application/json")
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodySize)
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	err = dec.Decode(dst)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+
+		switch {
+		case errors.As(err, &maxBytesError):
+			return fmt.Errorf("body must not be larger than %d bytes", maxBytesError.Limit)
+		case errors.Is(err, io.EOF):
+			return errors.New("body must not be empty")
+		default:
+			return fmt.Errorf("body contains badly-formed JSON: %w", err)
+		}
+	}
+
+	if dec.More() {
+		return errors.New("body must only contain a single JSON value")
+	}
+
+	return nil
+}
+
+// invalidTokenResponse는 잘못된 Bearer 토큰에 대해 RFC 6750 형식의 401 응답을 보냅니다.
+func (app *application) invalidTokenResponse(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	app.apiErrorResponse(w, http.StatusUnauthorized, "invalid or missing authentication token")
+}
+
+// requireAPIAuthentication은 requireAuthentication과 같지만 로그인 페이지로 리디렉션하는 대신
+// 401 JSON 응답을 보냅니다.
+func (app *application) requireAPIAuthentication(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !app.isAuthenticated(r) {
+			app.apiErrorResponse(w, http.StatusUnauthorized, "you must be authenticated to access this resource")
+			return
+		}
+
+		w.Header().Add("Cache-Control", "no-store")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiSnippet은 URL의 :id 매개변수에 해당하는 스니펫을 가져옵니다. 찾지 못하면
+// JSON 오류 응답을 보낸 뒤 false를 반환합니다.
+func (app *application) apiSnippet(w http.ResponseWriter, r *http.Request) (*models.Snippet, bool) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	id, err := strconv.Atoi(params.ByName("id"))
+	if err != nil || id < 1 {
+		app.apiNotFound(w)
+		return nil, false
+	}
+
+	snippet, err := app.snippets.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.apiNotFound(w)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return nil, false
+	}
+
+	return snippet, true
+}
+
+// apiOwnedSnippet은 apiSnippet과 같지만 현재 사용자가 작성한 스니펫만 허용합니다.
+func (app *application) apiOwnedSnippet(w http.ResponseWriter, r *http.Request) (*models.Snippet, bool) {
+	snippet, ok := app.apiSnippet(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	if snippet.UserID == 0 || snippet.UserID != app.authenticatedUserID(r) {
+		app.apiErrorResponse(w, http.StatusForbidden, "you do not have permission to modify this snippet")
+		return nil, false
+	}
+
+	return snippet, true
+}
+
+// apiOpenAPI는 ui.Files에 포함된 OpenAPI 3 문서를 그대로 제공합니다.
+func (app *application) apiOpenAPI(w http.ResponseWriter, r *http.Request) {
+	spec, err := fs.ReadFile(ui.Files, "api/openapi.json")
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(spec)
+}
+
+// apiSnippetList는 GET /api/v1/snippets?q=&page=&page_size= 요청을 처리합니다.
+// q가 주어지면 제목이나 내용에 q가 포함된 스니펫만 반환합니다.
+func (app *application) apiSnippetList(w http.ResponseWriter, r *http.Request) {
+	var v validator.Validator
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	page := readInt(r, "page", 1, &v)
+	pageSize := readInt(r, "page_size", 20, &v)
+
+	v.CheckField(page >= 1, "page", "This field must be greater than zero")
+	v.CheckField(page <= 10_000, "page", "This field must be a maximum of 10000")
+	v.CheckField(pageSize >= 1, "page_size", "This field must be greater than zero")
+	v.CheckField(pageSize <= 100, "page_size", "This field must be a maximum of 100")
+	v.CheckField(validator.MaxChars(query, 100), "q", "This field cannot be more than 100 characters long")
+
+	if !v.Valid() {
+		app.apiValidationError(w, v)
+		return
+	}
+
+	snippets, total, err := app.snippets.List(query, page, pageSize)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, map[string]any{
+		"snippets":   snippets,
+		"pagination": newPagination(page, pageSize, total),
+	})
+}
+
+func (app *application) apiSnippetGet(w http.ResponseWriter, r *http.Request) {
+	snippet, ok := app.apiSnippet(w, r)
+	if !ok {
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, map[string]any{"snippet": snippet})
+}
+
+func (app *application) apiSnippetCreate(w http.ResponseWriter, r *http.Request) {
+	var input snippetInput
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.apiErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var v validator.Validator
+
+	title, content, tags := "", "", []string{}
+	if input.Title != nil {
+		title = *input.Title
+	}
+	if input.Content != nil {
+		content = *input.Content
+	}
+	if input.Tags != nil {
+		tags = parseTags(strings.Join(*input.Tags, ","))
+	}
+	expires := 365
+	if input.Expires != nil {
+		expires = *input.Expires
+	}
+
+	checkSnippet(&v, title, content, tags)
+	v.CheckField(validator.PermittedValue(expires, 1, 7, 365), "expires", "This field must equal 1, 7 or 365")
+
+	if !v.Valid() {
+		app.apiValidationError(w, v)
+		return
+	}
+
+	id, err := app.snippets.Insert(app.authenticatedUserID(r), title, content, expires, tags)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	snippet := insertedSnippet(id, app.authenticatedUserID(r), title, content, tags, expires)
+	app.snippetEvent(models.EventSnippetCreated, snippet)
+	app.publishSnippet(snippet)
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/snippets/%d", id))
+	app.writeJSON(w, http.StatusCreated, map[string]any{"snippet": snippet})
+}
+
+// apiSnippetUpdate는 PATCH 요청을 처리합니다. 본문에 포함된 필드만 변경되며
+// 만료 시각은 변경할 수 없습니다.
+func (app *application) apiSnippetUpdate(w http.ResponseWriter, r *http.Request) {
+	current, ok := app.apiOwnedSnippet(w, r)
+	if !ok {
+		return
+	}
+
+	var input snippetInput
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.apiErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// 모델이 반환한 값을 직접 바꾸지 않도록 복사본을 수정합니다.
+	snippet := *current
+
+	var v validator.Validator
+
+	if input.Title != nil {
+		snippet.Title = *input.Title
+	}
+	if input.Content != nil {
+		snippet.Content = *input.Content
+	}
+	if input.Tags != nil {
+		snippet.Tags = parseTags(strings.Join(*input.Tags, ","))
+	}
+	v.CheckField(input.Expires == nil, "expires", "This field cannot be changed")
+
+	checkSnippet(&v, snippet.Title, snippet.Content, snippet.Tags)
+
+	if !v.Valid() {
+		app.apiValidationError(w, v)
+		return
+	}
+
+	err = app.snippets.Update(snippet.ID, snippet.Title, snippet.Content, snippet.Tags)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.snippetEvent(models.EventSnippetUpdated, &snippet)
+
+	app.writeJSON(w, http.StatusOK, map[string]any{"snippet": &snippet})
+}
+
+func (app *application) apiSnippetDelete(w http.ResponseWriter, r *http.Request) {
+	snippet, ok := app.apiOwnedSnippet(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.snippets.Delete(snippet.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.apiNotFound(w)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return
+	}
+
+	app.snippetEvent(models.EventSnippetDeleted, snippet)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readInt는 쿼리 문자열의 key 값을 정수로 읽습니다. 값이 없으면 defaultValue를 반환하고,
+// 정수가 아니면 v에 필드 오류를 추가합니다.
+func readInt(r *http.Request, key string, defaultValue int, v *validator.Validator) int {
+	s := r.URL.Query().Get(key)
+	if s == "" {
+		return defaultValue
+	}
+
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		v.AddFieldError(key, "This field must be an integer value")
+		return defaultValue
+	}
+
+	return i
+}
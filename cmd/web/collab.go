@@ -0,0 +1,639 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"github.com/gorilla/websocket"
+	"github.com/julienschmidt/httprouter"
+	"snippetbox.wook.net/internal/models"
+	"snippetbox.wook.net/internal/ot"
+	"snippetbox.wook.net/internal/validator"
+)
+
+const (
+	// 클라이언트마다 쌓아 둘 수 있는 최대 메시지 수입니다. 이보다 느린 클라이언트는 연결이 끊깁니다.
+	collabSendBuffer = 64
+	// 공동 편집 문서의 최대 길이입니다. 저장할 수 있도록 snippets.content 열과 같이
+	// UTF-8 바이트 수로 셉니다. UTF-16 코드 단위 하나는 UTF-8로 1바이트 이상이므로 문서의
+	// 코드 단위 수도 이 값을 넘지 않으며, ot.MaxLength보다 작아야 합니다.
+	collabMaxLength = maxPasteSize
+	// 세션이 기억하는 최대 연산 수입니다. 이보다 오래된 리비전을 기준으로 한 연산은 변환할 수
+	// 없습니다. 그만큼 뒤처진 클라이언트는 collabSendBuffer가 넘쳐 이미 연결이 끊겼을 것이므로
+	// collabSendBuffer보다 넉넉하게 잡습니다.
+	collabMaxHistory = 4 * collabSendBuffer
+	// 클라이언트가 보내는 메시지 하나의 최대 크기입니다.
+	collabMaxMessageSize = maxJSONBodySize
+	// 이 시간 동안 아무 메시지도 받지 못하면 연결이 끊긴 것으로 봅니다.
+	collabPongWait = 60 * time.Second
+	// 연결을 확인하기 위해 ping을 보내는 주기로, collabPongWait보다 짧아야 합니다.
+	collabPingInterval = 25 * time.Second
+	// 쓰기 한 번에 허용하는 최대 시간입니다.
+	collabWriteTimeout = 10 * time.Second
+)
+
+// collabMessage는 클라이언트가 보내는 메시지입니다. Rev는 클라이언트가 알고 있는
+// 마지막 서버 리비전으로, Op와 커서 위치는 그 리비전의 문서를 기준으로 합니다.
+type collabMessage struct {
+	Type string        `json:"type"`
+	Rev  int           `json:"rev"`
+	Op   *ot.Operation `json:"op"`
+	Pos  int           `json:"pos"`
+	End  int           `json:"end"`
+}
+
+// collabPeer는 같은 문서를 편집하고 있는 다른 사용자와 그 커서(선택 영역)입니다.
+type collabPeer struct {
+	Client int    `json:"client"`
+	Name   string `json:"name"`
+	Pos    int    `json:"pos"`
+	End    int    `json:"end"`
+}
+
+// collabEvent는 서버가 클라이언트에게 보내는 메시지입니다.
+//
+//	init   연결 직후 현재 문서와 리비전, 다른 편집자 목록
+//	ack    보낸 연산이 Rev 리비전으로 적용됨
+//	op     다른 편집자의 연산이 Rev 리비전으로 적용됨
+//	cursor 다른 편집자의 커서가 Rev 리비전 기준 Pos-End로 옮겨짐
+//	join   다른 편집자가 들어옴
+//	leave  다른 편집자가 나감
+//	saved  Rev 리비전의 문서가 스니펫 리비전 Revision으로 저장됨
+//	error  Message 참고
+type collabEvent struct {
+	Type     string        `json:"type"`
+	Rev      int           `json:"rev"`
+	Client   int           `json:"client,omitempty"`
+	Name     string        `json:"name,omitempty"`
+	Op       *ot.Operation `json:"op,omitempty"`
+	Pos      int           `json:"pos"`
+	End      int           `json:"end"`
+	Content  *string       `json:"content,omitempty"`
+	Peers    []collabPeer  `json:"peers,omitempty"`
+	CanSave  bool          `json:"canSave,omitempty"`
+	Revision int           `json:"revision,omitempty"`
+	Message  string        `json:"message,omitempty"`
+}
+
+type collabClient struct {
+	id       int
+	userID   int
+	name     string
+	canSave  bool
+	pos, end int
+	send     chan []byte
+}
+
+// collabRoom은 한 스니펫의 공동 편집 세션입니다. 서버가 문서의 기준 사본과 적용된 연산의
+// 기록을 갖고, 클라이언트가 오래된 리비전을 기준으로 보낸 연산은 그 이후의 연산에 맞춰
+// 변환한 뒤 적용합니다. history[i]는 리비전 base+i를 리비전 base+i+1로 바꾸는 연산이며,
+// 기록은 collabMaxHistory개 안팎으로 유지합니다.
+type collabRoom struct {
+	mu        sync.Mutex
+	snippetID int
+	doc       []uint16
+	history   []*ot.Operation
+	base      int
+	clients   map[int]*collabClient
+	nextID    int
+}
+
+// collabHub는 진행 중인 공동 편집 세션을 스니펫 ID별로 관리합니다. 세션은 첫 편집자가
+// 들어올 때 스니펫의 현재 내용으로 만들어지고, 마지막 편집자가 나가면 사라집니다.
+// 저장하지 않은 편집 내용은 세션과 함께 사라집니다.
+type collabHub struct {
+	mu    sync.Mutex
+	rooms map[int]*collabRoom
+}
+
+func newCollabHub() *collabHub {
+	return &collabHub{
+		rooms: make(map[int]*collabRoom),
+	}
+}
+
+// join은 스니펫의 편집 세션에 새 클라이언트를 추가하고 init 메시지를 보냅니다.
+// 잠금은 항상 허브, 세션 순서로 잡습니다.
+func (h *collabHub) join(snippet *models.Snippet, userID int, name string, canSave bool) (*collabRoom, *collabClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	room, ok := h.rooms[snippet.ID]
+	if !ok {
+		room = &collabRoom{
+			snippetID: snippet.ID,
+			doc:       utf16.Encode([]rune(snippet.Content)),
+			clients:   make(map[int]*collabClient),
+		}
+		h.rooms[snippet.ID] = room
+	}
+
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	room.nextID++
+	c := &collabClient{
+		id:      room.nextID,
+		userID:  userID,
+		name:    name,
+		canSave: canSave,
+		send:    make(chan []byte, collabSendBuffer),
+	}
+
+	peers := []collabPeer{}
+	for _, other := range room.clients {
+		peers = append(peers, collabPeer{Client: other.id, Name: other.name, Pos: other.pos, End: other.end})
+	}
+
+	room.clients[c.id] = c
+
+	content := string(utf16.Decode(room.doc))
+	room.sendLocked(c, collabEvent{Type: "init", Rev: room.revLocked(), Client: c.id, Content: &content, Peers: peers, CanSave: canSave})
+	room.broadcastLocked(c, collabEvent{Type: "join", Rev: room.revLocked(), Client: c.id, Name: c.name})
+
+	return room, c
+}
+
+// leave는 클라이언트를 세션에서 내보내고, 남은 편집자가 없으면 세션을 없앱니다.
+func (h *collabHub) leave(room *collabRoom, c *collabClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	room.removeLocked(c)
+
+	if len(room.clients) == 0 && h.rooms[room.snippetID] == room {
+		delete(h.rooms, room.snippetID)
+	}
+}
+
+// kick은 초대가 취소된 사용자를 스니펫의 편집 세션에서 내보냅니다. send 채널이 닫히면
+// collabWriter가 연결을 닫습니다.
+func (h *collabHub) kick(snippetID, userID int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	room, ok := h.rooms[snippetID]
+	if !ok {
+		return
+	}
+
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	for _, c := range room.clients {
+		if c.userID == userID {
+			room.removeLocked(c)
+		}
+	}
+
+	if len(room.clients) == 0 {
+		delete(h.rooms, snippetID)
+	}
+}
+
+// removeLocked는 클라이언트를 제거하고 send 채널을 닫습니다. 이미 제거되었으면 아무 일도 하지 않습니다.
+func (room *collabRoom) removeLocked(c *collabClient) {
+	if room.clients[c.id] != c {
+		return
+	}
+
+	delete(room.clients, c.id)
+	close(c.send)
+
+	room.broadcastLocked(c, collabEvent{Type: "leave", Rev: room.revLocked(), Client: c.id, Name: c.name})
+}
+
+// sendLocked는 ev를 c에게 보냅니다. c의 버퍼가 가득 찼으면 다른 편집자를 기다리게 하지 않도록 c를 내보냅니다.
+func (room *collabRoom) sendLocked(c *collabClient, ev collabEvent) {
+	js, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+
+	select {
+	case c.send <- js:
+	default:
+		room.removeLocked(c)
+	}
+}
+
+// broadcastLocked는 except를 제외한 모든 클라이언트에게 ev를 보냅니다.
+func (room *collabRoom) broadcastLocked(except *collabClient, ev collabEvent) {
+	for _, c := range room.clients {
+		if c != except {
+			room.sendLocked(c, ev)
+		}
+	}
+}
+
+// revLocked는 현재 리비전을 반환합니다.
+func (room *collabRoom) revLocked() int {
+	return room.base + len(room.history)
+}
+
+// sinceLocked는 rev 리비전 이후에 적용된 연산을 반환합니다. 기록에 없는 리비전이면 오류를 반환합니다.
+func (room *collabRoom) sinceLocked(rev int) ([]*ot.Operation, error) {
+	if rev < room.base || rev > room.revLocked() {
+		return nil, errors.New("unknown revision")
+	}
+	return room.history[rev-room.base:], nil
+}
+
+// transformLocked는 rev 리비전을 기준으로 만든 연산을 현재 리비전에 맞게 변환합니다.
+func (room *collabRoom) transformLocked(rev int, op *ot.Operation) (*ot.Operation, error) {
+	since, err := room.sinceLocked(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, concurrent := range since {
+		var err error
+		op, _, err = ot.Transform(op, concurrent)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return op, nil
+}
+
+// apply는 클라이언트의 연산을 변환하여 문서에 적용하고, 보낸 클라이언트에게는 ack를,
+// 나머지에게는 변환된 연산을 보냅니다.
+func (room *collabRoom) apply(c *collabClient, rev int, op *ot.Operation) error {
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	// 어느 리비전의 문서도 collabMaxLength보다 길 수 없으므로 그보다 긴 연산은 변환하지 않고 거부합니다.
+	if op.BaseLength > collabMaxLength || op.TargetLength > collabMaxLength {
+		return errors.New("the snippet is too long")
+	}
+
+	op, err := room.transformLocked(rev, op)
+	if err != nil {
+		return err
+	}
+
+	doc, err := op.Apply(room.doc)
+	if err != nil {
+		return err
+	}
+	if utf8Len(doc) > collabMaxLength {
+		return errors.New("the snippet is too long")
+	}
+
+	room.doc = doc
+	room.history = append(room.history, op)
+
+	// 기록이 두 배로 쌓일 때마다 오래된 연산을 한꺼번에 버려 복사 횟수를 줄입니다.
+	if len(room.history) > 2*collabMaxHistory {
+		drop := len(room.history) - collabMaxHistory
+		room.history = append([]*ot.Operation(nil), room.history[drop:]...)
+		room.base += drop
+	}
+
+	for _, other := range room.clients {
+		other.pos, other.end = op.TransformIndex(other.pos), op.TransformIndex(other.end)
+	}
+
+	newRev := room.revLocked()
+	room.sendLocked(c, collabEvent{Type: "ack", Rev: newRev})
+	room.broadcastLocked(c, collabEvent{Type: "op", Rev: newRev, Client: c.id, Op: op})
+
+	return nil
+}
+
+// utf8Len은 UTF-16 코드 단위로 된 문서를 UTF-8로 인코딩했을 때의 바이트 수를 반환합니다.
+// 짝이 없는 서로게이트는 utf16.Decode와 같이 U+FFFD로 셉니다.
+func utf8Len(doc []uint16) int {
+	n := 0
+	for i := 0; i < len(doc); i++ {
+		u := doc[i]
+		switch {
+		case u < 0x80:
+			n++
+		case u < 0x800:
+			n += 2
+		case u >= 0xd800 && u < 0xdc00 && i+1 < len(doc) && doc[i+1] >= 0xdc00 && doc[i+1] < 0xe000:
+			n += 4
+			i++
+		default:
+			n += 3
+		}
+	}
+	return n
+}
+
+// moveCursor는 클라이언트의 커서를 rev 리비전 기준 위치에서 현재 리비전 기준으로 옮겨 저장하고 알립니다.
+func (room *collabRoom) moveCursor(c *collabClient, rev, pos, end int) error {
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	since, err := room.sinceLocked(rev)
+	if err != nil {
+		return err
+	}
+
+	for _, op := range since {
+		pos, end = op.TransformIndex(pos), op.TransformIndex(end)
+	}
+
+	clamp := func(i int) int {
+		if i < 0 {
+			return 0
+		}
+		if i > len(room.doc) {
+			return len(room.doc)
+		}
+		return i
+	}
+
+	c.pos, c.end = clamp(pos), clamp(end)
+	room.broadcastLocked(c, collabEvent{Type: "cursor", Rev: room.revLocked(), Client: c.id, Name: c.name, Pos: c.pos, End: c.end})
+
+	return nil
+}
+
+// snapshot은 현재 문서와 리비전을 반환합니다.
+func (room *collabRoom) snapshot() (string, int) {
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	return string(utf16.Decode(room.doc)), room.revLocked()
+}
+
+func (room *collabRoom) send(c *collabClient, ev collabEvent) {
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	room.sendLocked(c, ev)
+}
+
+func (room *collabRoom) broadcast(ev collabEvent) {
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	room.broadcastLocked(nil, ev)
+}
+
+// collabUpgrader의 기본 CheckOrigin은 Origin 헤더의 호스트가 요청의 Host와 같은지 확인하므로
+// 다른 사이트의 페이지가 사용자의 세션 쿠키로 연결하는 것(CSWSH)을 막아 줍니다.
+var collabUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+}
+
+// snippetCollab은 공동 편집 WebSocket 연결을 처리합니다. 스니펫 작성자와 작성자가 초대한
+// 사용자만 편집에 참여할 수 있고, 리비전을 저장할 수 있는 사람은 작성자뿐입니다.
+//
+// LoadAndSave는 연결을 가로챈 뒤에도 버퍼링한 응답을 쓰려고 하므로 이 경로는 dynamic
+// 미들웨어 체인 밖에 두고 세션은 sessionUserID로 직접 읽습니다.
+func (app *application) snippetCollab(w http.ResponseWriter, r *http.Request) {
+	userID, err := app.sessionUserID(r)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+	if userID == 0 {
+		app.clientError(w, r, http.StatusUnauthorized)
+		return
+	}
+
+	params := httprouter.ParamsFromContext(r.Context())
+
+	id, err := strconv.Atoi(params.ByName("id"))
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	snippet, err := app.snippets.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return
+	}
+
+	ok, err := app.canEditSnippet(snippet, userID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+	if !ok {
+		app.notFound(w, r)
+		return
+	}
+
+	user, err := app.users.Get(userID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	conn, err := collabUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade가 이미 클라이언트에게 오류 응답을 보냈습니다.
+		return
+	}
+	defer conn.Close()
+
+	room, c := app.collab.join(snippet, userID, user.Name, snippet.UserID == userID)
+	defer app.collab.leave(room, c)
+
+	go collabWriter(conn, c.send)
+
+	conn.SetReadLimit(collabMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(collabPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(collabPongWait))
+	})
+
+	for {
+		var msg collabMessage
+
+		err := conn.ReadJSON(&msg)
+		if err != nil {
+			var syntaxError *json.SyntaxError
+			var typeError *json.UnmarshalTypeError
+			if errors.As(err, &syntaxError) || errors.As(err, &typeError) || errors.Is(err, ot.ErrInvalidOperation) {
+				room.send(c, collabEvent{Type: "error", Message: "badly-formed message"})
+				continue
+			}
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(collabPongWait))
+
+		switch msg.Type {
+		case "op":
+			if msg.Op == nil {
+				room.send(c, collabEvent{Type: "error", Message: "missing op"})
+				continue
+			}
+			err = room.apply(c, msg.Rev, msg.Op)
+		case "cursor":
+			err = room.moveCursor(c, msg.Rev, msg.Pos, msg.End)
+		case "save":
+			err = app.saveRevision(room, c, snippet)
+		default:
+			err = errors.New("unknown message type")
+		}
+
+		if err != nil {
+			room.send(c, collabEvent{Type: "error", Message: err.Error()})
+		}
+	}
+}
+
+// collaboratorAddPost는 이메일 주소로 찾은 사용자를 스니펫의 공동 편집자로 초대합니다.
+// 스니펫 작성자만 초대할 수 있습니다.
+func (app *application) collaboratorAddPost(w http.ResponseWriter, r *http.Request) {
+	snippet, ok := app.ownedSnippet(w, r)
+	if !ok {
+		return
+	}
+
+	var form collaboratorAddForm
+
+	err := app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form.Email = strings.TrimSpace(form.Email)
+
+	form.CheckField(validator.NotBlank(form.Email), "email", "This field cannot be blank")
+	form.CheckField(validator.Matches(form.Email, validator.EmailRX), "email", "This field must be a valid email address")
+
+	var user *models.User
+	if form.Valid() {
+		user, err = app.users.GetByEmail(form.Email)
+		if err != nil && !errors.Is(err, models.ErrNoRecord) {
+			app.serverError(w, r, err)
+			return
+		}
+		form.CheckField(user != nil, "email", "No account uses this email address")
+		form.CheckField(user == nil || user.ID != snippet.UserID, "email", "You're already the author of this snippet")
+	}
+
+	if !form.Valid() {
+		app.renderSnippetEdit(w, r, http.StatusUnprocessableEntity, snippet, form)
+		return
+	}
+
+	err = app.collaborators.Add(snippet.ID, user.ID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", fmt.Sprintf("%s can now edit this snippet.", user.Name))
+
+	http.Redirect(w, r, fmt.Sprintf("/snippet/edit/%d", snippet.ID), http.StatusSeeOther)
+}
+
+// collaboratorRemovePost는 공동 편집자 초대를 취소하고, 편집 중이라면 편집 세션에서 내보냅니다.
+func (app *application) collaboratorRemovePost(w http.ResponseWriter, r *http.Request) {
+	snippet, ok := app.ownedSnippet(w, r)
+	if !ok {
+		return
+	}
+
+	params := httprouter.ParamsFromContext(r.Context())
+
+	userID, err := strconv.Atoi(params.ByName("user"))
+	if err != nil || userID < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	err = app.collaborators.Remove(snippet.ID, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return
+	}
+
+	app.collab.kick(snippet.ID, userID)
+
+	app.sessionManager.Put(r.Context(), "flash", "The collaborator has been removed.")
+
+	http.Redirect(w, r, fmt.Sprintf("/snippet/edit/%d", snippet.ID), http.StatusSeeOther)
+}
+
+// saveRevision은 세션의 현재 문서를 스니펫의 새 리비전으로 저장하고 모든 편집자에게 알립니다.
+func (app *application) saveRevision(room *collabRoom, c *collabClient, snippet *models.Snippet) error {
+	if !c.canSave {
+		return errors.New("only the author of the snippet can save a revision")
+	}
+
+	content, rev := room.snapshot()
+
+	if !validator.NotBlank(content) {
+		return errors.New("the snippet cannot be blank")
+	}
+	if len(content) > collabMaxLength {
+		return errors.New("the snippet is too long to save")
+	}
+
+	revisionID, err := app.revisions.Insert(room.snippetID, c.userID, content)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			return errors.New("the snippet no longer exists")
+		}
+		app.errorLog.Print(err)
+		return errors.New("the revision could not be saved")
+	}
+
+	room.broadcast(collabEvent{Type: "saved", Rev: rev, Revision: revisionID, Name: c.name})
+
+	updated := *snippet
+	updated.Content = content
+	app.snippetEvent(models.EventSnippetUpdated, &updated)
+
+	return nil
+}
+
+// collabWriter는 send 채널의 메시지를 연결에 쓰고 주기적으로 ping을 보냅니다.
+// 채널이 닫히거나 쓰기에 실패하면 연결을 닫으므로 읽기 루프도 함께 끝납니다.
+func collabWriter(conn *websocket.Conn, send <-chan []byte) {
+	ticker := time.NewTicker(collabPingInterval)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-send:
+			conn.SetWriteDeadline(time.Now().Add(collabWriteTimeout))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(collabWriteTimeout))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
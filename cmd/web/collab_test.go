@@ -0,0 +1,333 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/gorilla/websocket"
+	"snippetbox.wook.net/internal/assert"
+	"snippetbox.wook.net/internal/models/mocks"
+	"snippetbox.wook.net/internal/ot"
+)
+
+// dialCollab은 테스트 서버 클라이언트의 쿠키로 공동 편집 WebSocket에 연결합니다.
+func (ts *testServer) dialCollab(t *testing.T, urlPath, origin string) (*websocket.Conn, int) {
+	dialer := websocket.Dialer{
+		TLSClientConfig:  ts.Client().Transport.(*http.Transport).TLSClientConfig,
+		Jar:              ts.Client().Jar,
+		HandshakeTimeout: 5 * time.Second,
+	}
+
+	header := http.Header{}
+	header.Set("Origin", origin)
+
+	conn, rs, err := dialer.Dial("wss"+strings.TrimPrefix(ts.URL, "https")+urlPath, header)
+	if err != nil {
+		if rs == nil {
+			t.Fatal(err)
+		}
+		return nil, rs.StatusCode
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn, http.StatusSwitchingProtocols
+}
+
+// readEvent는 다음 서버 메시지를 읽습니다.
+func readEvent(t *testing.T, conn *websocket.Conn) collabEvent {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var ev collabEvent
+	err := conn.ReadJSON(&ev)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ev
+}
+
+func TestSnippetCollab(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	_, code := ts.dialCollab(t, "/snippet/edit/1/ws", ts.URL)
+	assert.Equal(t, code, http.StatusUnauthorized)
+
+	ts.login(t)
+
+	_, code = ts.dialCollab(t, "/snippet/edit/1/ws", "https://evil.example.com")
+	assert.Equal(t, code, http.StatusForbidden)
+
+	_, code = ts.dialCollab(t, "/snippet/edit/2/ws", ts.URL)
+	assert.Equal(t, code, http.StatusNotFound)
+
+	alice, _ := ts.dialCollab(t, "/snippet/edit/1/ws", ts.URL)
+	init := readEvent(t, alice)
+	assert.Equal(t, init.Type, "init")
+	assert.Equal(t, init.Rev, 0)
+	assert.Equal(t, *init.Content, "An old silent pond...")
+	assert.Equal(t, init.CanSave, true)
+
+	// carol은 mocks.CollaboratorModel에서 스니펫 1의 공동 편집자입니다.
+	carol := newTestServer(t, app.routes())
+	defer carol.Close()
+	carol.loginCookie(t, "carol@example.com", false)
+
+	other, _ := carol.dialCollab(t, "/snippet/edit/1/ws", carol.URL)
+	init = readEvent(t, other)
+	assert.Equal(t, len(init.Peers), 1)
+	assert.Equal(t, init.CanSave, false)
+
+	join := readEvent(t, alice)
+	assert.Equal(t, join.Type, "join")
+	assert.Equal(t, join.Name, "Carol Brown")
+
+	// 두 클라이언트가 리비전 0을 기준으로 동시에 편집합니다.
+	// alice: "An old silent pond..." -> "An old silent frog pond..."
+	// other: "An old silent pond..." -> "A silent pond..."
+	err := alice.WriteJSON(collabMessage{Type: "op", Rev: 0, Op: (&ot.Operation{}).Retain(14).Insert("frog ").Retain(7)})
+	assert.NilError(t, err)
+	ack := readEvent(t, alice)
+	assert.Equal(t, ack.Type, "ack")
+	assert.Equal(t, ack.Rev, 1)
+
+	// other는 alice의 연산을 받기 전이므로 여전히 리비전 0을 기준으로 보냅니다.
+	err = other.WriteJSON(collabMessage{Type: "op", Rev: 0, Op: (&ot.Operation{}).Retain(1).Delete(5).Retain(15)})
+	assert.NilError(t, err)
+
+	op := readEvent(t, alice)
+	assert.Equal(t, op.Type, "op")
+	assert.Equal(t, op.Rev, 2)
+
+	err = alice.WriteJSON(collabMessage{Type: "op", Rev: 0, Op: (&ot.Operation{}).Retain(99)})
+	assert.NilError(t, err)
+	assert.Equal(t, readEvent(t, alice).Type, "error")
+
+	app.collab.mu.Lock()
+	room := app.collab.rooms[1]
+	app.collab.mu.Unlock()
+
+	content, rev := room.snapshot()
+	assert.Equal(t, content, "A silent frog pond...")
+	assert.Equal(t, rev, 2)
+
+	err = alice.WriteJSON(collabMessage{Type: "save"})
+	assert.NilError(t, err)
+	saved := readEvent(t, alice)
+	assert.Equal(t, saved.Type, "saved")
+	assert.Equal(t, saved.Revision, 1)
+}
+
+func TestSnippetEdit(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, header, _ := ts.get(t, "/snippet/edit/1")
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/user/login")
+
+	ts.login(t)
+
+	code, _, body := ts.get(t, "/snippet/edit/1")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "data-ws='/snippet/edit/1/ws'")
+	assert.StringContains(t, body, "Save revision")
+	assert.StringContains(t, body, "Alice Jones")
+
+	assert.StringContains(t, body, "carol@example.com")
+
+	code, _, _ = ts.get(t, "/snippet/edit/2")
+	assert.Equal(t, code, http.StatusNotFound)
+
+	// 공동 편집자에게는 편집 페이지를 보여주지만 초대 양식은 보여주지 않습니다.
+	carol := newTestServer(t, app.routes())
+	defer carol.Close()
+	carol.loginCookie(t, "carol@example.com", false)
+
+	code, _, body = carol.get(t, "/snippet/view/1")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "Edit together")
+
+	code, _, body = carol.get(t, "/snippet/edit/1")
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, strings.Contains(body, "/snippet/edit/1/collaborators"), false)
+}
+
+// uninvitedCollaboratorModel은 아무도 초대하지 않은 스니펫을 흉내 냅니다.
+type uninvitedCollaboratorModel struct {
+	mocks.CollaboratorModel
+}
+
+func (m *uninvitedCollaboratorModel) Exists(snippetID, userID int) (bool, error) {
+	return false, nil
+}
+
+func TestSnippetCollabUninvited(t *testing.T) {
+	app := newTestApplication(t)
+	app.collaborators = &uninvitedCollaboratorModel{}
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.loginCookie(t, "carol@example.com", false)
+
+	code, _, body := ts.get(t, "/snippet/view/1")
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, strings.Contains(body, "Edit together"), false)
+
+	code, _, _ = ts.get(t, "/snippet/edit/1")
+	assert.Equal(t, code, http.StatusNotFound)
+
+	_, code = ts.dialCollab(t, "/snippet/edit/1/ws", ts.URL)
+	assert.Equal(t, code, http.StatusNotFound)
+}
+
+func TestCollaboratorAddPost(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	csrfToken := ts.login(t)
+
+	tests := []struct {
+		name     string
+		email    string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Valid email",
+			email:    "carol@example.com",
+			wantCode: http.StatusSeeOther,
+		},
+		{
+			name:     "Blank email",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "This field cannot be blank",
+		},
+		{
+			name:     "Unknown email",
+			email:    "dave@example.com",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "No account uses this email address",
+		},
+		{
+			name:     "Author",
+			email:    "alice@example.com",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "You&#39;re already the author of this snippet",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("email", tt.email)
+			form.Add("csrf_token", csrfToken)
+
+			code, header, body := ts.postForm(t, "/snippet/edit/1/collaborators", form)
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+			if code == http.StatusSeeOther {
+				assert.Equal(t, header.Get("Location"), "/snippet/edit/1")
+			}
+		})
+	}
+
+	// 작성자가 아니면 초대할 수 없습니다.
+	carol := newTestServer(t, app.routes())
+	defer carol.Close()
+	carol.loginCookie(t, "carol@example.com", false)
+
+	_, _, body := carol.get(t, "/snippet/view/1")
+	form := url.Values{}
+	form.Add("email", "alice@example.com")
+	form.Add("csrf_token", extractCSRFToken(t, body))
+
+	code, _, _ := carol.postForm(t, "/snippet/edit/1/collaborators", form)
+	assert.Equal(t, code, http.StatusNotFound)
+}
+
+func TestCollaboratorRemovePost(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	csrfToken := ts.login(t)
+
+	carol := newTestServer(t, app.routes())
+	defer carol.Close()
+	carol.loginCookie(t, "carol@example.com", false)
+
+	conn, _ := carol.dialCollab(t, "/snippet/edit/1/ws", carol.URL)
+	assert.Equal(t, readEvent(t, conn).Type, "init")
+
+	form := url.Values{}
+	form.Add("csrf_token", csrfToken)
+
+	code, _, _ := ts.postForm(t, "/snippet/edit/1/collaborators/3/remove", form)
+	assert.Equal(t, code, http.StatusNotFound)
+
+	code, header, _ := ts.postForm(t, "/snippet/edit/1/collaborators/4/remove", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/snippet/edit/1")
+
+	// 초대를 취소하면 편집 중인 연결도 끊깁니다.
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err := conn.ReadMessage()
+	assert.Equal(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), true)
+}
+
+func TestUTF8Len(t *testing.T) {
+	tests := []string{
+		"",
+		"An old silent pond...",
+		"café",
+		"古池や蛙飛び込む水の音",
+		"🐸 jumps in",
+		string(utf16.Decode([]uint16{0xd83d})) + "a",
+	}
+
+	for _, s := range tests {
+		assert.Equal(t, utf8Len(utf16.Encode([]rune(s))), len(s))
+	}
+
+	// 짝이 없는 서로게이트는 U+FFFD(3바이트)로 셉니다.
+	assert.Equal(t, utf8Len([]uint16{0xdc00, 'a'}), 4)
+}
+
+func TestCollabRoomApply(t *testing.T) {
+	if collabMaxLength > ot.MaxLength {
+		t.Fatalf("collabMaxLength %d is longer than ot.MaxLength %d", collabMaxLength, ot.MaxLength)
+	}
+
+	room := &collabRoom{clients: make(map[int]*collabClient)}
+	c := &collabClient{id: 1, send: make(chan []byte, 4*collabMaxHistory)}
+
+	n := 2*collabMaxHistory + 1
+	for i := 0; i < n; i++ {
+		err := room.apply(c, i, (&ot.Operation{}).Retain(i).Insert("a"))
+		assert.NilError(t, err)
+	}
+
+	// 기록이 넘치면 오래된 연산을 버리므로 그 리비전을 기준으로 한 연산은 적용할 수 없습니다.
+	content, rev := room.snapshot()
+	assert.Equal(t, content, strings.Repeat("a", n))
+	assert.Equal(t, rev, n)
+	assert.Equal(t, len(room.history), collabMaxHistory)
+
+	err := room.apply(c, 0, (&ot.Operation{}).Insert("b"))
+	assert.Equal(t, err.Error(), "unknown revision")
+
+	err = room.apply(c, n-collabMaxHistory, (&ot.Operation{}).Retain(n-collabMaxHistory).Insert("b"))
+	assert.NilError(t, err)
+
+	// 어느 리비전의 문서보다도 긴 연산은 변환하기 전에 거부합니다.
+	err = room.apply(c, rev, (&ot.Operation{}).Retain(collabMaxLength+1))
+	assert.Equal(t, err.Error(), "the snippet is too long")
+}
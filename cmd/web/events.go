@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"snippetbox.wook.net/internal/models"
+)
+
+const (
+	// 구독자마다 쌓아 둘 수 있는 최대 메시지 수입니다. 이보다 느린 구독자는 연결이 끊깁니다.
+	eventBufferSize = 16
+	// 프록시가 유휴 연결을 닫지 않도록 이 주기마다 주석 줄을 보냅니다.
+	eventHeartbeatInterval = 15 * time.Second
+	// 쓰기 한 번에 허용하는 최대 시간입니다. 서버의 WriteTimeout 대신 사용합니다.
+	eventWriteTimeout = 10 * time.Second
+)
+
+// eventHub는 프로세스 안에서 동작하는 간단한 발행/구독 허브입니다. 각 구독자는
+// 버퍼가 있는 채널을 받으며, Publish는 절대 막히지 않습니다. 버퍼가 가득 찬 구독자는
+// 다른 구독자를 기다리게 하는 대신 채널을 닫아 내보냅니다.
+type eventHub struct {
+	mu      sync.Mutex
+	clients map[chan []byte]struct{}
+	closed  bool
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{
+		clients: make(map[chan []byte]struct{}),
+	}
+}
+
+// Subscribe는 새 구독 채널과 구독을 해지하는 함수를 반환합니다. 채널이 닫히면
+// 구독자가 너무 느려서 내보내졌거나 허브가 닫힌 것입니다.
+func (h *eventHub) Subscribe() (<-chan []byte, func()) {
+	ch := make(chan []byte, eventBufferSize)
+
+	h.mu.Lock()
+	if h.closed {
+		close(ch)
+	} else {
+		h.clients[ch] = struct{}{}
+	}
+	h.mu.Unlock()
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		if _, ok := h.clients[ch]; ok {
+			delete(h.clients, ch)
+			close(ch)
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+// Publish는 msg를 모든 구독자에게 보냅니다.
+func (h *eventHub) Publish(msg []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.clients {
+		select {
+		case ch <- msg:
+		default:
+			delete(h.clients, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close는 모든 구독 채널을 닫습니다. 서버가 종료될 때 스트리밍 중인 요청이
+// Shutdown()을 붙잡고 있지 않도록 srv.RegisterOnShutdown()에 등록합니다.
+func (h *eventHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for ch := range h.clients {
+		delete(h.clients, ch)
+		close(ch)
+	}
+}
+
+// publishSnippet은 새 스니펫을 /events 구독자에게 "snippet" 이벤트로 보냅니다.
+func (app *application) publishSnippet(snippet *models.Snippet) {
+	js, err := json.Marshal(snippet)
+	if err != nil {
+		app.errorLog.Print(err)
+		return
+	}
+
+	app.hub.Publish([]byte(fmt.Sprintf("id: %d\nevent: snippet\ndata: %s\n\n", snippet.ID, js)))
+}
+
+// eventStream은 새로 만들어진 스니펫을 Server-Sent Events로 스트리밍합니다.
+//
+// 서버의 WriteTimeout은 응답 전체에 적용되므로 그대로 두면 10초 뒤에 연결이 끊깁니다.
+// 그래서 이 요청에서만 읽기/쓰기 제한 시간을 해제하고, 대신 쓰기마다 eventWriteTimeout을
+// 적용해 응답하지 않는 클라이언트를 정리합니다. 응답을 버퍼링하는 LoadAndSave를
+// 거치면 스트리밍할 수 없으므로 dynamic 미들웨어 체인 밖에 등록해야 합니다.
+func (app *application) eventStream(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+
+	err := rc.SetReadDeadline(time.Time{})
+	if err == nil {
+		err = rc.SetWriteDeadline(time.Time{})
+	}
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	events, unsubscribe := app.hub.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	write := func(msg []byte) bool {
+		rc.SetWriteDeadline(time.Now().Add(eventWriteTimeout))
+
+		_, err := w.Write(msg)
+		if err == nil {
+			err = rc.Flush()
+		}
+		return err == nil
+	}
+
+	// 연결이 끊기면 브라우저의 EventSource가 5초 뒤에 다시 연결합니다.
+	if !write([]byte("retry: 5000\n\n")) {
+		return
+	}
+
+	ticker := time.NewTicker(eventHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-events:
+			if !ok || !write(msg) {
+				return
+			}
+		case <-ticker.C:
+			if !write([]byte(": heartbeat\n\n")) {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"snippetbox.wook.net/internal/assert"
+	"snippetbox.wook.net/internal/models"
+)
+
+func TestEventHub(t *testing.T) {
+	hub := newEventHub()
+
+	fast, unsubscribeFast := hub.Subscribe()
+	defer unsubscribeFast()
+	slow, unsubscribeSlow := hub.Subscribe()
+	defer unsubscribeSlow()
+
+	// fast는 매번 메시지를 읽고, slow는 읽지 않습니다. 버퍼가 가득 찬 뒤의
+	// 발행에서 slow만 내보내져야 합니다.
+	for i := 0; i <= eventBufferSize; i++ {
+		hub.Publish([]byte("msg"))
+		assert.Equal(t, string(<-fast), "msg")
+	}
+
+	for i := 0; i < eventBufferSize; i++ {
+		<-slow
+	}
+	_, ok := <-slow
+	assert.Equal(t, ok, false)
+
+	hub.Close()
+	_, ok = <-fast
+	assert.Equal(t, ok, false)
+
+	// 닫힌 허브를 구독하면 바로 닫힌 채널을 받습니다.
+	closed, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+	_, ok = <-closed
+	assert.Equal(t, ok, false)
+}
+
+func TestEventStream(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Body.Close()
+
+	assert.Equal(t, rs.StatusCode, http.StatusOK)
+	assert.Equal(t, rs.Header.Get("Content-Type"), "text/event-stream")
+
+	lines := bufio.NewScanner(rs.Body)
+
+	// retry 필드가 도착했다면 핸들러가 이미 구독을 마친 상태입니다.
+	lines.Scan()
+	assert.Equal(t, lines.Text(), "retry: 5000")
+
+	app.publishSnippet(&models.Snippet{ID: 7, Title: "Live"})
+
+	var frame []string
+	for lines.Scan() {
+		if lines.Text() == "" && len(frame) > 0 {
+			break
+		}
+		if lines.Text() != "" {
+			frame = append(frame, lines.Text())
+		}
+	}
+
+	assert.Equal(t, len(frame), 3)
+	assert.Equal(t, frame[0], "id: 7")
+	assert.Equal(t, frame[1], "event: snippet")
+	assert.StringContains(t, frame[2], `data: {"id":7,`)
+	assert.Equal(t, strings.Contains(frame[2], "\n"), false)
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"snippetbox.wook.net/internal/models"
+	"snippetbox.wook.net/internal/validator"
+)
+
+// feedGUID는 호스트 이름이나 URL 구조가 바뀌어도 변하지 않는 스니펫의 고유 식별자(RFC 4151 tag URI)를 반환합니다.
+func feedGUID(id int) string {
+	return fmt.Sprintf("tag:snippetbox.wook.net,2023:snippet/%d", id)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Link       atomLink       `xml:"link"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+}
+
+// writeFeed는 스니펫 목록을 Atom 또는 RSS 형식으로 씁니다. 피드 내용으로 만든 ETag와 가장
+// 최근 스니펫의 생성 시각을 Last-Modified로 보내고, 조건부 요청이 둘 중 하나와 맞으면 304를
+// 응답합니다.
+//
+// 스니펫에는 수정 시각이 없고 삭제나 만료는 목록에서 빠지는 것으로만 드러나므로 Last-Modified만
+// 으로는 이런 변경을 알 수 없습니다. 그래서 RFC 9110과 같이 If-None-Match가 있으면
+// If-Modified-Since보다 먼저 확인하고, 날짜만 보내는 클라이언트에는 Last-Modified로 답합니다.
+func (app *application) writeFeed(w http.ResponseWriter, r *http.Request, format, title, selfPath string, snippets []*models.Snippet) {
+	etag := feedETag(format, title, app.absoluteURL(r, selfPath), snippets)
+	w.Header().Set("ETag", etag)
+
+	var lastModified time.Time
+	for _, s := range snippets {
+		if s.Created.After(lastModified) {
+			lastModified = s.Created
+		}
+	}
+	lastModified = lastModified.UTC().Truncate(time.Second)
+
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+
+	if feedNotModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// 피드의 갱신 시각은 가장 최근 스니펫의 생성 시각이며, 스니펫이 없으면 현재 시각입니다.
+	updated := lastModified
+	if updated.IsZero() {
+		updated = time.Now().UTC().Truncate(time.Second)
+	}
+
+	var feed any
+
+	switch format {
+	case "atom":
+		f := atomFeed{
+			Title:   title,
+			ID:      app.absoluteURL(r, selfPath),
+			Updated: updated.Format(time.RFC3339),
+			Links: []atomLink{
+				{Href: app.absoluteURL(r, selfPath), Rel: "self", Type: "application/atom+xml"},
+				{Href: app.absoluteURL(r, "/"), Rel: "alternate", Type: "text/html"},
+			},
+			Entries: []atomEntry{},
+		}
+
+		for _, s := range snippets {
+			entry := atomEntry{
+				Title:     s.Title,
+				ID:        feedGUID(s.ID),
+				Published: s.Created.UTC().Format(time.RFC3339),
+				Updated:   s.Created.UTC().Format(time.RFC3339),
+				Link:      atomLink{Href: app.absoluteURL(r, fmt.Sprintf("/snippet/view/%d", s.ID)), Rel: "alternate"},
+				Content:   atomContent{Type: "text", Body: s.Content},
+			}
+			if s.Author != "" {
+				entry.Author = &atomPerson{Name: s.Author}
+			}
+			for _, tag := range s.Tags {
+				entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+			}
+			f.Entries = append(f.Entries, entry)
+		}
+
+		feed = f
+		w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	default:
+		f := rssFeed{
+			Version: "2.0",
+			Channel: rssChannel{
+				Title:         title,
+				Link:          app.absoluteURL(r, "/"),
+				Description:   title,
+				LastBuildDate: updated.Format(time.RFC1123Z),
+				Items:         []rssItem{},
+			},
+		}
+
+		for _, s := range snippets {
+			f.Channel.Items = append(f.Channel.Items, rssItem{
+				Title:       s.Title,
+				Link:        app.absoluteURL(r, fmt.Sprintf("/snippet/view/%d", s.ID)),
+				Description: s.Content,
+				GUID:        rssGUID{IsPermaLink: false, Value: feedGUID(s.ID)},
+				PubDate:     s.Created.UTC().Format(time.RFC1123Z),
+				Categories:  s.Tags,
+			})
+		}
+
+		feed = f
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	}
+
+	out, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	w.Write([]byte(xml.Header))
+	w.Write(out)
+}
+
+// feedNotModified는 조건부 요청 헤더가 현재 피드와 맞는지 확인합니다. If-None-Match가 있으면
+// ETag만 비교하고, 없을 때만 If-Modified-Since를 lastModified와 비교합니다.
+func feedNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.After(since)
+}
+
+// feedETag는 피드에 들어가는 내용으로 강한 ETag를 만듭니다. 스니펫이 추가, 수정, 삭제되거나
+// 만료되면 값이 바뀝니다.
+func feedETag(format, title, selfURL string, snippets []*models.Snippet) string {
+	h := sha256.New()
+
+	fmt.Fprintf(h, "%q %q %q\n", format, title, selfURL)
+	for _, s := range snippets {
+		fmt.Fprintf(h, "%d %q %q %q %q %d\n", s.ID, s.Author, s.Title, s.Content, s.Tags, s.Created.Unix())
+	}
+
+	return fmt.Sprintf(`"%x"`, h.Sum(nil)[:16])
+}
+
+// etagMatches는 If-None-Match 헤더의 값 중 하나가 etag와 같은지 확인합니다. If-None-Match는
+// 약한 비교를 하므로 W/ 접두사는 무시합니다.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// splitFeedName은 "k8s.atom"과 같은 경로 조각을 이름과 피드 형식으로 나눕니다.
+// 지원하지 않는 확장자이면 ok는 false입니다.
+func splitFeedName(s string) (name, format string, ok bool) {
+	ext := path.Ext(s)
+	format = strings.TrimPrefix(ext, ".")
+
+	if !validator.PermittedValue(format, "atom", "rss") {
+		return "", "", false
+	}
+
+	return strings.TrimSuffix(s, ext), format, true
+}
+
+func (app *application) feedLatest(w http.ResponseWriter, r *http.Request) {
+	_, format, _ := splitFeedName(r.URL.Path)
+
+	snippets, err := app.snippets.Latest()
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.writeFeed(w, r, format, "Snippetbox: latest snippets", r.URL.Path, snippets)
+}
+
+func (app *application) feedUser(w http.ResponseWriter, r *http.Request) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	name, format, ok := splitFeedName(params.ByName("file"))
+	if !ok {
+		app.notFound(w, r)
+		return
+	}
+
+	id, err := strconv.Atoi(name)
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	exists, err := app.users.Exists(id)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+	if !exists {
+		app.notFound(w, r)
+		return
+	}
+
+	snippets, err := app.snippets.LatestByUser(id)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	title := fmt.Sprintf("Snippetbox: snippets by user #%d", id)
+	if len(snippets) > 0 {
+		title = fmt.Sprintf("Snippetbox: snippets by %s", snippets[0].Author)
+	}
+
+	app.writeFeed(w, r, format, title, r.URL.Path, snippets)
+}
+
+func (app *application) feedTag(w http.ResponseWriter, r *http.Request) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	tag, format, ok := splitFeedName(params.ByName("file"))
+	if !ok || !validator.Matches(tag, validator.TagRX) {
+		app.notFound(w, r)
+		return
+	}
+
+	snippets, err := app.snippets.LatestByTag(tag)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.writeFeed(w, r, format, fmt.Sprintf("Snippetbox: snippets tagged %s", tag), r.URL.Path, snippets)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"snippetbox.wook.net/internal/assert"
+	"snippetbox.wook.net/internal/models"
+)
+
+func TestFeeds(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	tests := []struct {
+		name            string
+		urlPath         string
+		wantCode        int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "Latest Atom",
+			urlPath:         "/feed.atom",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/atom+xml; charset=utf-8",
+			wantBody:        "<id>tag:snippetbox.wook.net,2023:snippet/1</id>",
+		},
+		{
+			name:            "Latest RSS",
+			urlPath:         "/feed.rss",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/rss+xml; charset=utf-8",
+			wantBody:        `<guid isPermaLink="false">tag:snippetbox.wook.net,2023:snippet/1</guid>`,
+		},
+		{
+			name:            "User feed",
+			urlPath:         "/feed/user/1.atom",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/atom+xml; charset=utf-8",
+			wantBody:        "<title>Snippetbox: snippets by Alice Jones</title>",
+		},
+		{
+			name:     "Non-existent user",
+			urlPath:  "/feed/user/2.atom",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:            "Tag feed",
+			urlPath:         "/feed/tag/haiku.rss",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/rss+xml; charset=utf-8",
+			wantBody:        "<category>haiku</category>",
+		},
+		{
+			name:     "Unknown format",
+			urlPath:  "/feed/tag/haiku.json",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, header, body := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantContentType != "" {
+				assert.Equal(t, header.Get("Content-Type"), tt.wantContentType)
+			}
+			if tt.wantBody != "" {
+				assert.StringContains(t, body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestFeedConditionalGet(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	_, header, _ := ts.get(t, "/feed.atom")
+	etag := header.Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag header")
+	}
+
+	lastModified, err := http.ParseTime(header.Get("Last-Modified"))
+	assert.NilError(t, err)
+
+	modified := lastModified.Format(http.TimeFormat)
+	before := lastModified.Add(-time.Minute).Format(http.TimeFormat)
+
+	tests := []struct {
+		name     string
+		urlPath  string
+		header   http.Header
+		wantCode int
+	}{
+		{"Matching ETag", "/feed.atom", http.Header{"If-None-Match": {etag}}, http.StatusNotModified},
+		{"ETag in a list", "/feed.atom", http.Header{"If-None-Match": {`"other", W/` + etag}}, http.StatusNotModified},
+		{"Other format", "/feed.rss", http.Header{"If-None-Match": {etag}}, http.StatusOK},
+		{"Stale ETag", "/feed.atom", http.Header{"If-None-Match": {`"stale"`}}, http.StatusOK},
+		{"Not modified since", "/feed.atom", http.Header{"If-Modified-Since": {modified}}, http.StatusNotModified},
+		{"Modified since", "/feed.atom", http.Header{"If-Modified-Since": {before}}, http.StatusOK},
+		{"Invalid date", "/feed.atom", http.Header{"If-Modified-Since": {"yesterday"}}, http.StatusOK},
+		// If-None-Match가 있으면 If-Modified-Since는 무시합니다.
+		{"Stale ETag with date", "/feed.atom", http.Header{"If-None-Match": {`"stale"`}, "If-Modified-Since": {modified}}, http.StatusOK},
+		{"Matching ETag with old date", "/feed.atom", http.Header{"If-None-Match": {etag}, "If-Modified-Since": {before}}, http.StatusNotModified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, _ := ts.request(t, http.MethodGet, tt.urlPath, tt.header, "")
+			assert.Equal(t, code, tt.wantCode)
+		})
+	}
+}
+
+func TestFeedETagChanges(t *testing.T) {
+	snippet := &models.Snippet{
+		ID:      1,
+		Author:  "Alice Jones",
+		Title:   "An old silent pond",
+		Content: "An old silent pond...",
+		Tags:    []string{"haiku"},
+		Created: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC),
+	}
+	edited := *snippet
+	edited.Content = "An old silent pond, a frog jumps in"
+
+	etag := feedETag("atom", "Latest", "https://example.com/feed.atom", []*models.Snippet{snippet})
+
+	assert.Equal(t, feedETag("atom", "Latest", "https://example.com/feed.atom", []*models.Snippet{snippet}), etag)
+	assert.Equal(t, feedETag("atom", "Latest", "https://example.com/feed.atom", []*models.Snippet{&edited}) == etag, false)
+	assert.Equal(t, feedETag("atom", "Latest", "https://example.com/feed.atom", []*models.Snippet{}) == etag, false)
+}
+
+func TestFeedEmpty(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, header, body := ts.get(t, "/feed/tag/unknown.atom")
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, header.Get("Last-Modified"), "")
+	// 스니펫이 없어도 0001-01-01이 아닌 현재 시각을 갱신 시각으로 씁니다.
+	assert.StringContains(t, body, "<updated>"+strconv.Itoa(time.Now().UTC().Year()))
+}
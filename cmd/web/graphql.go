@@ -0,0 +1,604 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"snippetbox.wook.net/internal/models"
+)
+
+const (
+	// 쿼리에서 필드를 중첩할 수 있는 최대 깊이입니다.
+	graphqlMaxDepth = 10
+	// 쿼리 하나의 최대 복잡도입니다. 필드마다 1이며, 연결(connection) 필드의 하위
+	// 필드는 요청한 항목 수(first)만큼 곱해집니다.
+	graphqlMaxComplexity = 1000
+	// 연결 필드의 first 인수 기본값과 최댓값입니다.
+	graphqlDefaultFirst = 10
+	graphqlMaxFirst     = 100
+)
+
+const graphqlLoadersContextKey = contextKey("graphqlLoaders")
+
+// errGraphQLInternal은 데이터베이스 오류 등 내부 오류 대신 클라이언트에게 보여주는 오류입니다.
+var errGraphQLInternal = errors.New("internal server error")
+
+// graphqlRequest는 GraphQL over HTTP 요청입니다. GET 요청에서는 같은 이름의 쿼리 문자열
+// 매개변수를 사용하며, variables는 JSON으로 인코딩합니다.
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+	Extensions    map[string]any `json:"extensions"`
+}
+
+// graphqlConnection은 Relay 방식의 커서 기반 스니펫 목록입니다.
+type graphqlConnection struct {
+	Edges      []graphqlEdge
+	PageInfo   graphqlPageInfo
+	TotalCount int
+}
+
+type graphqlEdge struct {
+	Cursor string
+	Node   *models.Snippet
+}
+
+type graphqlPageInfo struct {
+	HasNextPage bool
+	EndCursor   *string
+}
+
+// encodeCursor와 decodeCursor는 스니펫 ID를 불투명한 커서 문자열로 바꿉니다.
+func encodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("snippet:" + strconv.Itoa(id)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+
+	value, ok := strings.CutPrefix(string(b), "snippet:")
+	id, err := strconv.Atoi(value)
+	if !ok || err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+
+	return id, nil
+}
+
+// pageArgs는 연결 필드의 first와 after 인수를 읽습니다. 반환하는 limit은 다음 페이지가
+// 있는지 알 수 있도록 first보다 하나 많습니다.
+func pageArgs(p graphql.ResolveParams) (after, limit int, err error) {
+	first := graphqlDefaultFirst
+	if v, ok := p.Args["first"].(int); ok {
+		first = v
+	}
+	if first < 1 || first > graphqlMaxFirst {
+		return 0, 0, fmt.Errorf("first must be between 1 and %d", graphqlMaxFirst)
+	}
+
+	if v, ok := p.Args["after"].(string); ok {
+		after, err = decodeCursor(v)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return after, first + 1, nil
+}
+
+// newConnection은 limit개까지 요청해서 받은 페이지로 연결을 만듭니다. 마지막 한 개는
+// 다음 페이지가 있는지 확인하는 데만 사용합니다.
+func newConnection(page *models.SnippetPage, limit int) *graphqlConnection {
+	conn := &graphqlConnection{
+		Edges:      []graphqlEdge{},
+		TotalCount: page.Total,
+	}
+
+	snippets := page.Snippets
+	if len(snippets) >= limit {
+		snippets = snippets[:limit-1]
+		conn.PageInfo.HasNextPage = true
+	}
+
+	for _, s := range snippets {
+		conn.Edges = append(conn.Edges, graphqlEdge{Cursor: encodeCursor(s.ID), Node: s})
+	}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.EndCursor = &conn.Edges[len(conn.Edges)-1].Cursor
+	}
+
+	return conn
+}
+
+// graphqlInternalError는 오류와 스택 추적을 기록하고 클라이언트에게 보여줄 오류를 반환합니다.
+func (app *application) graphqlInternalError(err error) error {
+	trace := fmt.Sprintf("%s\n%s", err.Error(), debug.Stack())
+	app.errorLog.Output(2, trace)
+
+	return errGraphQLInternal
+}
+
+func loadersFromContext(ctx context.Context) *graphqlLoaders {
+	return ctx.Value(graphqlLoadersContextKey).(*graphqlLoaders)
+}
+
+// idArg는 ID 인수를 정수로 바꿉니다. 숫자가 아닌 ID는 없는 레코드처럼 0을 반환합니다.
+func idArg(p graphql.ResolveParams) int {
+	id, err := strconv.Atoi(fmt.Sprint(p.Args["id"]))
+	if err != nil || id < 1 {
+		return 0
+	}
+	return id
+}
+
+// loadUser와 loadSnippet은 loader의 썽크를 graphql-go가 기대하는 형태로 감쌉니다.
+// 찾지 못한 레코드는 null이 되도록 타입이 있는 nil 포인터 대신 nil을 반환합니다.
+func (app *application) loadUser(p graphql.ResolveParams, id int) (any, error) {
+	if id == 0 {
+		return nil, nil
+	}
+
+	thunk := loadersFromContext(p.Context).users.Load(id)
+
+	return func() (any, error) {
+		u, err := thunk()
+		if err != nil {
+			return nil, app.graphqlInternalError(err)
+		}
+		if u == nil {
+			return nil, nil
+		}
+		return u, nil
+	}, nil
+}
+
+func (app *application) loadSnippet(p graphql.ResolveParams, id int) (any, error) {
+	if id == 0 {
+		return nil, nil
+	}
+
+	thunk := loadersFromContext(p.Context).snippets.Load(id)
+
+	return func() (any, error) {
+		s, err := thunk()
+		if err != nil {
+			return nil, app.graphqlInternalError(err)
+		}
+		if s == nil {
+			return nil, nil
+		}
+		return s, nil
+	}, nil
+}
+
+// newGraphQLSchema는 /graphql에서 제공하는 스키마를 만듭니다. 모든 필드는 기존 모델
+// 인터페이스로 해석하며, 목록 안에서 반복되는 조회는 요청별 loader로 묶습니다.
+func (app *application) newGraphQLSchema() (graphql.Schema, error) {
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"endCursor":   &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	// Snippet, User, Tag는 서로를 참조하므로 필드를 나중에 추가합니다.
+	snippetType := graphql.NewObject(graphql.ObjectConfig{
+		Name:   "Snippet",
+		Fields: graphql.Fields{},
+	})
+
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name:   "User",
+		Fields: graphql.Fields{},
+	})
+
+	tagType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Tag",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(string), nil
+				},
+			},
+			"snippetCount": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "Number of unexpired snippets with this tag.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					thunk := loadersFromContext(p.Context).tagCounts.Load(p.Source.(string))
+					return func() (any, error) {
+						n, err := thunk()
+						if err != nil {
+							return nil, app.graphqlInternalError(err)
+						}
+						return n, nil
+					}, nil
+				},
+			},
+		},
+	})
+
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SnippetEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"node":   &graphql.Field{Type: graphql.NewNonNull(snippetType)},
+		},
+	})
+
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SnippetConnection",
+		Fields: graphql.Fields{
+			"edges":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType)))},
+			"pageInfo":   &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+			"totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	connectionArgs := graphql.FieldConfigArgument{
+		"first": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: graphqlDefaultFirst},
+		"after": &graphql.ArgumentConfig{Type: graphql.String},
+	}
+
+	snippetType.AddFieldConfig("id", &graphql.Field{Type: graphql.NewNonNull(graphql.ID)})
+	snippetType.AddFieldConfig("title", &graphql.Field{Type: graphql.NewNonNull(graphql.String)})
+	snippetType.AddFieldConfig("content", &graphql.Field{Type: graphql.NewNonNull(graphql.String)})
+	snippetType.AddFieldConfig("created", &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)})
+	snippetType.AddFieldConfig("expires", &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)})
+	snippetType.AddFieldConfig("views", &graphql.Field{Type: graphql.NewNonNull(graphql.Int)})
+	snippetType.AddFieldConfig("tags", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(tagType))),
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(*models.Snippet).Tags, nil
+		},
+	})
+	snippetType.AddFieldConfig("author", &graphql.Field{
+		Type:        userType,
+		Description: "The user who created the snippet, or null for anonymous pastes.",
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return app.loadUser(p, p.Source.(*models.Snippet).UserID)
+		},
+	})
+
+	userType.AddFieldConfig("id", &graphql.Field{Type: graphql.NewNonNull(graphql.ID)})
+	userType.AddFieldConfig("name", &graphql.Field{Type: graphql.NewNonNull(graphql.String)})
+	userType.AddFieldConfig("created", &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)})
+	userType.AddFieldConfig("email", &graphql.Field{
+		Type:        graphql.String,
+		Description: "Only visible to the user themselves.",
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			u := p.Source.(*models.User)
+			if viewer, _ := p.Context.Value(authenticatedUserIDContextKey).(int); viewer != u.ID {
+				return nil, nil
+			}
+			return u.Email, nil
+		},
+	})
+	userType.AddFieldConfig("snippets", &graphql.Field{
+		Type: graphql.NewNonNull(connectionType),
+		Args: connectionArgs,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			after, limit, err := pageArgs(p)
+			if err != nil {
+				return nil, err
+			}
+
+			thunk := loadersFromContext(p.Context).userPages.Load(userPageKey{p.Source.(*models.User).ID, after, limit})
+
+			return func() (any, error) {
+				page, err := thunk()
+				if err != nil {
+					return nil, app.graphqlInternalError(err)
+				}
+				return newConnection(page, limit), nil
+			}, nil
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"viewer": &graphql.Field{
+				Type:        userType,
+				Description: "The authenticated user, or null.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id, _ := p.Context.Value(authenticatedUserIDContextKey).(int)
+					return app.loadUser(p, id)
+				},
+			},
+			"snippet": &graphql.Field{
+				Type: snippetType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return app.loadSnippet(p, idArg(p))
+				},
+			},
+			"snippets": &graphql.Field{
+				Type:        graphql.NewNonNull(connectionType),
+				Description: "Unexpired snippets, newest first.",
+				Args: graphql.FieldConfigArgument{
+					"first": connectionArgs["first"],
+					"after": connectionArgs["after"],
+					"query": &graphql.ArgumentConfig{Type: graphql.String, Description: "Only snippets whose title or content contains this text."},
+					"tag":   &graphql.ArgumentConfig{Type: graphql.String, Description: "Only snippets with this tag."},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					after, limit, err := pageArgs(p)
+					if err != nil {
+						return nil, err
+					}
+
+					filter := models.SnippetFilter{}
+					filter.Query, _ = p.Args["query"].(string)
+					filter.Tag, _ = p.Args["tag"].(string)
+					filter.Query = strings.TrimSpace(filter.Query)
+
+					page, err := app.snippets.Page(filter, after, limit)
+					if err != nil {
+						return nil, app.graphqlInternalError(err)
+					}
+					return newConnection(page, limit), nil
+				},
+			},
+			"user": &graphql.Field{
+				Type: userType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return app.loadUser(p, idArg(p))
+				},
+			},
+			"tag": &graphql.Field{
+				Type:        tagType,
+				Description: "The tag with this name, or null if no unexpired snippet has it.",
+				Args: graphql.FieldConfigArgument{
+					"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					name := p.Args["name"].(string)
+					thunk := loadersFromContext(p.Context).tagCounts.Load(name)
+
+					return func() (any, error) {
+						n, err := thunk()
+						if err != nil {
+							return nil, app.graphqlInternalError(err)
+						}
+						if n == 0 {
+							return nil, nil
+						}
+						return name, nil
+					}, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// queryMeasure는 실행 전에 쿼리의 깊이와 복잡도를 계산합니다.
+type queryMeasure struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+	visiting  map[string]bool
+}
+
+// measure는 선택 집합의 복잡도와, depth에서 시작했을 때 도달하는 최대 깊이를 반환합니다.
+// 스키마 조회(__schema, __type)는 스키마 크기로 제한되므로 계산에서 뺍니다.
+func (q *queryMeasure) measure(set *ast.SelectionSet, depth int) (cost, maxDepth int) {
+	maxDepth = depth
+	if set == nil {
+		return 0, maxDepth
+	}
+
+	add := func(c, d int) {
+		cost += c
+		if d > maxDepth {
+			maxDepth = d
+		}
+	}
+
+	for _, sel := range set.Selections {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(sel.Name.Value, "__") {
+				continue
+			}
+			c, d := q.measure(sel.SelectionSet, depth+1)
+			add(1+q.multiplier(sel)*c, d)
+		case *ast.InlineFragment:
+			add(q.measure(sel.SelectionSet, depth))
+		case *ast.FragmentSpread:
+			name := sel.Name.Value
+			frag, ok := q.fragments[name]
+			if !ok || q.visiting[name] {
+				continue
+			}
+			q.visiting[name] = true
+			add(q.measure(frag.SelectionSet, depth))
+			delete(q.visiting, name)
+		}
+	}
+
+	return cost, maxDepth
+}
+
+// multiplier는 연결 필드(snippets)의 하위 필드가 반복되는 횟수, 즉 first 인수의 값을 반환합니다.
+// 변수로 전달된 값을 알 수 없으면 제한을 우회하지 못하도록 가장 큰 값인 graphqlMaxFirst로 계산합니다.
+func (q *queryMeasure) multiplier(field *ast.Field) int {
+	if field.Name.Value != "snippets" {
+		return 1
+	}
+
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil && n > 0 {
+				return n
+			}
+		case *ast.Variable:
+			switch n := q.variables[v.Name.Value].(type) {
+			case float64:
+				return int(n)
+			case int:
+				return n
+			}
+			return graphqlMaxFirst
+		}
+	}
+
+	return graphqlDefaultFirst
+}
+
+// checkGraphQLLimits는 실행할 연산이 깊이와 복잡도 제한을 넘으면 오류를 반환합니다.
+func checkGraphQLLimits(doc *ast.Document, operationName string, variables map[string]any) error {
+	q := &queryMeasure{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		visiting:  make(map[string]bool),
+	}
+
+	var operations []*ast.OperationDefinition
+
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			q.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operations = append(operations, def)
+			}
+		}
+	}
+
+	for _, op := range operations {
+		q.variables = operationVariables(op, variables)
+		cost, depth := q.measure(op.SelectionSet, 0)
+		if depth > graphqlMaxDepth {
+			return fmt.Errorf("query depth %d exceeds the maximum of %d", depth, graphqlMaxDepth)
+		}
+		if cost > graphqlMaxComplexity {
+			return fmt.Errorf("query complexity %d exceeds the maximum of %d", cost, graphqlMaxComplexity)
+		}
+	}
+
+	return nil
+}
+
+// operationVariables는 요청의 변수에 연산이 선언한 기본값을 더한 값을 반환합니다. 실행할 때와
+// 같이 요청에 없는 변수는 기본값을 사용합니다.
+func operationVariables(op *ast.OperationDefinition, variables map[string]any) map[string]any {
+	vars := make(map[string]any, len(variables))
+	for name, value := range variables {
+		vars[name] = value
+	}
+
+	for _, def := range op.VariableDefinitions {
+		name := def.Variable.Name.Value
+		if _, ok := vars[name]; ok {
+			continue
+		}
+		if v, ok := def.DefaultValue.(*ast.IntValue); ok {
+			if n, err := strconv.Atoi(v.Value); err == nil {
+				vars[name] = n
+			}
+		}
+	}
+
+	return vars
+}
+
+// executeGraphQL은 요청을 파싱하고 검증한 뒤, 깊이와 복잡도 제한을 확인하고 실행합니다.
+// 실행하기 전에 요청을 거부했으면 두 번째 반환값이 false입니다.
+func (app *application) executeGraphQL(ctx context.Context, req graphqlRequest) (*graphql.Result, bool) {
+	if strings.TrimSpace(req.Query) == "" {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(errors.New("a query is required"))}, false
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, false
+	}
+
+	validation := graphql.ValidateDocument(&app.graphql, doc, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}, false
+	}
+
+	err = checkGraphQLLimits(doc, req.OperationName, req.Variables)
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, false
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        app.graphql,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       context.WithValue(ctx, graphqlLoadersContextKey, app.newGraphQLLoaders()),
+	})
+
+	return result, true
+}
+
+// graphqlHandler는 GET과 POST로 GraphQL 쿼리를 받습니다. 인증은 JSON API와 같은
+// 미들웨어 체인(세션 쿠키 또는 Bearer 토큰)을 사용하며, 인증된 사용자는 viewer 필드로
+// 조회할 수 있습니다.
+func (app *application) graphqlHandler(w http.ResponseWriter, r *http.Request) {
+	var req graphqlRequest
+
+	if r.Method == http.MethodPost {
+		err := app.readJSON(w, r, &req)
+		if err != nil {
+			app.writeJSON(w, http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+			return
+		}
+	} else {
+		qs := r.URL.Query()
+		req.Query = qs.Get("query")
+		req.OperationName = qs.Get("operationName")
+
+		if v := qs.Get("variables"); v != "" {
+			err := json.Unmarshal([]byte(v), &req.Variables)
+			if err != nil {
+				app.writeJSON(w, http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(errors.New("variables must be a JSON object"))})
+				return
+			}
+		}
+	}
+
+	// 실행을 시작한 요청은 필드 오류가 있어도 200으로 응답하고, 파싱이나 검증, 제한
+	// 검사에서 거부한 요청만 400으로 응답합니다.
+	result, ok := app.executeGraphQL(r.Context(), req)
+	if !ok {
+		app.writeJSON(w, http.StatusBadRequest, result)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, result)
+}
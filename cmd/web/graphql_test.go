@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"snippetbox.wook.net/internal/assert"
+	"snippetbox.wook.net/internal/models"
+	"snippetbox.wook.net/internal/models/mocks"
+)
+
+func TestLoader(t *testing.T) {
+	var batches [][]int
+
+	l := newLoader(func(keys []int) (map[int]string, error) {
+		batches = append(batches, keys)
+		values := make(map[int]string)
+		for _, k := range keys {
+			if k != 3 {
+				values[k] = strings.Repeat("x", k)
+			}
+		}
+		return values, nil
+	})
+
+	a, b, c, again := l.Load(1), l.Load(2), l.Load(3), l.Load(1)
+
+	v, err := b()
+	assert.NilError(t, err)
+	assert.Equal(t, v, "xx")
+
+	v, _ = a()
+	assert.Equal(t, v, "x")
+	v, _ = c()
+	assert.Equal(t, v, "")
+	v, _ = again()
+	assert.Equal(t, v, "x")
+
+	// 이미 가져온 키는 다시 요청하지 않습니다.
+	v, _ = l.Load(2)()
+	assert.Equal(t, v, "xx")
+
+	assert.Equal(t, len(batches), 1)
+	assert.Equal(t, len(batches[0]), 3)
+}
+
+func TestGraphQL(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	post := func(query string) (int, string) {
+		body, _ := json.Marshal(map[string]any{"query": query})
+		code, _, rsBody := ts.request(t, http.MethodPost, "/graphql", http.Header{"Content-Type": {"application/json"}}, string(body))
+		return code, rsBody
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Connection",
+			query:    `{ snippets(first: 5, tag: "haiku") { totalCount pageInfo { hasNextPage } edges { node { id title tags { name snippetCount } author { name } } } } }`,
+			wantCode: http.StatusOK,
+			wantBody: `{"data":{"snippets":{"edges":[{"node":{"author":{"name":"Alice Jones"},"id":"1","tags":[{"name":"haiku","snippetCount":1}],"title":"An old silent pond"}}],"pageInfo":{"hasNextPage":false},"totalCount":1}}}`,
+		},
+		{
+			name:     "Author connection",
+			query:    `{ user(id: "1") { name email snippets { totalCount edges { node { title } } } } }`,
+			wantCode: http.StatusOK,
+			wantBody: `{"data":{"user":{"email":null,"name":"Alice Jones","snippets":{"edges":[{"node":{"title":"An old silent pond"}}],"totalCount":1}}}}`,
+		},
+		{
+			name:     "Missing records",
+			query:    `{ snippet(id: "2") { title } user(id: "x") { name } tag(name: "none") { name } viewer { name } }`,
+			wantCode: http.StatusOK,
+			wantBody: `{"data":{"snippet":null,"tag":null,"user":null,"viewer":null}}`,
+		},
+		{
+			name:     "Invalid cursor",
+			query:    `{ snippets(after: "nope") { totalCount } }`,
+			wantCode: http.StatusOK,
+			wantBody: `invalid cursor`,
+		},
+		{
+			name:     "Syntax error",
+			query:    `{ snippets {`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `Syntax Error`,
+		},
+		{
+			name:     "Unknown field",
+			query:    `{ passwords }`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `Cannot query field`,
+		},
+		{
+			name:     "Too deep",
+			query:    `{ snippet(id: "1") { author { snippets { edges { node { author { snippets { edges { node { author { name } } } } } } } } } } }`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `query depth 11 exceeds the maximum of 10`,
+		},
+		{
+			name:     "Too complex",
+			query:    `{ snippets(first: 100) { edges { node { author { snippets(first: 50) { totalCount } } } } } }`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `query complexity`,
+		},
+		{
+			name:     "Too complex through fragments",
+			query:    `{ snippets(first: 100) { ...edges } } fragment edges on SnippetConnection { edges { node { author { snippets(first: 50) { totalCount } } } } }`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `query complexity`,
+		},
+		{
+			name:     "Too complex through variable defaults",
+			query:    `query($n: Int = 100) { snippets(first: $n) { edges { node { author { snippets(first: $n) { totalCount } } } } } }`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `query complexity`,
+		},
+		{
+			name:     "Too complex through unknown variables",
+			query:    `query($n: Int) { snippets(first: $n) { edges { node { author { snippets(first: $n) { totalCount } } } } } }`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `query complexity`,
+		},
+		{
+			name:     "Variable default",
+			query:    `query($n: Int = 5) { snippets(first: $n) { totalCount } }`,
+			wantCode: http.StatusOK,
+			wantBody: `{"data":{"snippets":{"totalCount":1}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := post(tt.query)
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+
+	t.Run("GET", func(t *testing.T) {
+		q := url.Values{}
+		q.Set("query", `query One($id: ID!) { snippet(id: $id) { title } }`)
+		q.Set("variables", `{"id": "1"}`)
+
+		code, _, body := ts.get(t, "/graphql?"+q.Encode())
+		assert.Equal(t, code, http.StatusOK)
+		assert.Equal(t, body, `{"data":{"snippet":{"title":"An old silent pond"}}}`)
+	})
+
+	t.Run("Viewer", func(t *testing.T) {
+		ts.login(t)
+
+		code, body := post(`{ viewer { id email } }`)
+		assert.Equal(t, code, http.StatusOK)
+		assert.Equal(t, body, `{"data":{"viewer":{"email":"alice@example.com","id":"1"}}}`)
+	})
+}
+
+// pagedSnippetModel은 작성자가 서로 다른 스니펫 여러 개를 돌려주는 SnippetModel입니다.
+type pagedSnippetModel struct {
+	mocks.SnippetModel
+}
+
+func (m *pagedSnippetModel) Page(filter models.SnippetFilter, after, limit int) (*models.SnippetPage, error) {
+	page := &models.SnippetPage{Total: 3}
+	for id := 3; id >= 1; id-- {
+		page.Snippets = append(page.Snippets, &models.Snippet{ID: id, UserID: id, Title: fmt.Sprintf("Snippet %d", id)})
+	}
+	return page, nil
+}
+
+// countingUserModel은 GetMany 호출을 기록합니다.
+type countingUserModel struct {
+	mocks.UserModel
+	calls [][]int
+}
+
+func (m *countingUserModel) GetMany(ids []int) ([]*models.User, error) {
+	m.calls = append(m.calls, ids)
+	users := []*models.User{}
+	for _, id := range ids {
+		users = append(users, &models.User{ID: id, Name: fmt.Sprintf("User %d", id)})
+	}
+	return users, nil
+}
+
+func TestGraphQLBatching(t *testing.T) {
+	app := newTestApplication(t)
+	users := &countingUserModel{}
+	app.users = users
+	app.snippets = &pagedSnippetModel{}
+
+	result, ok := app.executeGraphQL(context.Background(), graphqlRequest{
+		Query: `{ snippets(first: 2) { pageInfo { hasNextPage endCursor } edges { node { author { name } } } } }`,
+	})
+	assert.Equal(t, ok, true)
+	assert.Equal(t, len(result.Errors), 0)
+
+	js, err := json.Marshal(result.Data)
+	assert.NilError(t, err)
+	assert.Equal(t, string(js), `{"snippets":{"edges":[{"node":{"author":{"name":"User 3"}}},{"node":{"author":{"name":"User 2"}}}],"pageInfo":{"endCursor":"`+encodeCursor(2)+`","hasNextPage":true}}}`)
+
+	// 두 작성자를 한 번의 GetMany 호출로 가져와야 합니다.
+	assert.Equal(t, len(users.calls), 1)
+	assert.Equal(t, len(users.calls[0]), 2)
+}
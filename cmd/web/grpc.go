@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"runtime/debug"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"snippetbox.wook.net/internal/models"
+	"snippetbox.wook.net/internal/snippetpb"
+	"snippetbox.wook.net/internal/validator"
+)
+
+// grpcScopes는 RPC마다 필요한 토큰 권한 범위입니다. 여기에 없는 RPC는 호출할 수 없습니다.
+var grpcScopes = map[string]string{
+	snippetpb.SnippetService_Create_FullMethodName: models.ScopeSnippetsWrite,
+	snippetpb.SnippetService_Get_FullMethodName:    models.ScopeSnippetsRead,
+	snippetpb.SnippetService_List_FullMethodName:   models.ScopeSnippetsRead,
+	snippetpb.SnippetService_Search_FullMethodName: models.ScopeSnippetsRead,
+}
+
+// newGRPCServer는 SnippetService를 등록한 gRPC 서버를 만듭니다. creds가 nil이면
+// 암호화하지 않은 연결을 받으므로 테스트에서만 nil을 사용합니다.
+func (app *application) newGRPCServer(creds credentials.TransportCredentials) *grpc.Server {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(app.grpcRecoverPanic, app.grpcAuthenticate),
+	}
+	if creds != nil {
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	srv := grpc.NewServer(opts...)
+	snippetpb.RegisterSnippetServiceServer(srv, &snippetServer{app: app})
+
+	return srv
+}
+
+// grpcInternalError는 serverError와 같이 오류와 스택 추적을 기록하고, 클라이언트에게는
+// 내부 정보가 없는 INTERNAL 상태를 반환합니다.
+func (app *application) grpcInternalError(err error) error {
+	trace := fmt.Sprintf("%s\n%s", err.Error(), debug.Stack())
+	app.errorLog.Output(2, trace)
+
+	return status.Error(codes.Internal, "internal server error")
+}
+
+// grpcRecoverPanic은 recoverPanic 미들웨어와 같이 핸들러의 패닉을 INTERNAL 오류로 바꿉니다.
+func (app *application) grpcRecoverPanic(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = app.grpcInternalError(fmt.Errorf("%s", r))
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+// grpcAuthenticate는 authenticateToken 미들웨어와 같은 방식으로 "authorization: Bearer <token>"
+// 메타데이터의 토큰을 확인하고, 토큰에 RPC에 필요한 권한 범위가 있는지 검사합니다.
+func (app *application) grpcAuthenticate(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing authentication token")
+	}
+
+	scheme, plaintext, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication token")
+	}
+
+	token, err := app.tokens.Authenticate(strings.TrimSpace(plaintext))
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCredentials) {
+			return nil, status.Error(codes.Unauthenticated, "invalid authentication token")
+		}
+		return nil, app.grpcInternalError(err)
+	}
+
+	exists, err := app.users.Exists(token.UserID)
+	if err != nil {
+		return nil, app.grpcInternalError(err)
+	}
+	if !exists {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication token")
+	}
+
+	scope, ok := grpcScopes[info.FullMethod]
+	if !ok || !token.HasScope(scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "this token does not have the %s scope", scope)
+	}
+
+	ctx = context.WithValue(ctx, isAuthenticatedContextKey, true)
+	ctx = context.WithValue(ctx, authenticatedUserIDContextKey, token.UserID)
+	ctx = context.WithValue(ctx, tokenContextKey, token)
+
+	return handler(ctx, req)
+}
+
+// grpcValidationError는 validator.Validator에 쌓인 오류를 BadRequest 세부 정보가 붙은
+// INVALID_ARGUMENT 상태로 바꿉니다. fieldNames는 validator의 키를 protobuf 필드 이름으로 바꿉니다.
+func grpcValidationError(v validator.Validator, fieldNames map[string]string) error {
+	details := &errdetails.BadRequest{}
+
+	for key, message := range v.FieldErrors {
+		if name, ok := fieldNames[key]; ok {
+			key = name
+		}
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       key,
+			Description: message,
+		})
+	}
+	for _, message := range v.NonFieldErrors {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Description: message,
+		})
+	}
+
+	st, err := status.New(codes.InvalidArgument, "validation failed").WithDetails(details)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "validation failed")
+	}
+
+	return st.Err()
+}
+
+func snippetToProto(s *models.Snippet) *snippetpb.Snippet {
+	return &snippetpb.Snippet{
+		Id:      int64(s.ID),
+		UserId:  int64(s.UserID),
+		Author:  s.Author,
+		Title:   s.Title,
+		Content: s.Content,
+		Tags:    s.Tags,
+		Created: timestamppb.New(s.Created),
+		Expires: timestamppb.New(s.Expires),
+		Views:   int64(s.Views),
+	}
+}
+
+// snippetServer는 SnippetService를 SnippetModelInterface로 구현합니다.
+type snippetServer struct {
+	snippetpb.UnimplementedSnippetServiceServer
+	app *application
+}
+
+func (s *snippetServer) Create(ctx context.Context, req *snippetpb.CreateRequest) (*snippetpb.Snippet, error) {
+	var v validator.Validator
+
+	tags := parseTags(strings.Join(req.GetTags(), ","))
+	expires := int(req.GetExpiresDays())
+	if expires == 0 {
+		expires = 365
+	}
+
+	checkSnippet(&v, req.GetTitle(), req.GetContent(), tags)
+	v.CheckField(validator.PermittedValue(expires, 1, 7, 365), "expires", "This field must equal 1, 7 or 365")
+
+	if !v.Valid() {
+		return nil, grpcValidationError(v, map[string]string{"expires": "expires_days"})
+	}
+
+	userID, _ := ctx.Value(authenticatedUserIDContextKey).(int)
+
+	id, err := s.app.snippets.Insert(userID, req.GetTitle(), req.GetContent(), expires, tags)
+	if err != nil {
+		return nil, s.app.grpcInternalError(err)
+	}
+
+	snippet := insertedSnippet(id, userID, req.GetTitle(), req.GetContent(), tags, expires)
+	s.app.snippetEvent(models.EventSnippetCreated, snippet)
+	s.app.publishSnippet(snippet)
+
+	return snippetToProto(snippet), nil
+}
+
+func (s *snippetServer) Get(ctx context.Context, req *snippetpb.GetRequest) (*snippetpb.Snippet, error) {
+	id := req.GetId()
+	if id < 1 || id > math.MaxInt32 {
+		return nil, status.Error(codes.NotFound, "snippet not found")
+	}
+
+	snippet, err := s.app.snippets.Get(int(id))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			return nil, status.Error(codes.NotFound, "snippet not found")
+		}
+		return nil, s.app.grpcInternalError(err)
+	}
+
+	return snippetToProto(snippet), nil
+}
+
+func (s *snippetServer) List(ctx context.Context, req *snippetpb.ListRequest) (*snippetpb.ListResponse, error) {
+	return s.list("", req.GetPage(), req.GetPageSize())
+}
+
+func (s *snippetServer) Search(ctx context.Context, req *snippetpb.SearchRequest) (*snippetpb.ListResponse, error) {
+	query := strings.TrimSpace(req.GetQuery())
+	if query == "" {
+		var v validator.Validator
+		v.AddFieldError("query", "This field cannot be blank")
+		return nil, grpcValidationError(v, nil)
+	}
+
+	return s.list(query, req.GetPage(), req.GetPageSize())
+}
+
+// list는 apiSnippetList와 같은 규칙으로 페이지 인수를 검사한 뒤 스니펫 목록을 반환합니다.
+// page와 pageSize가 0이면 기본값(1, 20)을 사용합니다.
+func (s *snippetServer) list(query string, page, pageSize int32) (*snippetpb.ListResponse, error) {
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = 20
+	}
+
+	var v validator.Validator
+
+	v.CheckField(page >= 1, "page", "This field must be greater than zero")
+	v.CheckField(page <= 10_000, "page", "This field must be a maximum of 10000")
+	v.CheckField(pageSize >= 1, "page_size", "This field must be greater than zero")
+	v.CheckField(pageSize <= 100, "page_size", "This field must be a maximum of 100")
+	v.CheckField(validator.MaxChars(query, 100), "query", "This field cannot be more than 100 characters long")
+
+	if !v.Valid() {
+		return nil, grpcValidationError(v, nil)
+	}
+
+	snippets, total, err := s.app.snippets.List(query, int(page), int(pageSize))
+	if err != nil {
+		return nil, s.app.grpcInternalError(err)
+	}
+
+	p := newPagination(int(page), int(pageSize), total)
+
+	resp := &snippetpb.ListResponse{
+		Snippets:     make([]*snippetpb.Snippet, 0, len(snippets)),
+		Page:         int32(p.Page),
+		PageSize:     int32(p.PageSize),
+		LastPage:     int32(p.LastPage),
+		TotalRecords: int32(p.TotalRecords),
+	}
+	for _, snippet := range snippets {
+		resp.Snippets = append(resp.Snippets, snippetToProto(snippet))
+	}
+
+	return resp, nil
+}
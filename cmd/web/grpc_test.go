@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"snippetbox.wook.net/internal/assert"
+	"snippetbox.wook.net/internal/snippetpb"
+)
+
+// newTestGRPCClient는 메모리 안의 bufconn 리스너로 테스트 애플리케이션의 gRPC 서버에
+// 연결된 SnippetService 클라이언트를 반환합니다.
+func newTestGRPCClient(t *testing.T) snippetpb.SnippetServiceClient {
+	app := newTestApplication(t)
+
+	lis := bufconn.Listen(1 << 20)
+	srv := app.newGRPCServer(nil)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return snippetpb.NewSnippetServiceClient(conn)
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestGRPCAuthentication(t *testing.T) {
+	client := newTestGRPCClient(t)
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		call     func(ctx context.Context) error
+		wantCode codes.Code
+	}{
+		{
+			name: "No token",
+			ctx:  context.Background(),
+			call: func(ctx context.Context) error {
+				_, err := client.Get(ctx, &snippetpb.GetRequest{Id: 1})
+				return err
+			},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "Invalid token",
+			ctx:  withToken("sbx_wrong"),
+			call: func(ctx context.Context) error {
+				_, err := client.Get(ctx, &snippetpb.GetRequest{Id: 1})
+				return err
+			},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "Wrong scheme",
+			ctx:  metadata.AppendToOutgoingContext(context.Background(), "authorization", "Basic sbx_valid"),
+			call: func(ctx context.Context) error {
+				_, err := client.Get(ctx, &snippetpb.GetRequest{Id: 1})
+				return err
+			},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "Read-only token reads",
+			ctx:  withToken("sbx_readonly"),
+			call: func(ctx context.Context) error {
+				_, err := client.Get(ctx, &snippetpb.GetRequest{Id: 1})
+				return err
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "Read-only token creates",
+			ctx:  withToken("sbx_readonly"),
+			call: func(ctx context.Context) error {
+				_, err := client.Create(ctx, &snippetpb.CreateRequest{Title: "Title", Content: "Content"})
+				return err
+			},
+			wantCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call(tt.ctx)
+			assert.Equal(t, status.Code(err), tt.wantCode)
+		})
+	}
+}
+
+func TestGRPCGet(t *testing.T) {
+	client := newTestGRPCClient(t)
+	ctx := withToken("sbx_valid")
+
+	snippet, err := client.Get(ctx, &snippetpb.GetRequest{Id: 1})
+	assert.NilError(t, err)
+	assert.Equal(t, snippet.GetTitle(), "An old silent pond")
+	assert.Equal(t, snippet.GetAuthor(), "Alice Jones")
+	assert.Equal(t, snippet.GetTags()[0], "haiku")
+
+	for _, id := range []int64{0, -1, 2, 1 << 40} {
+		_, err = client.Get(ctx, &snippetpb.GetRequest{Id: id})
+		assert.Equal(t, status.Code(err), codes.NotFound)
+	}
+}
+
+func TestGRPCCreate(t *testing.T) {
+	client := newTestGRPCClient(t)
+	ctx := withToken("sbx_valid")
+
+	snippet, err := client.Create(ctx, &snippetpb.CreateRequest{
+		Title:   "O snail",
+		Content: "Climb Mount Fuji",
+		Tags:    []string{"Haiku", "issa"},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, snippet.GetId(), int64(2))
+	assert.Equal(t, snippet.GetUserId(), int64(1))
+	assert.Equal(t, len(snippet.GetTags()), 2)
+
+	_, err = client.Create(ctx, &snippetpb.CreateRequest{Content: "Climb Mount Fuji", ExpiresDays: 30})
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+
+	fields := map[string]bool{}
+	for _, detail := range status.Convert(err).Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				fields[v.GetField()] = true
+			}
+		}
+	}
+	assert.Equal(t, fields["title"], true)
+	assert.Equal(t, fields["expires_days"], true)
+}
+
+func TestGRPCListAndSearch(t *testing.T) {
+	client := newTestGRPCClient(t)
+	ctx := withToken("sbx_readonly")
+
+	list, err := client.List(ctx, &snippetpb.ListRequest{})
+	assert.NilError(t, err)
+	assert.Equal(t, list.GetPage(), int32(1))
+	assert.Equal(t, list.GetPageSize(), int32(20))
+	assert.Equal(t, list.GetTotalRecords(), int32(1))
+	assert.Equal(t, len(list.GetSnippets()), 1)
+
+	_, err = client.List(ctx, &snippetpb.ListRequest{PageSize: 101})
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+
+	found, err := client.Search(ctx, &snippetpb.SearchRequest{Query: "pond"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(found.GetSnippets()), 1)
+
+	found, err = client.Search(ctx, &snippetpb.SearchRequest{Query: "frog"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(found.GetSnippets()), 0)
+
+	_, err = client.Search(ctx, &snippetpb.SearchRequest{Query: "  "})
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+}
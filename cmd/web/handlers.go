@@ -1,10 +1,15 @@
 package main
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"html"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"snippetbox.wook.net/internal/models"
@@ -19,10 +24,51 @@ import (
 type snippetCreateForm struct {
 	Title               string `form:"title"`
 	Content             string `form:"content"`
+	Tags                string `form:"tags"`
 	Expires             int    `form:"expires"`
 	validator.Validator `form:"-"`
 }
 
+// checkSnippet은 HTML 양식과 JSON API가 공통으로 사용하는 스니펫 유효성 검사입니다.
+func checkSnippet(v *validator.Validator, title, content string, tags []string) {
+	v.CheckField(validator.NotBlank(title), "title", "This field cannot be blank")
+	v.CheckField(validator.MaxChars(title, 100), "title", "This field cannot be more than 100 characters long")
+	v.CheckField(validator.NotBlank(content), "content", "This field cannot be blank")
+
+	v.CheckField(len(tags) <= 5, "tags", "This field cannot contain more than 5 tags")
+	for _, tag := range tags {
+		v.CheckField(validator.Matches(tag, validator.TagRX), "tags", "Tags may only contain letters, numbers and hyphens")
+	}
+}
+
+type collectionCreateForm struct {
+	Name                string `form:"name"`
+	Public              bool   `form:"public"`
+	validator.Validator `form:"-"`
+}
+
+// collectionSnippetForm은 컬렉션에 스니펫을 추가, 제거, 이동하는 양식에서 공통으로 사용합니다.
+type collectionSnippetForm struct {
+	SnippetID int    `form:"snippet_id"`
+	Direction string `form:"direction"`
+}
+
+type webhookCreateForm struct {
+	URL                 string `form:"url"`
+	validator.Validator `form:"-"`
+}
+
+type collaboratorAddForm struct {
+	Email               string `form:"email"`
+	validator.Validator `form:"-"`
+}
+
+type tokenCreateForm struct {
+	Name                string   `form:"name"`
+	Scopes              []string `form:"scopes"`
+	validator.Validator `form:"-"`
+}
+
 type userSignupForm struct {
 	Name                string `form:"name"`
 	Email               string `form:"email"`
@@ -33,20 +79,63 @@ type userSignupForm struct {
 type userLoginForm struct {
 	Email               string `form:"email"`
 	Password            string `form:"password"`
+	RememberMe          bool   `form:"remember_me"`
 	validator.Validator `form:"-"`
 }
 
 func (app *application) home(w http.ResponseWriter, r *http.Request) {
 	snippets, err := app.snippets.Latest()
 	if err != nil {
-		app.serverError(w, err)
+		app.serverError(w, r, err)
+		return
+	}
+
+	// 같은 URL이 Accept 헤더에 따라 다른 표현을 반환하므로 캐시가 이를 구분하도록 알려 줍니다.
+	w.Header().Add("Vary", "Accept")
+
+	switch negotiateFormat(r) {
+	case formatJSON:
+		app.writeJSON(w, http.StatusOK, map[string]any{"snippets": snippets})
+		return
+	case formatText:
+		var buf bytes.Buffer
+		for _, s := range snippets {
+			fmt.Fprintf(&buf, "%d\t%s\t%s\n", s.ID, s.Created.UTC().Format(time.RFC3339), s.Title)
+		}
+		app.writeText(w, http.StatusOK, buf.Bytes())
 		return
 	}
 
 	data := app.newTemplateData(r)
 	data.Snippets = snippets
 
-	app.render(w, http.StatusOK, "home.go.tpl", data)
+	app.render(w, r, http.StatusOK, "home.go.tpl", data)
+}
+
+// trendingView 핸들러는 백그라운드에서 미리 계산해 둔 인기 순위를 보여줍니다.
+// 기간은 ?period= 쿼리 문자열로 선택하며 기본값은 최근 24시간입니다.
+func (app *application) trendingView(w http.ResponseWriter, r *http.Request) {
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = models.Period24h
+	}
+
+	if !validator.PermittedValue(period, models.Period24h, models.Period7d, models.Period30d, models.PeriodAll) {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	rankings, err := app.trending.Get(period, 20)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Period = period
+	data.Rankings = rankings
+
+	app.render(w, r, http.StatusOK, "trending.go.tpl", data)
 }
 
 func (app *application) snippetView(w http.ResponseWriter, r *http.Request) {
@@ -55,24 +144,123 @@ func (app *application) snippetView(w http.ResponseWriter, r *http.Request) {
 
 	id, err := strconv.Atoi(params.ByName("id"))
 	if err != nil || id < 1 {
-		app.notFound(w)
+		app.notFound(w, r)
 		return
 	}
 
 	snippet, err := app.snippets.Get(id)
 	if err != nil {
 		if errors.Is(err, models.ErrNoRecord) {
-			app.notFound(w)
+			app.notFound(w, r)
 		} else {
-			app.serverError(w, err)
+			app.serverError(w, r, err)
 		}
 		return
 	}
 
+	// 조회수는 메모리에서만 증가시키고, 데이터베이스에는 백그라운드 플러셔가 기록합니다.
+	// 아직 기록되지 않은 조회수도 화면에 반영되도록 더해 줍니다.
+	app.views.Inc(id)
+	snippet.Views += app.views.Pending(id)
+
+	w.Header().Add("Vary", "Accept")
+
+	switch negotiateFormat(r) {
+	case formatJSON:
+		app.writeJSON(w, http.StatusOK, map[string]any{"snippet": snippet})
+		return
+	case formatText:
+		app.writeText(w, http.StatusOK, []byte(snippet.Content))
+		return
+	}
+
 	data := app.newTemplateData(r)
 	data.Snippet = snippet
+	data.EmbedCode = embedHTML(app.absoluteURL(r, fmt.Sprintf("/embed/%d", id)), embedWidth, embedHeight)
+
+	// 로그인한 사용자에게는 스니펫을 자신의 컬렉션에 추가하는 양식을 보여주고, 편집할 수 있는
+	// 사용자에게는 공동 편집 링크도 보여줍니다.
+	if data.IsAuthenticated {
+		data.Collections, err = app.collections.ForUser(app.authenticatedUserID(r))
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+
+		data.CanEdit, err = app.canEditSnippet(snippet, app.authenticatedUserID(r))
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	app.render(w, r, http.StatusOK, "view.go.tpl", data)
+}
+
+// snippetEmbed는 다른 사이트의 iframe 안에 넣을 수 있는 머리글 없는 스니펫 페이지를 렌더링합니다.
+// 라우트에 allowEmbedding 미들웨어를 적용해야 허용된 출처에서 프레임으로 표시됩니다.
+func (app *application) snippetEmbed(w http.ResponseWriter, r *http.Request) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	id, err := strconv.Atoi(params.ByName("id"))
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	snippet, err := app.snippets.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return
+	}
+
+	app.views.Inc(id)
 
-	app.render(w, http.StatusOK, "view.go.tpl", data)
+	data := &templateData{
+		Snippet: snippet,
+		BaseURL: app.absoluteURL(r, ""),
+	}
+
+	app.render(w, r, http.StatusOK, "embed.go.tpl", data)
+}
+
+// snippetEdit는 공동 편집 페이지를 렌더링합니다. 편집 내용은 페이지의 스크립트가
+// /snippet/edit/:id/ws WebSocket으로 주고받습니다. 스니펫 작성자와 작성자가 초대한
+// 사용자만 편집할 수 있으며, 다른 사용자에게는 404를 응답합니다.
+func (app *application) snippetEdit(w http.ResponseWriter, r *http.Request) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	id, err := strconv.Atoi(params.ByName("id"))
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	snippet, err := app.snippets.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return
+	}
+
+	ok, err := app.canEditSnippet(snippet, app.authenticatedUserID(r))
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+	if !ok {
+		app.notFound(w, r)
+		return
+	}
+
+	app.renderSnippetEdit(w, r, http.StatusOK, snippet, collaboratorAddForm{})
 }
 
 func (app *application) snippetCreate(w http.ResponseWriter, r *http.Request) {
@@ -84,7 +272,7 @@ func (app *application) snippetCreate(w http.ResponseWriter, r *http.Request) {
 	data.Form = snippetCreateForm{
 		Expires: 365,
 	}
-	app.render(w, http.StatusOK, "create.go.tpl", data)
+	app.render(w, r, http.StatusOK, "create.go.tpl", data)
 }
 
 func (app *application) snippetCreatePost(w http.ResponseWriter, r *http.Request) {
@@ -92,27 +280,31 @@ func (app *application) snippetCreatePost(w http.ResponseWriter, r *http.Request
 
 	err := app.decodePostForm(r, &form)
 	if err != nil {
-		app.clientError(w, http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest)
 		return
 	}
 
-	form.CheckField(validator.NotBlank(form.Title), "title", "This field cannot be blank")
-	form.CheckField(validator.MaxChars(form.Title, 100), "title", "This field cannot be more than 100 characters long")
-	form.CheckField(validator.NotBlank(form.Content), "content", "This field cannot be blank")
+	tags := parseTags(form.Tags)
+
+	checkSnippet(&form.Validator, form.Title, form.Content, tags)
 	form.CheckField(validator.PermittedValue(form.Expires, 1, 7, 365), "expires", "This field must equal 1, 7 or 365")
 
 	if !form.Valid() {
 		data := app.newTemplateData(r)
 		data.Form = form
-		app.render(w, http.StatusUnprocessableEntity, "create.go.tpl", data)
+		app.render(w, r, http.StatusUnprocessableEntity, "create.go.tpl", data)
 		return
 	}
 
-	id, err := app.snippets.Insert(form.Title, form.Content, form.Expires)
+	id, err := app.snippets.Insert(app.authenticatedUserID(r), form.Title, form.Content, form.Expires, tags)
 	if err != nil {
-		app.serverError(w, err)
+		app.serverError(w, r, err)
 		return
 	}
+
+	snippet := insertedSnippet(id, app.authenticatedUserID(r), form.Title, form.Content, tags, form.Expires)
+	app.snippetEvent(models.EventSnippetCreated, snippet)
+	app.publishSnippet(snippet)
 	// Put() 메서드를 사용하여 문자열 값("Snippet successfully created!")과
 	// 해당 키("flash")를 세션 데이터에 추가합니다.
 	app.sessionManager.Put(r.Context(), "flash", "Snippet successfully created!")
@@ -120,10 +312,337 @@ func (app *application) snippetCreatePost(w http.ResponseWriter, r *http.Request
 	http.Redirect(w, r, fmt.Sprintf("/snippet/view/%d", id), http.StatusSeeOther)
 }
 
+func (app *application) collectionList(w http.ResponseWriter, r *http.Request) {
+	collections, err := app.collections.ForUser(app.authenticatedUserID(r))
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Collections = collections
+	data.Form = collectionCreateForm{}
+	app.render(w, r, http.StatusOK, "collections.go.tpl", data)
+}
+
+func (app *application) collectionCreatePost(w http.ResponseWriter, r *http.Request) {
+	var form collectionCreateForm
+
+	err := app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Name), "name", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.Name, 100), "name", "This field cannot be more than 100 characters long")
+
+	userID := app.authenticatedUserID(r)
+
+	if !form.Valid() {
+		collections, err := app.collections.ForUser(userID)
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+
+		data := app.newTemplateData(r)
+		data.Collections = collections
+		data.Form = form
+		app.render(w, r, http.StatusUnprocessableEntity, "collections.go.tpl", data)
+		return
+	}
+
+	id, err := app.collections.Insert(userID, form.Name, form.Public)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Collection successfully created!")
+
+	http.Redirect(w, r, fmt.Sprintf("/collection/%d", id), http.StatusSeeOther)
+}
+
+// collectionView는 공개 컬렉션이거나 현재 사용자가 소유한 컬렉션만 보여줍니다.
+// 비공개 컬렉션의 존재 여부가 드러나지 않도록 다른 사용자에게는 404를 반환합니다.
+func (app *application) collectionView(w http.ResponseWriter, r *http.Request) {
+	collection, ok := app.collectionFromParams(w, r)
+	if !ok {
+		return
+	}
+
+	isOwner := collection.UserID == app.authenticatedUserID(r)
+	if !collection.Public && !isOwner {
+		app.notFound(w, r)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Collection = collection
+	data.IsOwner = isOwner
+	app.render(w, r, http.StatusOK, "collection.go.tpl", data)
+}
+
+func (app *application) collectionSharePost(w http.ResponseWriter, r *http.Request) {
+	collection, ok := app.ownedCollection(w, r)
+	if !ok {
+		return
+	}
+
+	var form collectionCreateForm
+
+	err := app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	err = app.collections.SetPublic(collection.ID, form.Public)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	if form.Public {
+		app.sessionManager.Put(r.Context(), "flash", "Collection is now public.")
+	} else {
+		app.sessionManager.Put(r.Context(), "flash", "Collection is now private.")
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/collection/%d", collection.ID), http.StatusSeeOther)
+}
+
+func (app *application) collectionAddPost(w http.ResponseWriter, r *http.Request) {
+	collection, ok := app.ownedCollection(w, r)
+	if !ok {
+		return
+	}
+
+	var form collectionSnippetForm
+
+	err := app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	err = app.collections.AddSnippet(collection.ID, form.SnippetID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNoRecord):
+			app.notFound(w, r)
+		case errors.Is(err, models.ErrDuplicateSnippet):
+			app.sessionManager.Put(r.Context(), "flash", "That snippet is already in this collection.")
+			http.Redirect(w, r, fmt.Sprintf("/collection/%d", collection.ID), http.StatusSeeOther)
+		default:
+			app.serverError(w, r, err)
+		}
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Snippet added to collection!")
+
+	http.Redirect(w, r, fmt.Sprintf("/collection/%d", collection.ID), http.StatusSeeOther)
+}
+
+func (app *application) collectionRemovePost(w http.ResponseWriter, r *http.Request) {
+	collection, ok := app.ownedCollection(w, r)
+	if !ok {
+		return
+	}
+
+	var form collectionSnippetForm
+
+	err := app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	err = app.collections.RemoveSnippet(collection.ID, form.SnippetID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/collection/%d", collection.ID), http.StatusSeeOther)
+}
+
+func (app *application) collectionMovePost(w http.ResponseWriter, r *http.Request) {
+	collection, ok := app.ownedCollection(w, r)
+	if !ok {
+		return
+	}
+
+	var form collectionSnippetForm
+
+	err := app.decodePostForm(r, &form)
+	if err != nil || !validator.PermittedValue(form.Direction, "up", "down") {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	offset := 1
+	if form.Direction == "up" {
+		offset = -1
+	}
+
+	err = app.collections.MoveSnippet(collection.ID, form.SnippetID, offset)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/collection/%d", collection.ID), http.StatusSeeOther)
+}
+
+func (app *application) tokenList(w http.ResponseWriter, r *http.Request) {
+	app.renderTokens(w, r, http.StatusOK, tokenCreateForm{Scopes: []string{models.ScopeSnippetsRead}}, "")
+}
+
+// tokenCreatePost는 새 토큰을 만든 뒤 리디렉션하지 않고 바로 페이지를 렌더링합니다.
+// 평문 토큰은 세션에도 저장되지 않고 이 응답에서 단 한 번만 표시됩니다.
+func (app *application) tokenCreatePost(w http.ResponseWriter, r *http.Request) {
+	var form tokenCreateForm
+
+	err := app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Name), "name", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.Name, 100), "name", "This field cannot be more than 100 characters long")
+	form.CheckField(len(form.Scopes) > 0, "scopes", "Select at least one scope")
+	for _, scope := range form.Scopes {
+		form.CheckField(validator.PermittedValue(scope, models.Scopes...), "scopes", "Unknown scope")
+	}
+
+	if !form.Valid() {
+		app.renderTokens(w, r, http.StatusUnprocessableEntity, form, "")
+		return
+	}
+
+	token, err := app.tokens.Insert(app.authenticatedUserID(r), form.Name, form.Scopes)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	app.renderTokens(w, r, http.StatusOK, tokenCreateForm{Scopes: []string{models.ScopeSnippetsRead}}, token)
+}
+
+func (app *application) tokenRevokePost(w http.ResponseWriter, r *http.Request) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	id, err := strconv.Atoi(params.ByName("id"))
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	err = app.tokens.Revoke(app.authenticatedUserID(r), id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Token revoked.")
+
+	http.Redirect(w, r, "/account/tokens", http.StatusSeeOther)
+}
+
+func (app *application) webhookList(w http.ResponseWriter, r *http.Request) {
+	app.renderWebhooks(w, r, http.StatusOK, webhookCreateForm{}, "")
+}
+
+// webhookCreatePost는 tokenCreatePost와 마찬가지로 서명 비밀 값을 이 응답에서 단 한 번만 표시합니다.
+func (app *application) webhookCreatePost(w http.ResponseWriter, r *http.Request) {
+	var form webhookCreateForm
+
+	err := app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form.URL = strings.TrimSpace(form.URL)
+
+	form.CheckField(validator.NotBlank(form.URL), "url", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.URL, 2048), "url", "This field cannot be more than 2048 characters long")
+	form.CheckField(validator.WebURL(form.URL), "url", "This field must be an absolute http or https URL")
+	// 주소는 보낼 때마다 다시 확인하지만, 바로 알 수 있는 내부 주소는 등록할 때 알려 줍니다.
+	form.CheckField(!privateWebhookHost(form.URL), "url", "This URL must point to a public address")
+
+	if !form.Valid() {
+		app.renderWebhooks(w, r, http.StatusUnprocessableEntity, form, "")
+		return
+	}
+
+	_, secret, err := app.webhooks.Insert(app.authenticatedUserID(r), form.URL)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	app.renderWebhooks(w, r, http.StatusOK, webhookCreateForm{}, secret)
+}
+
+// webhookView는 웹훅의 최근 전달 기록을 보여줍니다.
+func (app *application) webhookView(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := app.ownedWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := app.webhooks.Deliveries(webhook.ID, 50)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Webhook = webhook
+	data.Deliveries = deliveries
+	app.render(w, r, http.StatusOK, "webhook.go.tpl", data)
+}
+
+func (app *application) webhookDeletePost(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := app.ownedWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.webhooks.Delete(app.authenticatedUserID(r), webhook.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Webhook deleted.")
+
+	http.Redirect(w, r, "/account/webhooks", http.StatusSeeOther)
+}
+
 func (app *application) userSignup(w http.ResponseWriter, r *http.Request) {
 	data := app.newTemplateData(r)
 	data.Form = userSignupForm{}
-	app.render(w, http.StatusOK, "signup.go.tpl", data)
+	app.render(w, r, http.StatusOK, "signup.go.tpl", data)
 }
 
 func (app *application) userSignupPost(w http.ResponseWriter, r *http.Request) {
@@ -131,7 +650,7 @@ func (app *application) userSignupPost(w http.ResponseWriter, r *http.Request) {
 
 	err := app.decodePostForm(r, &form)
 	if err != nil {
-		app.clientError(w, http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest)
 		return
 	}
 
@@ -144,26 +663,29 @@ func (app *application) userSignupPost(w http.ResponseWriter, r *http.Request) {
 	if !form.Valid() {
 		data := app.newTemplateData(r)
 		data.Form = form
-		app.render(w, http.StatusUnprocessableEntity, "signup.go.tpl", data)
+		app.render(w, r, http.StatusUnprocessableEntity, "signup.go.tpl", data)
 		return
 	}
 
-	err = app.users.Insert(form.Name, form.Email, form.Password)
+	id, err := app.users.Insert(form.Name, form.Email, form.Password)
 	if err != nil {
 		if errors.Is(err, models.ErrDupliacteEmail) {
 			form.AddFieldError("email", "Email address is already in user")
 
 			data := app.newTemplateData(r)
 			data.Form = form
-			app.render(w, http.StatusUnprocessableEntity, "signup.go.tpl", data)
+			app.render(w, r, http.StatusUnprocessableEntity, "signup.go.tpl", data)
 		} else {
-			app.serverError(w, err)
+			app.serverError(w, r, err)
 		}
 
 		return
 	}
 
-	app.sessionManager.Put(r.Context(), "flash", "Your signup was successful. Please log in.")
+	// 계정은 이메일 주소를 인증한 뒤에야 로그인할 수 있습니다.
+	app.sendVerification(&models.User{ID: id, Name: form.Name, Email: form.Email})
+
+	app.sessionManager.Put(r.Context(), "flash", "Your signup was successful. We've sent a verification link to your email address.")
 
 	http.Redirect(w, r, "/user/login/", http.StatusSeeOther)
 }
@@ -171,7 +693,7 @@ func (app *application) userSignupPost(w http.ResponseWriter, r *http.Request) {
 func (app *application) userLogin(w http.ResponseWriter, r *http.Request) {
 	data := app.newTemplateData(r)
 	data.Form = userLoginForm{}
-	app.render(w, http.StatusOK, "login.go.tpl", data)
+	app.render(w, r, http.StatusOK, "login.go.tpl", data)
 }
 
 func (app *application) userLoginPost(w http.ResponseWriter, r *http.Request) {
@@ -179,7 +701,7 @@ func (app *application) userLoginPost(w http.ResponseWriter, r *http.Request) {
 
 	err := app.decodePostForm(r, &form)
 	if err != nil {
-		app.clientError(w, http.StatusBadRequest)
+		app.clientError(w, r, http.StatusBadRequest)
 		return
 	}
 
@@ -193,7 +715,7 @@ func (app *application) userLoginPost(w http.ResponseWriter, r *http.Request) {
 	if !form.Valid() {
 		data := app.newTemplateData(r)
 		data.Form = form
-		app.render(w, http.StatusUnprocessableEntity, "login.go.tpl", data)
+		app.render(w, r, http.StatusUnprocessableEntity, "login.go.tpl", data)
 		return
 	}
 
@@ -207,41 +729,160 @@ func (app *application) userLoginPost(w http.ResponseWriter, r *http.Request) {
 
 			data := app.newTemplateData(r)
 			data.Form = form
-			app.render(w, http.StatusUnprocessableEntity, "login.go.tpl", data)
+			app.render(w, r, http.StatusUnprocessableEntity, "login.go.tpl", data)
+			return
+		} else if errors.Is(err, models.ErrUnverified) {
+			form.AddNonFieldError("Please verify your email address before logging in")
+
+			data := app.newTemplateData(r)
+			data.Form = form
+			app.render(w, r, http.StatusUnprocessableEntity, "login.go.tpl", data)
 			return
 		} else {
-			app.serverError(w, err)
+			app.serverError(w, r, err)
 		}
 		return
 	}
 
+	// 2단계 인증을 사용하는 사용자는 인증 코드를 확인한 뒤에 로그인 상태가 됩니다.
+	_, err = app.twoFactor.Get(id)
+	if err == nil {
+		app.startTwoFactorLogin(w, r, id, form.RememberMe)
+		return
+	} else if !errors.Is(err, models.ErrNoRecord) {
+		app.serverError(w, r, err)
+		return
+	}
+
 	// 현재 세션에서 RenewToken() 메서드를 사용하여 세션 ID를 변경합니다.
 	// 사용자의 인증 상태 또는 권한 수준이 변경될 때(예: 로그인 및 로그아웃 작업)
 	// 새 세션 ID를 생성하는 것이 좋습니다.
 	err = app.sessionManager.RenewToken(r.Context())
 	if err != nil {
-		app.serverError(w, err)
+		app.serverError(w, r, err)
 	}
 	// 현재 사용자의 ID를 세션에 추가하여 이제 '로그인' 상태가 되도록 합니다.
 	app.sessionManager.Put(r.Context(), "authenticatedUserID", id)
 
+	err = app.startUserSession(r, id, form.RememberMe)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
 	http.Redirect(w, r, "/snippet/create", http.StatusSeeOther)
 }
 
 func (app *application) userLogoutPost(w http.ResponseWriter, r *http.Request) {
 	err := app.sessionManager.RenewToken(r.Context())
 	if err != nil {
-		app.serverError(w, err)
+		app.serverError(w, r, err)
 		return
 	}
 
-	app.sessionManager.Remove(r.Context(), "authenticatedUserID")
+	err = app.logoutSession(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
 
 	app.sessionManager.Put(r.Context(), "flash", "You've been logged out successfully !")
 
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
+// oembedResponse는 oEmbed 1.0 명세(https://oembed.com)의 "rich" 유형 응답 형식입니다.
+type oembedResponse struct {
+	Version      string `json:"version"`
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name,omitempty"`
+	ProviderName string `json:"provider_name"`
+	ProviderURL  string `json:"provider_url"`
+	CacheAge     int    `json:"cache_age"`
+	HTML         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+// 삽입용 iframe의 기본 크기입니다.
+const (
+	embedWidth  = 600
+	embedHeight = 300
+)
+
+// embedHTML은 /embed/:id 페이지를 가리키는 iframe 태그를 반환합니다.
+func embedHTML(src string, width, height int) string {
+	return fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" frameborder="0" loading="lazy"></iframe>`,
+		html.EscapeString(src), width, height)
+}
+
+// oembed는 ?url=로 전달된 스니펫 URL의 oEmbed 메타데이터를 반환합니다.
+// 명세에 따라 JSON 이외의 형식은 501, 알 수 없는 URL은 404로 응답합니다.
+func (app *application) oembed(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if format := query.Get("format"); format != "" && format != "json" {
+		app.clientError(w, r, http.StatusNotImplemented)
+		return
+	}
+
+	u, err := url.Parse(query.Get("url"))
+	if err != nil || query.Get("url") == "" {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	// 이 서버의 스니펫 URL만 허용합니다.
+	base, err := url.Parse(app.absoluteURL(r, "/"))
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+	if u.Host != base.Host || !strings.HasPrefix(u.Path, "/snippet/view/") {
+		app.notFound(w, r)
+		return
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(u.Path, "/snippet/view/"))
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	snippet, err := app.snippets.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return
+	}
+
+	// 소비자가 maxwidth, maxheight를 지정하면 그보다 크지 않게 맞춥니다.
+	width, height := embedWidth, embedHeight
+	if n, err := strconv.Atoi(query.Get("maxwidth")); err == nil && n > 0 && n < width {
+		width = n
+	}
+	if n, err := strconv.Atoi(query.Get("maxheight")); err == nil && n > 0 && n < height {
+		height = n
+	}
+
+	app.writeJSON(w, http.StatusOK, oembedResponse{
+		Version:      "1.0",
+		Type:         "rich",
+		Title:        snippet.Title,
+		AuthorName:   snippet.Author,
+		ProviderName: "Snippetbox",
+		ProviderURL:  app.absoluteURL(r, "/"),
+		CacheAge:     3600,
+		HTML:         embedHTML(app.absoluteURL(r, fmt.Sprintf("/embed/%d", snippet.ID)), width, height),
+		Width:        width,
+		Height:       height,
+	})
+}
+
 func ping(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("OK"))
 }
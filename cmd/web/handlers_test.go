@@ -3,6 +3,7 @@ package main
 import (
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 
 	"snippetbox.wook.net/internal/assert"
@@ -190,3 +191,381 @@ func TestUserSignup(t *testing.T) {
 		})
 	}
 }
+
+func TestTrending(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		urlPath  string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Default period",
+			urlPath:  "/trending",
+			wantCode: http.StatusOK,
+			wantBody: "An old silent pond",
+		},
+		{
+			name:     "Most viewed",
+			urlPath:  "/trending?period=all",
+			wantCode: http.StatusOK,
+			wantBody: "<a href='/trending?period=all' class='live'>",
+		},
+		{
+			name:     "Unknown period",
+			urlPath:  "/trending?period=1y",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, body := ts.get(t, tt.urlPath)
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantBody != "" {
+				assert.StringContains(t, body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCollectionView(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, _, body := ts.get(t, "/collection/1")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "Onboarding")
+	assert.StringContains(t, body, "An old silent pond")
+
+	// 다른 사용자의 비공개 컬렉션은 로그인 여부와 관계없이 보이지 않아야 합니다.
+	code, _, _ = ts.get(t, "/collection/2")
+	assert.Equal(t, code, http.StatusNotFound)
+
+	ts.login(t)
+
+	code, _, _ = ts.get(t, "/collection/2")
+	assert.Equal(t, code, http.StatusNotFound)
+
+	code, _, body = ts.get(t, "/collection/1")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<form action='/collection/1/share' method='POST'>")
+
+	code, _, body = ts.get(t, "/snippet/view/1")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<button formaction='/collection/1/add'>Onboarding</button>")
+}
+
+func TestCollectionAddPost(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	csrfToken := ts.login(t)
+
+	tests := []struct {
+		name      string
+		urlPath   string
+		snippetID string
+		wantCode  int
+	}{
+		{
+			name:      "Valid snippet",
+			urlPath:   "/collection/1/add",
+			snippetID: "2",
+			wantCode:  http.StatusSeeOther,
+		},
+		{
+			name:      "Already added",
+			urlPath:   "/collection/1/add",
+			snippetID: "1",
+			wantCode:  http.StatusSeeOther,
+		},
+		{
+			name:      "Non-existent snippet",
+			urlPath:   "/collection/1/add",
+			snippetID: "99",
+			wantCode:  http.StatusNotFound,
+		},
+		{
+			name:      "Collection owned by someone else",
+			urlPath:   "/collection/2/add",
+			snippetID: "2",
+			wantCode:  http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("snippet_id", tt.snippetID)
+			form.Add("csrf_token", csrfToken)
+
+			code, _, _ := ts.postForm(t, tt.urlPath, form)
+			assert.Equal(t, code, tt.wantCode)
+		})
+	}
+}
+
+func TestSnippetViewMeta(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	_, _, body := ts.get(t, "/snippet/view/1")
+
+	assert.StringContains(t, body, "<title>An old silent pond - Snippetbox</title>")
+	assert.StringContains(t, body, "<meta property='og:title' content='An old silent pond'>")
+	assert.StringContains(t, body, "<meta property='og:description' content='An old silent pond...'>")
+	assert.StringContains(t, body, "type='application/json+oembed'")
+}
+
+func TestOembed(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		snippet  string
+		format   string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Valid URL",
+			snippet:  ts.URL + "/snippet/view/1",
+			wantCode: http.StatusOK,
+			wantBody: `"type":"rich"`,
+		},
+		{
+			name:     "Non-existent snippet",
+			snippet:  ts.URL + "/snippet/view/2",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Other host",
+			snippet:  "https://example.com/snippet/view/1",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "XML format",
+			snippet:  ts.URL + "/snippet/view/1",
+			format:   "xml",
+			wantCode: http.StatusNotImplemented,
+		},
+		{
+			name:     "Missing URL",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := url.Values{}
+			if tt.snippet != "" {
+				query.Set("url", tt.snippet)
+			}
+			if tt.format != "" {
+				query.Set("format", tt.format)
+			}
+
+			code, header, body := ts.get(t, "/oembed?"+query.Encode())
+
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantBody != "" {
+				assert.Equal(t, header.Get("Content-Type"), "application/json")
+				assert.StringContains(t, body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestSnippetEmbed(t *testing.T) {
+	app := newTestApplication(t)
+	app.embedOrigins = []string{"https://wiki.example.com"}
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, header, body := ts.get(t, "/embed/1")
+
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, header.Get("X-Frame-Options"), "")
+	assert.StringContains(t, header.Get("Content-Security-Policy"), "frame-ancestors 'self' https://wiki.example.com")
+	assert.StringContains(t, body, "An old silent pond...")
+
+	// 삽입용 페이지에는 사이트의 내비게이션이 없어야 합니다.
+	if strings.Contains(body, "<nav>") {
+		t.Errorf("embed page should not contain the site navigation")
+	}
+
+	code, _, _ = ts.get(t, "/embed/2")
+	assert.Equal(t, code, http.StatusNotFound)
+
+	// 다른 페이지는 여전히 프레임으로 표시될 수 없어야 합니다.
+	_, header, body = ts.get(t, "/snippet/view/1")
+	assert.Equal(t, header.Get("X-Frame-Options"), "deny")
+	assert.StringContains(t, body, "&lt;iframe src=&#34;"+ts.URL+"/embed/1&#34;")
+}
+
+func TestTokenCreatePost(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	csrfToken := ts.login(t)
+
+	code, _, body := ts.get(t, "/account/tokens")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "laptop")
+
+	tests := []struct {
+		name     string
+		scopes   []string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Valid submission",
+			scopes:   []string{"snippets:read", "snippets:write"},
+			wantCode: http.StatusOK,
+			wantBody: "<code>sbx_new</code>",
+		},
+		{
+			name:     "No scopes",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "Select at least one scope",
+		},
+		{
+			name:     "Unknown scope",
+			scopes:   []string{"admin"},
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "Unknown scope",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("name", "ci")
+			form["scopes"] = tt.scopes
+			form.Add("csrf_token", csrfToken)
+
+			code, _, body := ts.postForm(t, "/account/tokens", form)
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
+
+func TestSnippetViewNegotiation(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	tests := []struct {
+		name            string
+		urlPath         string
+		accept          string
+		wantCode        int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "Default",
+			urlPath:         "/snippet/view/1",
+			wantCode:        http.StatusOK,
+			wantContentType: "text/html; charset=utf-8",
+			wantBody:        "<title>An old silent pond - Snippetbox</title>",
+		},
+		{
+			name:            "Browser",
+			urlPath:         "/snippet/view/1",
+			accept:          "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			wantCode:        http.StatusOK,
+			wantContentType: "text/html; charset=utf-8",
+			wantBody:        "<title>An old silent pond - Snippetbox</title>",
+		},
+		{
+			name:            "JSON",
+			urlPath:         "/snippet/view/1",
+			accept:          "application/json",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        `"title":"An old silent pond"`,
+		},
+		{
+			name:            "Plain text",
+			urlPath:         "/snippet/view/1",
+			accept:          "text/plain",
+			wantCode:        http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "An old silent pond...",
+		},
+		{
+			name:            "Preferred by quality",
+			urlPath:         "/snippet/view/1",
+			accept:          "text/html;q=0.5, application/json",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        `"snippet":`,
+		},
+		{
+			name:            "Not found as problem JSON",
+			urlPath:         "/snippet/view/2",
+			accept:          "application/json",
+			wantCode:        http.StatusNotFound,
+			wantContentType: "application/problem+json",
+			wantBody:        `{"type":"about:blank","title":"Not Found","status":404}`,
+		},
+		{
+			name:            "Not found as text",
+			urlPath:         "/snippet/view/2",
+			accept:          "text/plain",
+			wantCode:        http.StatusNotFound,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Not Found",
+		},
+		{
+			name:            "Home JSON",
+			urlPath:         "/",
+			accept:          "application/json",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        `{"snippets":[`,
+		},
+		{
+			name:            "Home plain text",
+			urlPath:         "/",
+			accept:          "text/plain",
+			wantCode:        http.StatusOK,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "1\t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.accept != "" {
+				header.Set("Accept", tt.accept)
+			}
+
+			code, headers, body := ts.request(t, http.MethodGet, tt.urlPath, header, "")
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.Equal(t, headers.Get("Content-Type"), tt.wantContentType)
+			assert.StringContains(t, strings.Join(headers.Values("Vary"), ","), "Accept")
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
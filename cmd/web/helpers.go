@@ -2,43 +2,118 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"mime"
+	"net"
 	"net/http"
 	"runtime/debug"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-playground/form/v4"
+	"github.com/julienschmidt/httprouter"
 	"github.com/justinas/nosurf"
+	"snippetbox.wook.net/internal/models"
 )
 
 // serverError는 오류 메시지와 스택 추적을 errorLog에 기록합니다,
 // 그런 다음 일반 500 내부 서버 오류 응답을 사용자에게 보냅니다.
-func (app *application) serverError(w http.ResponseWriter, err error) {
+func (app *application) serverError(w http.ResponseWriter, r *http.Request, err error) {
 	trace := fmt.Sprintf("%s\n%s", err.Error(), debug.Stack())
 	app.errorLog.Output(2, trace)
 
-	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	app.errorResponse(w, r, http.StatusInternalServerError)
 }
 
 // clientError 헬퍼는 특정 상태 코드와 해당 설명을 사용자에게 전송합니다.
 // 사용자에게 전송합니다. 이 책의 뒷부분에서 이를 사용하여 사용자가 보낸 요청에 문제가 있을 때
 // 400 "Bad Request"과 같은 응답을 보내는 데 사용하겠습니다.
-func (app *application) clientError(w http.ResponseWriter, status int) {
-	http.Error(w, http.StatusText(status), status)
+func (app *application) clientError(w http.ResponseWriter, r *http.Request, status int) {
+	app.errorResponse(w, r, status)
 }
 
 // 일관성을 위해 notFound 헬퍼도 구현하겠습니다. 이것은 단순히 클라이언트 에러에
 // 404 찾을 수 없음 응답을 사용자에게 보내는 클라이언트 오류에 대한 편의 래퍼입니다.
-func (app *application) notFound(w http.ResponseWriter) {
-	app.clientError(w, http.StatusNotFound)
+func (app *application) notFound(w http.ResponseWriter, r *http.Request) {
+	app.clientError(w, r, http.StatusNotFound)
 }
 
-func (app *application) render(w http.ResponseWriter, status int, page string, data *templateData) {
+// problem은 RFC 7807의 application/problem+json 응답 본문입니다.
+type problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+}
+
+// errorResponse는 협상된 형식에 맞춰 오류 응답을 보냅니다. JSON을 원하는 클라이언트에게는
+// application/problem+json을, 그 밖의 클라이언트에게는 지금까지처럼 일반 텍스트를 보냅니다.
+func (app *application) errorResponse(w http.ResponseWriter, r *http.Request, status int) {
+	w.Header().Add("Vary", "Accept")
+
+	if negotiateFormat(r) != formatJSON {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	js, _ := json.Marshal(problem{Type: "about:blank", Title: http.StatusText(status), Status: status})
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	w.Write(js)
+}
+
+// 콘텐츠 협상으로 선택할 수 있는 응답 형식입니다.
+const (
+	formatHTML = "html"
+	formatJSON = "json"
+	formatText = "text"
+)
+
+// negotiateFormat은 Accept 요청 헤더를 읽어 응답 형식을 고릅니다. q 값이 가장 큰 미디어 타입이
+// 선택되고, q 값이 같으면 먼저 나온 것이 우선합니다. 헤더가 없거나 알 수 없는 미디어 타입만
+// 있으면 HTML을 반환하므로 브라우저의 동작은 이전과 같습니다.
+func negotiateFormat(r *http.Request) string {
+	format, best := formatHTML, 0.0
+
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+		}
+		if q <= 0 || q <= best {
+			continue
+		}
+
+		switch mediaType {
+		case "text/html", "application/xhtml+xml", "text/*", "*/*":
+			format, best = formatHTML, q
+		case "application/json", "application/problem+json":
+			format, best = formatJSON, q
+		case "text/plain":
+			format, best = formatText, q
+		}
+	}
+
+	return format
+}
+
+func (app *application) render(w http.ResponseWriter, r *http.Request, status int, page string, data *templateData) {
 	ts, ok := app.templateCache[page]
 	if !ok {
 		err := fmt.Errorf("the template %s does not exist", page)
-		app.serverError(w, err)
+		app.serverError(w, r, err)
 		return
 	}
 
@@ -49,7 +124,8 @@ func (app *application) render(w http.ResponseWriter, status int, page string, d
 	// http.ResponseWriter. 오류가 발생하면 serverError() 헬퍼를 호출한 다음 반환합니다.
 	err := ts.ExecuteTemplate(buf, "base", data)
 	if err != nil {
-		app.serverError(w, err)
+		app.serverError(w, r, err)
+		return
 	}
 	// 템플릿이 오류 없이 버퍼에 기록되면 안전합니다.
 	// HTTP 상태 코드를 http.ResponseWriter에 기록합니다.
@@ -65,6 +141,8 @@ func (app *application) newTemplateData(r *http.Request) *templateData {
 		Flash:           app.sessionManager.PopString(r.Context(), "flash"),
 		IsAuthenticated: app.isAuthenticated(r),
 		CSRFToken:       nosurf.Token(r),
+		BaseURL:         app.absoluteURL(r, ""),
+		TOTPEnabled:     app.totpEnabled,
 	}
 }
 
@@ -103,3 +181,347 @@ func (app *application) isAuthenticated(r *http.Request) bool {
 
 	return isAuthenticated
 }
+
+// authenticatedUserID는 authenticate 미들웨어가 요청 컨텍스트에 저장한 사용자 ID를 반환합니다.
+// 인증되지 않은 요청이면 0을 반환합니다.
+func (app *application) authenticatedUserID(r *http.Request) int {
+	id, ok := r.Context().Value(authenticatedUserIDContextKey).(int)
+	if !ok {
+		return 0
+	}
+
+	return id
+}
+
+// sessionUserID는 LoadAndSave와 authenticate 미들웨어를 거치지 않는 요청에서 세션 쿠키를
+// 직접 읽어 로그인한 사용자의 ID를 반환합니다. 로그인하지 않았거나, authenticate와 같이
+// 로그인 유지 시간이 지났거나 로그인 기록이 없거나, 사용자가 더 이상 없으면 0을 반환합니다. 세션을 읽기만 하므로
+// 세션 만료 시간은 갱신되지 않고, 만료된 로그인은 다음 일반 요청에서 로그아웃됩니다.
+func (app *application) sessionUserID(r *http.Request) (int, error) {
+	cookie, err := r.Cookie(app.sessionManager.Cookie.Name)
+	if err != nil {
+		return 0, nil
+	}
+
+	ctx, err := app.sessionManager.Load(r.Context(), cookie.Value)
+	if err != nil {
+		return 0, err
+	}
+
+	id := app.sessionManager.GetInt(ctx, "authenticatedUserID")
+	if id == 0 {
+		return 0, nil
+	}
+
+	active, err := app.activeUserSession(ctx, id)
+	if err != nil || !active {
+		return 0, err
+	}
+
+	exists, err := app.users.Exists(id)
+	if err != nil || !exists {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// runPeriodically는 백그라운드 고루틴에서 interval마다 fn을 호출합니다.
+// fn이 반환한 오류는 기록만 하고 다음 주기에 다시 시도합니다.
+// app.quit 채널이 닫히면 고루틴이 종료되며, app.wg로 종료를 기다릴 수 있습니다.
+func (app *application) runPeriodically(interval time.Duration, fn func() error) {
+	app.wg.Add(1)
+
+	go func() {
+		defer app.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := fn(); err != nil {
+					app.errorLog.Print(err)
+				}
+			case <-app.quit:
+				return
+			}
+		}
+	}()
+}
+
+// collectionFromParams는 URL의 :id 매개변수에 해당하는 컬렉션을 가져옵니다.
+// 컬렉션을 찾지 못하면 적절한 오류 응답을 보낸 뒤 false를 반환합니다.
+func (app *application) collectionFromParams(w http.ResponseWriter, r *http.Request) (*models.Collection, bool) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	id, err := strconv.Atoi(params.ByName("id"))
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return nil, false
+	}
+
+	collection, err := app.collections.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return nil, false
+	}
+
+	return collection, true
+}
+
+// ownedCollection은 collectionFromParams와 같지만 현재 사용자가 소유한 컬렉션만 허용합니다.
+func (app *application) ownedCollection(w http.ResponseWriter, r *http.Request) (*models.Collection, bool) {
+	collection, ok := app.collectionFromParams(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	if collection.UserID != app.authenticatedUserID(r) {
+		app.notFound(w, r)
+		return nil, false
+	}
+
+	return collection, true
+}
+
+// absoluteURL은 path의 절대 URL을 반환합니다. -base-url 플래그가 설정되어 있으면
+// 그 값을, 그렇지 않으면 요청의 Host 헤더를 기준으로 합니다.
+// 서버는 항상 HTTPS로만 동작하므로 스킴은 https로 고정합니다. 메일에 넣을 링크에는
+// mailURL을 사용합니다.
+func (app *application) absoluteURL(r *http.Request, path string) string {
+	if app.baseURL != "" {
+		return app.baseURL + path
+	}
+	return "https://" + r.Host + path
+}
+
+// errNoBaseURL은 -base-url 플래그 없이 메일에 넣을 링크를 만들려고 할 때 반환됩니다.
+var errNoBaseURL = errors.New("-base-url is not set, refusing to send a link by email")
+
+// mailURL은 메일에 넣을 링크의 절대 URL을 반환합니다. Host 헤더는 클라이언트가 마음대로
+// 정할 수 있으므로, absoluteURL과 달리 요청을 기준으로 하지 않고 -base-url 플래그만
+// 사용합니다. 그렇지 않으면 공격자가 다른 사람의 주소로 재설정을 요청하면서 Host 헤더를
+// 바꿔 토큰이 담긴 링크가 공격자의 서버를 가리키게 할 수 있습니다.
+func (app *application) mailURL(path string) (string, error) {
+	if app.baseURL == "" {
+		return "", errNoBaseURL
+	}
+	return app.baseURL + path, nil
+}
+
+// remoteIP는 요청을 보낸 클라이언트의 IP 주소를 반환합니다.
+func remoteIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// writeJSON은 data를 JSON으로 인코딩하여 주어진 상태 코드와 함께 응답합니다.
+func (app *application) writeJSON(w http.ResponseWriter, status int, data any) {
+	js, err := json.Marshal(data)
+	if err != nil {
+		trace := fmt.Sprintf("%s\n%s", err.Error(), debug.Stack())
+		app.errorLog.Output(2, trace)
+
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"type":"about:blank","title":"Internal Server Error","status":500}`))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(js)
+}
+
+// insertedSnippet은 방금 저장한 스니펫을 다시 조회하지 않고 입력값으로 구성합니다.
+// 생성 시각은 데이터베이스의 UTC_TIMESTAMP()와 최대 몇 밀리초 정도 차이가 날 수 있습니다.
+func insertedSnippet(id, userID int, title, content string, tags []string, expires int) *models.Snippet {
+	now := time.Now().UTC().Truncate(time.Second)
+	return &models.Snippet{
+		ID:      id,
+		UserID:  userID,
+		Title:   title,
+		Content: content,
+		Tags:    tags,
+		Created: now,
+		Expires: now.AddDate(0, 0, expires),
+	}
+}
+
+// writeText는 body를 일반 텍스트로 응답합니다.
+func (app *application) writeText(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// parseTags는 쉼표나 공백으로 구분된 태그 문자열을 소문자 태그 목록으로 변환합니다.
+// 빈 항목과 중복된 태그는 제거됩니다.
+func parseTags(s string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+
+	tags := []string{}
+	seen := make(map[string]bool)
+
+	for _, tag := range fields {
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// renderTokens는 현재 사용자의 토큰 목록과 함께 토큰 관리 페이지를 렌더링합니다.
+// newToken이 비어 있지 않으면 방금 생성한 평문 토큰을 표시합니다.
+func (app *application) renderTokens(w http.ResponseWriter, r *http.Request, status int, form tokenCreateForm, newToken string) {
+	tokens, err := app.tokens.ForUser(app.authenticatedUserID(r))
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Tokens = tokens
+	data.NewToken = newToken
+	data.Scopes = models.Scopes
+	data.Form = form
+	app.render(w, r, status, "tokens.go.tpl", data)
+}
+
+// renderWebhooks는 현재 사용자의 웹훅 목록과 함께 웹훅 관리 페이지를 렌더링합니다.
+// newSecret이 비어 있지 않으면 방금 만든 웹훅의 서명 비밀 값을 표시합니다.
+func (app *application) renderWebhooks(w http.ResponseWriter, r *http.Request, status int, form webhookCreateForm, newSecret string) {
+	webhooks, err := app.webhooks.ForUser(app.authenticatedUserID(r))
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Webhooks = webhooks
+	data.NewSecret = newSecret
+	data.Form = form
+	app.render(w, r, status, "webhooks.go.tpl", data)
+}
+
+// ownedWebhook은 URL의 :id 매개변수에 해당하는 웹훅 중 현재 사용자가 등록한 것만 반환합니다.
+// 다른 사용자의 웹훅이면 존재를 드러내지 않도록 404를 응답합니다.
+func (app *application) ownedWebhook(w http.ResponseWriter, r *http.Request) (*models.Webhook, bool) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	id, err := strconv.Atoi(params.ByName("id"))
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return nil, false
+	}
+
+	webhook, err := app.webhooks.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return nil, false
+	}
+
+	if webhook.UserID != app.authenticatedUserID(r) {
+		app.notFound(w, r)
+		return nil, false
+	}
+
+	return webhook, true
+}
+
+// canEditSnippet은 사용자가 스니펫을 공동 편집할 수 있는지 확인합니다. 작성자와 작성자가
+// 초대한 공동 편집자만 편집할 수 있습니다.
+func (app *application) canEditSnippet(snippet *models.Snippet, userID int) (bool, error) {
+	if userID == 0 {
+		return false, nil
+	}
+	if snippet.UserID == userID {
+		return true, nil
+	}
+	return app.collaborators.Exists(snippet.ID, userID)
+}
+
+// ownedSnippet은 URL의 :id 매개변수에 해당하는 스니펫 중 현재 사용자가 작성한 것만 반환합니다.
+// 다른 사용자의 스니펫이면 404를 응답합니다.
+func (app *application) ownedSnippet(w http.ResponseWriter, r *http.Request) (*models.Snippet, bool) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	id, err := strconv.Atoi(params.ByName("id"))
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return nil, false
+	}
+
+	snippet, err := app.snippets.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return nil, false
+	}
+
+	if snippet.UserID == 0 || snippet.UserID != app.authenticatedUserID(r) {
+		app.notFound(w, r)
+		return nil, false
+	}
+
+	return snippet, true
+}
+
+// renderSnippetEdit는 공동 편집 페이지를 리비전 목록과 함께 렌더링합니다. 작성자에게는
+// 공동 편집자 목록과 초대 양식도 보여줍니다.
+func (app *application) renderSnippetEdit(w http.ResponseWriter, r *http.Request, status int, snippet *models.Snippet, form collaboratorAddForm) {
+	revisions, err := app.revisions.ForSnippet(snippet.ID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Snippet = snippet
+	data.Revisions = revisions
+	data.IsOwner = snippet.UserID != 0 && snippet.UserID == app.authenticatedUserID(r)
+	data.Form = form
+
+	if data.IsOwner {
+		data.Collaborators, err = app.collaborators.ForSnippet(snippet.ID)
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	app.render(w, r, status, "edit.go.tpl", data)
+}
+
+// backoff는 attempts번째 실패 뒤에 다음 시도까지 기다릴 시간을 반환합니다. 첫 실패 뒤에는
+// base만큼 기다리고, 실패할 때마다 두 배로 늘리되 maxDelay를 넘지 않습니다.
+func backoff(base, maxDelay time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
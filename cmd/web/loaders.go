@@ -0,0 +1,137 @@
+package main
+
+import (
+	"sync"
+
+	"snippetbox.wook.net/internal/models"
+)
+
+// loader는 dataloader 방식으로 여러 키의 조회를 하나로 묶습니다. Load는 키를 대기열에
+// 넣고 썽크를 반환하며, 썽크 중 하나가 처음 호출될 때 대기 중인 키를 모두 batch 함수로
+// 한꺼번에 가져옵니다. graphql-go는 같은 깊이의 필드를 모두 해석한 뒤에 썽크를 호출하므로
+// 목록의 모든 항목에서 요청한 키가 하나의 쿼리로 처리됩니다.
+//
+// 가져온 값은 loader가 살아 있는 동안 캐시되므로 loader는 요청마다 새로 만듭니다.
+// batch 결과에 없는 키는 V의 영값으로 해석됩니다.
+type loader[K comparable, V any] struct {
+	mu      sync.Mutex
+	batch   func(keys []K) (map[K]V, error)
+	pending []K
+	queued  map[K]bool
+	results map[K]V
+	errs    map[K]error
+}
+
+func newLoader[K comparable, V any](batch func(keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		batch:   batch,
+		queued:  make(map[K]bool),
+		results: make(map[K]V),
+		errs:    make(map[K]error),
+	}
+}
+
+// Load는 key를 다음 batch 호출에 포함시키고, 그 결과를 돌려주는 썽크를 반환합니다.
+func (l *loader[K, V]) Load(key K) func() (V, error) {
+	l.mu.Lock()
+	if _, done := l.results[key]; !done && !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if l.queued[key] {
+			l.dispatchLocked()
+		}
+
+		return l.results[key], l.errs[key]
+	}
+}
+
+// dispatchLocked는 대기 중인 키를 모두 batch 함수로 가져옵니다.
+func (l *loader[K, V]) dispatchLocked() {
+	keys := l.pending
+	l.pending = nil
+
+	values, err := l.batch(keys)
+
+	for _, key := range keys {
+		delete(l.queued, key)
+		if err != nil {
+			l.errs[key] = err
+		} else {
+			l.results[key] = values[key]
+		}
+	}
+}
+
+// userPageKey는 한 사용자의 스니펫 연결(connection) 페이지를 가리킵니다.
+type userPageKey struct {
+	userID int
+	after  int
+	limit  int
+}
+
+// graphqlLoaders는 GraphQL 요청 하나가 사용하는 loader들입니다.
+type graphqlLoaders struct {
+	users     *loader[int, *models.User]
+	snippets  *loader[int, *models.Snippet]
+	userPages *loader[userPageKey, *models.SnippetPage]
+	tagCounts *loader[string, int]
+}
+
+func (app *application) newGraphQLLoaders() *graphqlLoaders {
+	return &graphqlLoaders{
+		users: newLoader(func(ids []int) (map[int]*models.User, error) {
+			users, err := app.users.GetMany(ids)
+			if err != nil {
+				return nil, err
+			}
+
+			byID := make(map[int]*models.User, len(users))
+			for _, u := range users {
+				byID[u.ID] = u
+			}
+			return byID, nil
+		}),
+		snippets: newLoader(func(ids []int) (map[int]*models.Snippet, error) {
+			snippets, err := app.snippets.GetMany(ids)
+			if err != nil {
+				return nil, err
+			}
+
+			byID := make(map[int]*models.Snippet, len(snippets))
+			for _, s := range snippets {
+				byID[s.ID] = s
+			}
+			return byID, nil
+		}),
+		userPages: newLoader(func(keys []userPageKey) (map[userPageKey]*models.SnippetPage, error) {
+			// 같은 페이지 인수(after, limit)를 쓰는 사용자끼리 묶어 PageByUsers를 호출합니다.
+			// 보통은 목록의 모든 사용자가 같은 인수를 쓰므로 쿼리는 한 번입니다.
+			type args struct{ after, limit int }
+			groups := make(map[args][]int)
+			for _, key := range keys {
+				a := args{key.after, key.limit}
+				groups[a] = append(groups[a], key.userID)
+			}
+
+			pages := make(map[userPageKey]*models.SnippetPage, len(keys))
+			for a, userIDs := range groups {
+				byUser, err := app.snippets.PageByUsers(userIDs, a.after, a.limit)
+				if err != nil {
+					return nil, err
+				}
+				for id, page := range byUser {
+					pages[userPageKey{id, a.after, a.limit}] = page
+				}
+			}
+			return pages, nil
+		}),
+		tagCounts: newLoader(app.snippets.TagCounts),
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"snippetbox.wook.net/internal/mailer"
+	"snippetbox.wook.net/internal/models"
+	"snippetbox.wook.net/ui"
+)
+
+const (
+	// 한 번의 Deliver 호출에서 보내는 최대 메일 수입니다.
+	mailBatchSize = 20
+	// 이 횟수만큼 실패하면 더 이상 다시 시도하지 않습니다.
+	mailMaxAttempts = 6
+	// 첫 번째 재시도까지의 대기 시간이며, 실패할 때마다 두 배로 늘어납니다.
+	mailBaseDelay = time.Minute
+	mailMaxDelay  = time.Hour
+)
+
+// mailDispatcher는 보낼 편지함에서 때가 된 메일을 꺼내 mailer로 보냅니다. 실패한 메일은
+// webhookDispatcher와 같이 지수적으로 늘어나는 간격을 두고 다시 시도합니다.
+type mailDispatcher struct {
+	outbox models.OutboxModelInterface
+	mailer mailer.Mailer
+}
+
+func newMailDispatcher(outbox models.OutboxModelInterface, m mailer.Mailer) *mailDispatcher {
+	return &mailDispatcher{outbox: outbox, mailer: m}
+}
+
+// Deliver는 때가 된 메일을 보냅니다. runPeriodically와 함께 사용합니다.
+// SMTP 오류는 대기열에 기록하고, 데이터베이스 오류만 반환합니다.
+func (md *mailDispatcher) Deliver() error {
+	emails, err := md.outbox.Due(mailBatchSize)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, e := range emails {
+		err := md.mailer.Send(&mailer.Message{
+			To:       e.To,
+			Subject:  e.Subject,
+			TextBody: e.TextBody,
+			HTMLBody: e.HTMLBody,
+		})
+		if err == nil {
+			err = md.outbox.MarkSent(e.ID)
+		} else {
+			attempts := e.Attempts + 1
+			next := time.Now().Add(backoff(mailBaseDelay, mailMaxDelay, attempts))
+			err = md.outbox.MarkFailed(e.ID, err.Error(), next, attempts >= mailMaxAttempts)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sendEmail은 ui/mail의 templateFile 템플릿으로 메일을 만들어 보낼 편지함에 넣습니다.
+// 실제 전송은 mailDispatcher가 백그라운드에서 처리합니다.
+func (app *application) sendEmail(to, templateFile string, data any) error {
+	msg, err := mailer.Render(ui.Files, "mail/"+templateFile, to, data)
+	if err != nil {
+		return err
+	}
+
+	return app.outbox.Enqueue(msg.To, msg.Subject, msg.TextBody, msg.HTMLBody)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"net/mail"
+	"testing"
+	"time"
+
+	"snippetbox.wook.net/internal/assert"
+	"snippetbox.wook.net/internal/mailer"
+	"snippetbox.wook.net/internal/mailer/mailertest"
+	"snippetbox.wook.net/internal/models"
+	"snippetbox.wook.net/internal/models/mocks"
+)
+
+type mailResult struct {
+	message string
+	next    time.Time
+	giveUp  bool
+}
+
+// recordingOutboxModel은 due에 담긴 메일을 반환하고, 대기열에 넣은 메일과 전송 결과를
+// 기록하는 모의 모델입니다.
+type recordingOutboxModel struct {
+	mocks.OutboxModel
+	due      []*models.Email
+	enqueued []*models.Email
+	sent     []int
+	failed   map[int]mailResult
+}
+
+func (m *recordingOutboxModel) Enqueue(to, subject, textBody, htmlBody string) error {
+	m.enqueued = append(m.enqueued, &models.Email{To: to, Subject: subject, TextBody: textBody, HTMLBody: htmlBody})
+	return nil
+}
+
+func (m *recordingOutboxModel) Due(limit int) ([]*models.Email, error) {
+	return m.due, nil
+}
+
+func (m *recordingOutboxModel) MarkSent(id int) error {
+	m.sent = append(m.sent, id)
+	return nil
+}
+
+func (m *recordingOutboxModel) MarkFailed(id int, message string, next time.Time, giveUp bool) error {
+	m.failed[id] = mailResult{message: message, next: next, giveUp: giveUp}
+	return nil
+}
+
+func newRecordingOutboxModel(due ...*models.Email) *recordingOutboxModel {
+	return &recordingOutboxModel{due: due, failed: make(map[int]mailResult)}
+}
+
+func newTestMailer(t *testing.T) (*mailer.SMTP, *mailertest.Server) {
+	srv, err := mailertest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+
+	m, err := mailer.NewSMTP(srv.Host, srv.Port, "", "", "Snippetbox <no-reply@snippetbox.test>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return m, srv
+}
+
+func TestMailDispatcherDeliver(t *testing.T) {
+	smtp, srv := newTestMailer(t)
+
+	outbox := newRecordingOutboxModel(
+		&models.Email{ID: 1, To: "bob@example.com", Subject: "Hello", TextBody: "Hi Bob"},
+		&models.Email{ID: 2, To: "not an address", Subject: "Hello", TextBody: "Hi"},
+	)
+
+	err := newMailDispatcher(outbox, smtp).Deliver()
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(outbox.sent), 1)
+	assert.Equal(t, outbox.sent[0], 1)
+
+	received := srv.Messages()
+	assert.Equal(t, len(received), 1)
+	msg, err := mail.ReadMessage(bytes.NewReader(received[0].Data))
+	assert.NilError(t, err)
+	assert.Equal(t, msg.Header.Get("Subject"), "Hello")
+
+	result := outbox.failed[2]
+	assert.StringContains(t, result.message, "invalid recipient")
+	assert.Equal(t, result.giveUp, false)
+	if d := time.Until(result.next); d < 50*time.Second || d > mailBaseDelay {
+		t.Errorf("got next attempt in %s; want about %s", d, mailBaseDelay)
+	}
+
+	// SMTP 서버가 거부하면 다시 시도하고, 마지막 시도에서 실패하면 포기합니다.
+	srv.Reject(true)
+
+	outbox = newRecordingOutboxModel(
+		&models.Email{ID: 3, To: "bob@example.com", Subject: "Hello", TextBody: "Hi", Attempts: 1},
+		&models.Email{ID: 4, To: "bob@example.com", Subject: "Hello", TextBody: "Hi", Attempts: mailMaxAttempts - 1},
+	)
+
+	err = newMailDispatcher(outbox, smtp).Deliver()
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(outbox.sent), 0)
+	assert.StringContains(t, outbox.failed[3].message, "451")
+	assert.Equal(t, outbox.failed[3].giveUp, false)
+	assert.Equal(t, outbox.failed[4].giveUp, true)
+	assert.Equal(t, len(srv.Messages()), 1)
+}
+
+func TestSendEmail(t *testing.T) {
+	app := newTestApplication(t)
+	outbox := newRecordingOutboxModel()
+	app.outbox = outbox
+
+	err := app.sendEmail("bob@example.com", "verify.tmpl", map[string]string{
+		"Name": "<Bob>",
+		"Link": "https://snippets.example.com/user/verify?token=1.2.abc",
+	})
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(outbox.enqueued), 1)
+	email := outbox.enqueued[0]
+	assert.Equal(t, email.To, "bob@example.com")
+	assert.Equal(t, email.Subject, "Verify your Snippetbox email address")
+	assert.StringContains(t, email.TextBody, "Hi <Bob>,")
+	assert.StringContains(t, email.TextBody, "https://snippets.example.com/user/verify?token=1.2.abc")
+	assert.StringContains(t, email.HTMLBody, "Hi &lt;Bob&gt;,")
+
+	err = app.sendEmail("bob@example.com", "missing.tmpl", nil)
+	if err == nil {
+		t.Error("got: nil; want: error for a missing template")
+	}
+}
@@ -1,21 +1,36 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
 	"crypto/tls"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"flag"
+	"fmt"
 	"html/template"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"snippetbox.wook.net/internal/mailer"
 	"snippetbox.wook.net/internal/models"
 
 	"github.com/alexedwards/scs/mysqlstore"
 	"github.com/alexedwards/scs/v2"
 	"github.com/go-playground/form/v4"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/graphql-go/graphql"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // form.Decoder 인스턴스에 대한 포인터를 보유하는 formDecoder 필드를 추가합니다.
@@ -24,14 +39,54 @@ type application struct {
 	infoLog        *log.Logger
 	snippets       models.SnippetModelInterface // Use our new interface type.
 	users          models.UserModelInterface    // Use our new interface type.
+	trending       models.TrendingModelInterface
+	collections    models.CollectionModelInterface
+	tokens         models.TokenModelInterface
+	webhooks       models.WebhookModelInterface
+	revisions      models.RevisionModelInterface
+	collaborators  models.CollaboratorModelInterface
+	outbox         models.OutboxModelInterface
+	passwordResets models.PasswordResetModelInterface
+	twoFactor      models.TwoFactorModelInterface
+	sessions       models.SessionModelInterface
 	templateCache  map[string]*template.Template
 	formDecoder    *form.Decoder
 	sessionManager *scs.SessionManager
+	views          *viewCounter
+	dispatcher     *webhookDispatcher
+	mail           *mailDispatcher
+	hub            *eventHub
+	collab         *collabHub
+	graphql        graphql.Schema
+	baseURL        string
+	embedOrigins   []string
+	pasteLimiter   *rateLimiter
+	mailLimiter    *rateLimiter
+	totpLimiter    *rateLimiter
+	totpEnabled    bool
+	secret         []byte
+	quit           chan struct{}
+	wg             sync.WaitGroup
 }
 
 func main() {
 	addr := flag.String("addr", ":4000", "HTTP 네트워크 주소")
 	dsn := flag.String("dsn", "web:pass@/snippetbox?parseTime=true", "MySQL data source name")
+	viewFlushInterval := flag.Duration("view-flush-interval", 5*time.Second, "조회수를 데이터베이스에 기록하는 주기")
+	baseURL := flag.String("base-url", "", "공유 링크, 피드와 메일에 사용할 외부 URL (예: https://snippets.example.com, 메일을 보내려면 필수)")
+	embedOrigins := flag.String("embed-origins", "", "스니펫을 iframe으로 삽입할 수 있는 출처 목록 (쉼표로 구분)")
+	trendingInterval := flag.Duration("trending-interval", 10*time.Minute, "인기 순위를 다시 계산하는 주기")
+	webhookInterval := flag.Duration("webhook-interval", 10*time.Second, "웹훅 전달 대기열을 확인하는 주기")
+	pasteLimit := flag.Int("paste-limit", 10, "IP 주소마다 1분에 허용하는 /paste 요청 수")
+	grpcAddr := flag.String("grpc-addr", ":4001", "gRPC 네트워크 주소 (비워 두면 gRPC 서버를 시작하지 않음)")
+	smtpHost := flag.String("smtp-host", "localhost", "SMTP 서버 호스트")
+	smtpPort := flag.Int("smtp-port", 25, "SMTP 서버 포트")
+	smtpUsername := flag.String("smtp-username", "", "SMTP 사용자 이름 (비워 두면 인증하지 않음)")
+	smtpPassword := flag.String("smtp-password", "", "SMTP 비밀번호")
+	smtpSender := flag.String("smtp-sender", "Snippetbox <no-reply@snippetbox.wook.net>", "보내는 사람 주소")
+	mailInterval := flag.Duration("mail-interval", 10*time.Second, "보낼 편지함을 확인하는 주기")
+	secret := flag.String("secret", "", "메일로 보내는 링크의 서명에 사용하는 비밀 키 (비워 두면 시작할 때마다 새로 만듦)")
+	totpKey := flag.String("totp-key", "", "2단계 인증 비밀 키를 암호화하는 32바이트 키 (16진수 64자)")
 
 	flag.Parse()
 
@@ -45,35 +100,140 @@ func main() {
 
 	defer db.Close()
 
+	origins, err := parseOrigins(*embedOrigins)
+	if err != nil {
+		errorLog.Fatal(err)
+	}
+
 	templateCache, err := newTemplateCache()
 	if err != nil {
 		errorLog.Fatal(err)
 	}
 
+	// 비밀 키가 없으면 임의의 키를 만듭니다. 이 경우 서버를 다시 시작하면 이미 보낸
+	// 인증 링크는 모두 무효가 됩니다.
+	secretKey := []byte(*secret)
+	if len(secretKey) == 0 {
+		secretKey = make([]byte, 32)
+		_, err = rand.Read(secretKey)
+		if err != nil {
+			errorLog.Fatal(err)
+		}
+		infoLog.Print("-secret 플래그가 없어 임의의 비밀 키를 사용합니다")
+	}
+
+	// 메일에 넣는 링크는 요청의 Host 헤더로 만들 수 없으므로 -base-url 플래그가 없으면
+	// 링크가 담긴 메일을 보내지 않습니다.
+	if *baseURL == "" {
+		errorLog.Print("-base-url 플래그가 없어 링크가 담긴 메일을 보내지 않습니다")
+	}
+
+	// 2단계 인증 비밀 키는 데이터베이스에 AES-256-GCM으로 암호화하여 저장합니다. 키가 바뀌면
+	// 이미 등록한 비밀 키를 읽을 수 없으므로 임의의 키를 만들지 않고 플래그로 받습니다.
+	// 키는 openssl rand -hex 32 등으로 만들 수 있습니다. 플래그가 없으면 2단계 인증 등록만
+	// 막지만, 이미 2단계 인증을 켠 사용자가 있으면 그 사용자가 로그인할 수 없으므로 시작하지
+	// 않습니다.
+	twoFactor := &models.TwoFactorModel{DB: db}
+	if *totpKey != "" {
+		twoFactor.Key, err = hex.DecodeString(*totpKey)
+		if err != nil || len(twoFactor.Key) != 32 {
+			errorLog.Fatal("-totp-key 플래그는 16진수 64자여야 합니다")
+		}
+	} else {
+		enabled, err := twoFactor.AnyEnabled()
+		if err != nil {
+			errorLog.Fatal(err)
+		}
+		if enabled {
+			errorLog.Fatal("2단계 인증을 켠 사용자가 있으므로 -totp-key 플래그가 필요합니다")
+		}
+		errorLog.Print("-totp-key 플래그가 없어 2단계 인증 등록을 막습니다")
+	}
+
 	formDecoder := form.NewDecoder()
 
-	// scs.New() 함수를 사용하여 새 세션 관리자를 초기화합니다.
-	// 그런 다음 MySQL 데이터베이스를 세션 저장소로 사용하도록 구성하고
-	// 수명을 12시간으로 설정합니다(세션이 처음 생성된 후 12시간이 지나면 자동으로 만료되도록).
+	smtp, err := mailer.NewSMTP(*smtpHost, *smtpPort, *smtpUsername, *smtpPassword, *smtpSender)
+	if err != nil {
+		errorLog.Fatal(err)
+	}
 
+	// scs.New() 함수를 사용하여 새 세션 관리자를 초기화합니다.
+	// 그런 다음 MySQL 데이터베이스를 세션 저장소로 사용하도록 구성합니다.
+	// 세션 쿠키는 기본적으로 브라우저를 닫으면 사라지며, 로그인할 때 로그인 유지를 선택한
+	// 경우에만 RememberMe()로 영구 쿠키를 보냅니다. 로그인 유지를 선택한 세션은 30일 동안
+	// 유지되지만 7일 동안 사용하지 않으면 만료됩니다. 로그인 유지를 선택하지 않은 로그인은
+	// loginLifetime이 지나면 로그아웃됩니다.
 	sessionManager := scs.New()
 	sessionManager.Store = mysqlstore.New(db)
-	sessionManager.Lifetime = 12 * time.Hour
+	sessionManager.Lifetime = 30 * 24 * time.Hour
+	sessionManager.IdleTimeout = 7 * 24 * time.Hour
+	sessionManager.Cookie.Persist = false
 	// 세션 쿠키에 보안 속성이 설정되어 있는지 확인합니다.
 	// 이 속성을 설정하면 쿠키가 사용자의 웹 브라우저에서만 전송됩니다.
 	// 브라우저에서만 전송되며, 보안되지 않은 HTTP 연결을 통해서는 전송되지 않습니다.
 	sessionManager.Cookie.Secure = true
 
+	snippets := &models.SnippetModel{DB: db}
+	webhooks := &models.WebhookModel{DB: db}
+	outbox := &models.OutboxModel{DB: db}
+
 	// 그리고 애플리케이션 종속성에 sessionManager를 추가합니다.
 	app := &application{
 		errorLog:       errorLog,
 		infoLog:        infoLog,
-		snippets:       &models.SnippetModel{DB: db},
+		snippets:       snippets,
 		users:          &models.UserModel{DB: db},
+		trending:       &models.TrendingModel{DB: db},
+		collections:    &models.CollectionModel{DB: db},
+		tokens:         &models.TokenModel{DB: db},
+		webhooks:       webhooks,
+		revisions:      &models.RevisionModel{DB: db},
+		collaborators:  &models.CollaboratorModel{DB: db},
+		outbox:         outbox,
+		passwordResets: &models.PasswordResetModel{DB: db},
+		twoFactor:      twoFactor,
+		totpEnabled:    twoFactor.Key != nil,
+		sessions:       &models.SessionModel{DB: db},
 		templateCache:  templateCache,
 		formDecoder:    formDecoder,
 		sessionManager: sessionManager,
+		views:          newViewCounter(snippets),
+		dispatcher:     newWebhookDispatcher(webhooks),
+		mail:           newMailDispatcher(outbox, smtp),
+		hub:            newEventHub(),
+		collab:         newCollabHub(),
+		baseURL:        strings.TrimSuffix(*baseURL, "/"),
+		embedOrigins:   origins,
+		pasteLimiter:   newRateLimiter(*pasteLimit, time.Minute),
+		mailLimiter:    newRateLimiter(5, time.Minute),
+		totpLimiter:    newRateLimiter(twoFactorUserLimit, twoFactorUserWindow),
+		secret:         secretKey,
+		quit:           make(chan struct{}),
+	}
+
+	app.graphql, err = app.newGraphQLSchema()
+	if err != nil {
+		errorLog.Fatal(err)
+	}
+
+	// 조회수 플러셔와 인기 순위 계산을 백그라운드 고루틴에서 시작합니다.
+	// 인기 순위는 첫 주기를 기다리지 않도록 시작할 때 한 번 계산해 둡니다.
+	app.runPeriodically(*viewFlushInterval, app.views.Flush)
+
+	err = app.trending.Refresh()
+	if err != nil {
+		errorLog.Print(err)
 	}
+	app.runPeriodically(*trendingInterval, app.trending.Refresh)
+	app.runPeriodically(time.Minute, app.pasteLimiter.Prune)
+	app.runPeriodically(time.Minute, app.mailLimiter.Prune)
+	app.runPeriodically(time.Minute, app.totpLimiter.Prune)
+
+	// 만료 이벤트는 1분마다 대기열에 넣고, 대기열은 -webhook-interval마다 처리합니다.
+	app.runPeriodically(time.Minute, app.enqueueExpiredSnippets)
+	app.runPeriodically(*webhookInterval, app.dispatcher.Deliver)
+	app.runPeriodically(*mailInterval, app.mail.Deliver)
+
 	// 서버에서 사용할 기본값이 아닌 TLS 설정을 저장하기 위해 tls.Config 구조체를 초기화합니다.
 	// 이 경우 변경하는 것은 커브 기본 설정 값뿐이므로 어셈블리 구현이 있는 타원형 커브만
 	// 사용됩니다.
@@ -92,11 +252,101 @@ func main() {
 		WriteTimeout: 10 * time.Second,
 	}
 
+	// /events 스트림은 클라이언트가 끊을 때까지 끝나지 않으므로 종료가 시작되면 허브를 닫아
+	// 스트리밍 중인 요청이 바로 끝나게 합니다.
+	srv.RegisterOnShutdown(app.hub.Close)
+
+	// gRPC 서버는 HTTPS 서버와 같은 인증서를 사용하여 별도의 리스너에서 실행합니다.
+	var grpcServer *grpc.Server
+
+	if *grpcAddr != "" {
+		creds, err := credentials.NewServerTLSFromFile("./tls/cert.pem", "./tls/key.pem")
+		if err != nil {
+			errorLog.Fatal(err)
+		}
+
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			errorLog.Fatal(err)
+		}
+
+		grpcServer = app.newGRPCServer(creds)
+
+		go func() {
+			infoLog.Printf("%s에서 gRPC 서버 시작 중", *grpcAddr)
+			err := grpcServer.Serve(lis)
+			if err != nil {
+				errorLog.Print(err)
+			}
+		}()
+	}
+
+	// SIGINT 또는 SIGTERM 신호를 받으면 진행 중인 요청이 끝날 때까지 기다린 후
+	// 서버를 종료합니다. Shutdown()의 결과는 shutdownError 채널로 전달됩니다.
+	shutdownError := make(chan error)
+
+	go func() {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		s := <-quit
+
+		infoLog.Printf("%s 신호를 받아 서버 종료 중", s)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+
+		// gRPC 서버도 진행 중인 RPC가 끝날 때까지 기다린 후 종료합니다. HTTP 서버와 함께
+		// 종료하도록 별도의 고루틴에서 GracefulStop()을 호출하고, 같은 제한 시간이 지나면
+		// Stop()으로 남은 RPC를 끊습니다.
+		grpcStopped := make(chan struct{})
+		if grpcServer != nil {
+			go func() {
+				grpcServer.GracefulStop()
+				close(grpcStopped)
+			}()
+		}
+
+		err := srv.Shutdown(ctx)
+
+		if grpcServer != nil {
+			select {
+			case <-grpcStopped:
+			case <-ctx.Done():
+				grpcServer.Stop()
+				<-grpcStopped
+			}
+		}
+
+		shutdownError <- err
+	}()
+
 	infoLog.Printf("%s에서 서버 시작 중", *addr)
 	// ListenAndServeTLS() 메서드를 사용하여 HTTPS 서버를 시작합니다.
 	// 두 개의 매개변수로 TLS 인증서와 해당 개인 키의 경로를 전달합니다.
+	// Shutdown()이 호출되면 즉시 http.ErrServerClosed를 반환하므로 이 경우는 오류로 취급하지 않습니다.
 	err = srv.ListenAndServeTLS("./tls/cert.pem", "./tls/key.pem")
-	errorLog.Fatal(err)
+	if !errors.Is(err, http.ErrServerClosed) {
+		errorLog.Fatal(err)
+	}
+
+	shutdownErr := <-shutdownError
+
+	// Shutdown()이 제한 시간을 넘겨 실패했더라도 새 요청은 더 이상 받지 않으므로, 백그라운드
+	// 작업이 모두 끝나기를 기다린 후 대기 중인 조회수를 마지막으로 기록합니다. 여기서 Fatal로
+	// 끝내면 이 단계를 건너뛰게 되므로 오류는 기록만 합니다.
+	close(app.quit)
+	app.wg.Wait()
+
+	err = app.views.Flush()
+	if err != nil {
+		errorLog.Print(err)
+	}
+
+	if shutdownErr != nil {
+		errorLog.Print(shutdownErr)
+	}
+
+	infoLog.Print("서버 종료")
 }
 
 // openDB() 함수는 sql.Open()을 래핑하고
@@ -111,3 +361,25 @@ func openDB(dsn string) (*sql.DB, error) {
 	}
 	return db, nil
 }
+
+// parseOrigins는 쉼표로 구분된 출처 목록을 검사하여 CSP에 그대로 넣을 수 있는
+// "scheme://host[:port]" 형태의 슬라이스로 반환합니다.
+func parseOrigins(s string) ([]string, error) {
+	origins := []string{}
+
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		u, err := url.Parse(field)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return nil, fmt.Errorf("invalid embed origin %q", field)
+		}
+
+		origins = append(origins, u.Scheme+"://"+u.Host)
+	}
+
+	return origins, nil
+}
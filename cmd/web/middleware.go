@@ -2,16 +2,21 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/justinas/nosurf"
+	"snippetbox.wook.net/internal/models"
 )
 
+// contentSecurityPolicy는 모든 응답에 적용되는 CSP에서 frame-ancestors 지시문을 뺀 부분입니다.
+const contentSecurityPolicy = "default-src 'self'; style-src 'self' fonts.googleapis.com; font-src fonts.gstatic.com"
+
 func secureHeaders(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Security-Policy",
-			"default-src 'self'; style-src 'self' fonts.googleapis.com; font-src fonts.gstatic.com")
+		w.Header().Set("Content-Security-Policy", contentSecurityPolicy+"; frame-ancestors 'none'")
 		w.Header().Set("Referrer-Policy", "origin-when-cross-origin")
 		w.Header().Set("X-Content-Type-Options", "nosniff")
 		w.Header().Set("X-Frame-Options", "deny")
@@ -20,6 +25,19 @@ func secureHeaders(next http.Handler) http.Handler {
 	})
 }
 
+// allowEmbedding은 secureHeaders가 설정한 프레임 금지 헤더를 완화하여
+// 자기 자신과 -embed-origins 플래그로 허용한 출처에서만 iframe으로 삽입할 수 있게 합니다.
+// secureHeaders보다 안쪽에서 실행되어야 합니다.
+func (app *application) allowEmbedding(next http.Handler) http.Handler {
+	frameAncestors := strings.Join(append([]string{"'self'"}, app.embedOrigins...), " ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Del("X-Frame-Options")
+		w.Header().Set("Content-Security-Policy", contentSecurityPolicy+"; frame-ancestors "+frameAncestors)
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (app *application) logRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		app.infoLog.Printf("%s - %s %s %s", r.RemoteAddr, r.Proto, r.Method, r.URL.RequestURI())
@@ -37,7 +55,7 @@ func (app *application) recoverPanic(next http.Handler) http.Handler {
 				w.Header().Set("Connection", "close")
 				// 앱 서버 오류 헬퍼 메서드를 호출하여 500
 				// 내부 서버 응답을 반환합니다.
-				app.serverError(w, fmt.Errorf("%s", err))
+				app.serverError(w, r, fmt.Errorf("%s", err))
 			}
 		}()
 
@@ -77,19 +95,111 @@ func (app *application) authenticate(next http.Handler) http.Handler {
 			next.ServeHTTP(w, r)
 			return
 		}
+		// 로그인 유지를 선택하지 않은 로그인이 유지 시간을 넘겼으면 로그아웃시킵니다. 로그인
+		// 기록이 없는 세션도 로그아웃시킵니다. 철회할 때 세션 저장소의 항목을 지우더라도 그때
+		// 처리 중이던 요청이 끝나면서 세션을 다시 저장할 수 있고, 로그인 기록을 만들기 전에
+		// 로그인한 세션은 목록에 없어 철회할 수 없기 때문입니다.
+		active, err := app.activeUserSession(r.Context(), id)
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+		if !active {
+			err = app.sessionManager.RenewToken(r.Context())
+			if err != nil {
+				app.serverError(w, r, err)
+				return
+			}
+
+			err = app.logoutSession(r.Context())
+			if err != nil {
+				app.serverError(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+			return
+		}
 		// 그렇지 않으면 해당 ID를 가진 사용자가 데이터베이스에 존재하는지 확인합니다.
 		exists, err := app.users.Exists(id)
 		if err != nil {
-			app.serverError(w, err)
+			app.serverError(w, r, err)
 			return
 		}
 		// 일치하는 사용자가 발견되면 해당 요청이
 		// 데이터베이스에 존재하는 인증된 사용자로부터 온 요청임을 알 수 있습니다.
 		// 요청의 새 복사본을 생성하고(요청 컨텍스트에서 isAuthenticatedContextKey 값이 true인 요청) 이를 r에 할당합니다.
+		// 핸들러에서 소유권을 확인할 수 있도록 사용자 ID도 함께 저장합니다.
 		if exists {
 			ctx := context.WithValue(r.Context(), isAuthenticatedContextKey, true)
+			ctx = context.WithValue(ctx, authenticatedUserIDContextKey, id)
 			r = r.WithContext(ctx)
+
+			app.touchUserSession(r)
 		}
 		next.ServeHTTP(w, r)
 	})
 }
+
+// authenticateToken은 "Authorization: Bearer <token>" 헤더로 전달된 개인 액세스 토큰을 확인합니다.
+// 토큰이 유효하면 authenticate 미들웨어와 같은 방식으로 요청 컨텍스트에 인증된 사용자를 저장하고,
+// 권한 범위 확인을 위해 토큰도 함께 저장합니다. 헤더가 없으면 아무 일도 하지 않으므로
+// 세션 쿠키로 인증된 요청은 그대로 통과합니다.
+func (app *application) authenticateToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Authorization")
+
+		authorizationHeader := r.Header.Get("Authorization")
+		if authorizationHeader == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		scheme, plaintext, ok := strings.Cut(authorizationHeader, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			app.invalidTokenResponse(w)
+			return
+		}
+
+		token, err := app.tokens.Authenticate(strings.TrimSpace(plaintext))
+		if err != nil {
+			if errors.Is(err, models.ErrInvalidCredentials) {
+				app.invalidTokenResponse(w)
+			} else {
+				app.serverError(w, r, err)
+			}
+			return
+		}
+
+		exists, err := app.users.Exists(token.UserID)
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+		if !exists {
+			app.invalidTokenResponse(w)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), isAuthenticatedContextKey, true)
+		ctx = context.WithValue(ctx, authenticatedUserIDContextKey, token.UserID)
+		ctx = context.WithValue(ctx, tokenContextKey, token)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requireScope는 토큰으로 인증된 요청에 주어진 권한 범위가 없으면 403으로 응답합니다.
+// 세션 쿠키로 인증된 요청은 사용자 본인의 요청이므로 모든 권한을 가진 것으로 취급합니다.
+func (app *application) requireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := r.Context().Value(tokenContextKey).(*models.Token)
+			if ok && !token.HasScope(scope) {
+				app.apiErrorResponse(w, http.StatusForbidden, fmt.Sprintf("this token does not have the %s scope", scope))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -29,7 +29,7 @@ func TestSecureHeaders(t *testing.T) {
 
 	rs := rr.Result()
 
-	expectedValue := "default-src 'self'; style-src 'self' fonts.googleapis.com; font-src fonts.gstatic.com"
+	expectedValue := "default-src 'self'; style-src 'self' fonts.googleapis.com; font-src fonts.gstatic.com; frame-ancestors 'none'"
 	assert.Equal(t, rs.Header.Get("Content-Security-Policy"), expectedValue)
 
 	// Check that the middleware has correctly set the Referrer-Policy
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"snippetbox.wook.net/internal/assert"
+	"snippetbox.wook.net/internal/models"
+	"snippetbox.wook.net/ui"
+)
+
+// openAPIDocument는 테스트에서 비교하는 데 필요한 OpenAPI 문서의 일부입니다.
+type openAPIDocument struct {
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Required   []string                   `json:"required"`
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+func loadOpenAPIDocument(t *testing.T) openAPIDocument {
+	b, err := fs.ReadFile(ui.Files, "api/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc openAPIDocument
+
+	err = json.Unmarshal(b, &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return doc
+}
+
+var (
+	routeParamRX = regexp.MustCompile(`:([a-z_]+)`)
+	specParamRX  = regexp.MustCompile(`\{[a-z_]+\}`)
+)
+
+// TestOpenAPIRoutes는 apiRoutes() 목록과 OpenAPI 문서의 경로가 정확히 일치하는지, 그리고
+// 문서의 모든 경로가 실제 라우터에서 처리되는지 확인합니다.
+//
+// httprouter는 등록된 경로를 나열할 수 없으므로, apiRoutes()를 거치지 않고 routes.go에서
+// /api/v1 아래에 직접 등록한 경로는 이 테스트가 찾지 못합니다. JSON API 경로는 반드시
+// apiRoutes()에 추가해야 합니다. /graphql은 OpenAPI 문서의 범위가 아닙니다.
+func TestOpenAPIRoutes(t *testing.T) {
+	app := newTestApplication(t)
+	doc := loadOpenAPIDocument(t)
+
+	if len(doc.Servers) != 1 {
+		t.Fatalf("expected exactly one server, got %d", len(doc.Servers))
+	}
+	prefix := doc.Servers[0].URL
+
+	var inSpec []string
+	for path, item := range doc.Paths {
+		for method := range item {
+			if method == "parameters" {
+				continue
+			}
+			inSpec = append(inSpec, strings.ToUpper(method)+" "+prefix+path)
+		}
+	}
+
+	var inRouter []string
+	for _, route := range app.apiRoutes() {
+		// httprouter의 :id 형식을 OpenAPI의 {id} 형식으로 바꿉니다.
+		inRouter = append(inRouter, route.method+" "+routeParamRX.ReplaceAllString(route.path, "{$1}"))
+	}
+
+	sort.Strings(inSpec)
+	sort.Strings(inRouter)
+
+	assert.Equal(t, strings.Join(inSpec, "\n"), strings.Join(inRouter, "\n"))
+
+	router := app.router()
+	for _, route := range inSpec {
+		method, path, _ := strings.Cut(route, " ")
+		// 경로 매개변수에는 아무 값이나 넣어 봅니다.
+		path = specParamRX.ReplaceAllString(path, "1")
+
+		handle, _, _ := router.Lookup(method, path)
+		if handle == nil {
+			t.Errorf("%s is in the OpenAPI document but not in the router", route)
+		}
+	}
+}
+
+// TestOpenAPISnippetSchema는 Snippet 스키마가 models.Snippet의 JSON 필드와 일치하는지 확인합니다.
+func TestOpenAPISnippetSchema(t *testing.T) {
+	doc := loadOpenAPIDocument(t)
+
+	schema, ok := doc.Components.Schemas["Snippet"]
+	if !ok {
+		t.Fatal("Snippet schema not found")
+	}
+
+	var fields []string
+	typ := reflect.TypeOf(models.Snippet{})
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+
+	var properties []string
+	for name := range schema.Properties {
+		properties = append(properties, name)
+	}
+
+	sort.Strings(fields)
+	sort.Strings(properties)
+	required := append([]string{}, schema.Required...)
+	sort.Strings(required)
+
+	assert.Equal(t, strings.Join(properties, ","), strings.Join(fields, ","))
+	assert.Equal(t, strings.Join(required, ","), strings.Join(fields, ","))
+}
+
+func TestOpenAPIServed(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, header, body := ts.get(t, "/api/v1/openapi.json")
+
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, header.Get("Content-Type"), "application/json")
+	assert.StringContains(t, body, `"openapi": "3.0.3"`)
+}
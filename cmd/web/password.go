@@ -0,0 +1,180 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"snippetbox.wook.net/internal/models"
+	"snippetbox.wook.net/internal/validator"
+)
+
+// passwordResetTTL은 비밀번호 재설정 링크의 유효 기간입니다.
+const passwordResetTTL = time.Hour
+
+type passwordForgotForm struct {
+	Email               string `form:"email"`
+	validator.Validator `form:"-"`
+}
+
+type passwordResetForm struct {
+	Token               string `form:"token"`
+	Password            string `form:"password"`
+	validator.Validator `form:"-"`
+}
+
+func (app *application) passwordForgot(w http.ResponseWriter, r *http.Request) {
+	data := app.newTemplateData(r)
+	data.Form = passwordForgotForm{}
+	app.render(w, r, http.StatusOK, "forgot.go.tpl", data)
+}
+
+func (app *application) passwordForgotPost(w http.ResponseWriter, r *http.Request) {
+	var form passwordForgotForm
+
+	err := app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Email), "email", "This field cannot be blank")
+	form.CheckField(validator.Matches(form.Email, validator.EmailRX), "email", "This field must be a valid email address")
+
+	if !form.Valid() {
+		data := app.newTemplateData(r)
+		data.Form = form
+		app.render(w, r, http.StatusUnprocessableEntity, "forgot.go.tpl", data)
+		return
+	}
+
+	// 인증 메일 재전송과 같이 계정이 없는 주소에도 같은 응답을 보냅니다.
+	user, err := app.users.GetByEmail(form.Email)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.serverError(w, r, err)
+		return
+	}
+	if err == nil {
+		err = app.sendPasswordReset(user)
+		if errors.Is(err, errNoBaseURL) {
+			// 오류 응답을 보내면 계정이 있는 주소인지 알려 주게 되므로 기록만 합니다.
+			app.errorLog.Print(err)
+		} else if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "If that address belongs to an account, we've sent a password reset link to it.")
+
+	http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+}
+
+// sendPasswordReset은 새 재설정 토큰을 만들어 링크를 메일로 보냅니다. -base-url 플래그가
+// 없으면 토큰을 만들지 않고 errNoBaseURL을 반환합니다.
+func (app *application) sendPasswordReset(user *models.User) error {
+	link, err := app.mailURL("/user/password/reset?token=")
+	if err != nil {
+		return err
+	}
+
+	token, err := app.passwordResets.Insert(user.ID, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return app.sendEmail(user.Email, "password_reset.tmpl", map[string]string{
+		"Name": user.Name,
+		"Link": link + url.QueryEscape(token),
+	})
+}
+
+// renderInvalidReset은 재설정 링크가 잘못되었거나 만료되었을 때 새 링크를 요청하는 양식을 보여 줍니다.
+func (app *application) renderInvalidReset(w http.ResponseWriter, r *http.Request) {
+	form := passwordForgotForm{}
+	form.AddNonFieldError("This password reset link is invalid or has expired. Enter your email address to get a new one.")
+
+	data := app.newTemplateData(r)
+	data.Form = form
+	app.render(w, r, http.StatusBadRequest, "forgot.go.tpl", data)
+}
+
+func (app *application) passwordReset(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+
+	_, err := app.passwordResets.Get(token)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.renderInvalidReset(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Form = passwordResetForm{Token: token}
+	app.render(w, r, http.StatusOK, "reset.go.tpl", data)
+}
+
+func (app *application) passwordResetPost(w http.ResponseWriter, r *http.Request) {
+	var form passwordResetForm
+
+	err := app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Password), "password", "This field cannot be blank")
+	form.CheckField(validator.MinChars(form.Password, 8), "password", "This field must be at least 8 characters long")
+
+	if !form.Valid() {
+		data := app.newTemplateData(r)
+		data.Form = form
+		app.render(w, r, http.StatusUnprocessableEntity, "reset.go.tpl", data)
+		return
+	}
+
+	// 토큰은 한 번만 쓸 수 있으므로 비밀번호를 바꾸기 전에 먼저 소비합니다.
+	userID, err := app.passwordResets.Consume(form.Token)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.renderInvalidReset(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return
+	}
+
+	err = app.users.UpdatePassword(userID, form.Password)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	// 예전 비밀번호로 로그인한 세션은 모두 끝냅니다. 이 요청의 세션은 응답을 보낼 때 다시
+	// 저장되므로 로그아웃과 같이 토큰을 바꾸고 로그인 정보를 지웁니다.
+	err = app.destroyUserSessions(r.Context(), userID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	err = app.sessionManager.RenewToken(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	err = app.logoutSession(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Your password has been reset. Please log in with your new password.")
+
+	http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"snippetbox.wook.net/internal/assert"
+)
+
+func TestPasswordForgot(t *testing.T) {
+	app := newTestApplication(t)
+	outbox := newRecordingOutboxModel()
+	app.outbox = outbox
+	app.baseURL = "https://snippets.example.com"
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, _, body := ts.get(t, "/user/password/forgot")
+	assert.Equal(t, code, http.StatusOK)
+	csrfToken := extractCSRFToken(t, body)
+
+	tests := []struct {
+		name     string
+		email    string
+		wantCode int
+	}{
+		{"Known account", "alice@example.com", http.StatusSeeOther},
+		{"Unknown account", "nobody@example.com", http.StatusSeeOther},
+		{"Invalid email", "alice@example.", http.StatusUnprocessableEntity},
+		{"Empty email", "", http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("email", tt.email)
+			form.Add("csrf_token", csrfToken)
+
+			code, _, _ := ts.postForm(t, "/user/password/forgot", form)
+			assert.Equal(t, code, tt.wantCode)
+		})
+	}
+
+	assert.Equal(t, len(outbox.enqueued), 1)
+	assert.Equal(t, outbox.enqueued[0].To, "alice@example.com")
+	assert.Equal(t, outbox.enqueued[0].Subject, "Reset your Snippetbox password")
+	assert.StringContains(t, outbox.enqueued[0].TextBody, "https://snippets.example.com/user/password/reset?token=valid-reset-token")
+}
+
+// TestPasswordForgotHost는 Host 헤더를 바꾼 요청으로 다른 사람의 재설정 링크가 공격자의
+// 서버를 가리키게 할 수 없는지 확인합니다.
+func TestPasswordForgotHost(t *testing.T) {
+	tests := []struct {
+		name      string
+		baseURL   string
+		wantLink  string
+		wantEmail bool
+	}{
+		{"Base URL", "https://snippets.example.com", "https://snippets.example.com/user/password/reset?token=", true},
+		{"No base URL", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			outbox := newRecordingOutboxModel()
+			app.outbox = outbox
+			app.baseURL = tt.baseURL
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			_, _, body := ts.get(t, "/user/password/forgot")
+			csrfToken := extractCSRFToken(t, body)
+
+			// 테스트 서버의 인증서를 그대로 검증할 수 있도록 TLS 서버 이름은 고정하고
+			// Host 헤더만 바꿉니다.
+			transport := ts.Client().Transport.(*http.Transport).Clone()
+			transport.TLSClientConfig.ServerName = "example.com"
+			client := &http.Client{
+				Transport:     transport,
+				CheckRedirect: ts.Client().CheckRedirect,
+			}
+
+			form := url.Values{}
+			form.Add("email", "alice@example.com")
+			form.Add("csrf_token", csrfToken)
+
+			req, err := http.NewRequest(http.MethodPost, ts.URL+"/user/password/forgot", strings.NewReader(form.Encode()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Host = "evil.example"
+			for _, cookie := range ts.Client().Jar.Cookies(req.URL) {
+				req.AddCookie(cookie)
+			}
+
+			rs, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rs.Body.Close()
+			assert.Equal(t, rs.StatusCode, http.StatusSeeOther)
+
+			if !tt.wantEmail {
+				assert.Equal(t, len(outbox.enqueued), 0)
+				return
+			}
+
+			assert.Equal(t, len(outbox.enqueued), 1)
+			assert.StringContains(t, outbox.enqueued[0].TextBody, tt.wantLink)
+			assert.Equal(t, strings.Contains(outbox.enqueued[0].TextBody, "evil.example"), false)
+			assert.Equal(t, strings.Contains(outbox.enqueued[0].HTMLBody, "evil.example"), false)
+		})
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	// 다른 브라우저에서 로그인한 세션입니다. 두 테스트 서버는 같은 세션 저장소를 사용합니다.
+	other := newTestServer(t, app.routes())
+	defer other.Close()
+	other.login(t)
+
+	code, _, _ := other.get(t, "/snippet/create")
+	assert.Equal(t, code, http.StatusOK)
+
+	ts.login(t)
+
+	code, _, body := ts.get(t, "/user/password/reset?token=wrong-token")
+	assert.Equal(t, code, http.StatusBadRequest)
+	assert.StringContains(t, body, "This password reset link is invalid or has expired.")
+
+	code, _, body = ts.get(t, "/user/password/reset?token=valid-reset-token")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<input type='hidden' name='token' value='valid-reset-token'>")
+	csrfToken := extractCSRFToken(t, body)
+
+	tests := []struct {
+		name     string
+		token    string
+		password string
+		wantCode int
+	}{
+		{"Short password", "valid-reset-token", "pa$$", http.StatusUnprocessableEntity},
+		{"Invalid token", "wrong-token", "newPa$$word", http.StatusBadRequest},
+		{"Valid submission", "valid-reset-token", "newPa$$word", http.StatusSeeOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("token", tt.token)
+			form.Add("password", tt.password)
+			form.Add("csrf_token", csrfToken)
+
+			code, _, _ := ts.postForm(t, "/user/password/reset", form)
+			assert.Equal(t, code, tt.wantCode)
+		})
+	}
+
+	// 재설정한 뒤에는 alice로 로그인한 세션이 하나도 남아 있지 않아야 합니다.
+	sessions := 0
+	err := app.sessionManager.Iterate(context.Background(), func(ctx context.Context) error {
+		if app.sessionManager.GetInt(ctx, "authenticatedUserID") == 1 {
+			sessions++
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, sessions, 0)
+
+	for _, client := range []*testServer{ts, other} {
+		code, header, _ := client.get(t, "/snippet/create")
+		assert.Equal(t, code, http.StatusSeeOther)
+		assert.Equal(t, header.Get("Location"), "/user/login")
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"snippetbox.wook.net/internal/validator"
+)
+
+// maxPasteSize는 붙여넣기 내용의 최대 크기입니다. snippets.content 열의 TEXT 형식이
+// 저장할 수 있는 크기와 같습니다.
+const maxPasteSize = 65_535
+
+// maxPasteBodySize는 multipart 경계와 헤더를 위한 여유분을 포함한 요청 본문의 최대 크기입니다.
+const maxPasteBodySize = maxPasteSize + 16_384
+
+// paste는 sprunge나 ix.io처럼 curl로 사용할 수 있는 익명 붙여넣기 핸들러입니다.
+//
+//	kubectl logs mypod | curl --data-binary @- https://snippets.example.com/paste
+//	curl -F 'f=<-' https://snippets.example.com/paste < notes.txt
+//
+// multipart 본문이면 첫 번째 부분을, 그렇지 않으면 본문 전체를 내용으로 사용하고
+// 새 스니펫의 URL을 일반 텍스트로 응답합니다. 제목과 만료 기간은 ?title=, ?expires=
+// 쿼리 문자열로 정할 수 있습니다. 세션이 없으므로 CSRF 토큰을 요구하지 않습니다.
+func (app *application) paste(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxPasteBodySize)
+
+	content, err := readPaste(r)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			app.clientError(w, r, http.StatusRequestEntityTooLarge)
+		} else {
+			app.clientError(w, r, http.StatusBadRequest)
+		}
+		return
+	}
+
+	if len(content) > maxPasteSize {
+		app.clientError(w, r, http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	title := r.URL.Query().Get("title")
+	if title == "" {
+		title = pasteTitle(content)
+	}
+
+	expires := 7
+	if s := r.URL.Query().Get("expires"); s != "" {
+		expires, err = strconv.Atoi(s)
+		if err != nil {
+			app.clientError(w, r, http.StatusBadRequest)
+			return
+		}
+	}
+
+	var v validator.Validator
+
+	checkSnippet(&v, title, content, nil)
+	v.CheckField(utf8.ValidString(content), "content", "This field must be UTF-8 text")
+	v.CheckField(validator.PermittedValue(expires, 1, 7, 365), "expires", "This field must equal 1, 7 or 365")
+
+	if !v.Valid() {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		for _, field := range []string{"title", "content", "expires"} {
+			if msg, ok := v.FieldErrors[field]; ok {
+				fmt.Fprintf(w, "%s: %s\n", field, msg)
+			}
+		}
+		return
+	}
+
+	id, err := app.snippets.Insert(0, title, content, expires, []string{})
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.publishSnippet(insertedSnippet(id, 0, title, content, []string{}, expires))
+
+	url := app.absoluteURL(r, fmt.Sprintf("/snippet/view/%d", id))
+
+	w.Header().Set("Location", url)
+	app.writeText(w, http.StatusCreated, []byte(url+"\n"))
+}
+
+// readPaste는 요청 본문에서 붙여넣을 내용을 읽습니다.
+func readPaste(r *http.Request) (string, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		b, err := io.ReadAll(r.Body)
+		return string(b), err
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return "", err
+	}
+
+	part, err := mr.NextPart()
+	if err != nil {
+		return "", err
+	}
+	defer part.Close()
+
+	b, err := io.ReadAll(part)
+	return string(b), err
+}
+
+// pasteTitle은 내용의 첫 번째 비어 있지 않은 줄을 제목으로 사용합니다.
+// 제목 길이 제한에 맞도록 100자를 넘으면 잘라 냅니다.
+func pasteTitle(content string) string {
+	title := "Untitled paste"
+
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			title = line
+			break
+		}
+	}
+
+	if utf8.RuneCountInString(title) > 100 {
+		title = string([]rune(title)[:99]) + "…"
+	}
+
+	return title
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"snippetbox.wook.net/internal/assert"
+)
+
+func TestPaste(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	var multipartBody bytes.Buffer
+	mw := multipart.NewWriter(&multipartBody)
+	mw.WriteField("f", "from a form\n")
+	mw.Close()
+
+	tests := []struct {
+		name        string
+		urlPath     string
+		contentType string
+		body        string
+		wantCode    int
+		wantBody    string
+	}{
+		{
+			name:        "Raw body",
+			urlPath:     "/paste",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "pod started\nready\n",
+			wantCode:    http.StatusCreated,
+			wantBody:    "/snippet/view/2",
+		},
+		{
+			name:        "Multipart",
+			urlPath:     "/paste",
+			contentType: mw.FormDataContentType(),
+			body:        multipartBody.String(),
+			wantCode:    http.StatusCreated,
+			wantBody:    "/snippet/view/2",
+		},
+		{
+			name:     "Empty body",
+			urlPath:  "/paste",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "content: This field cannot be blank",
+		},
+		{
+			name:     "Invalid expires",
+			urlPath:  "/paste?expires=30",
+			body:     "hello",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "expires: This field must equal 1, 7 or 365",
+		},
+		{
+			name:     "Too large",
+			urlPath:  "/paste",
+			body:     strings.Repeat("a", maxPasteSize+1),
+			wantCode: http.StatusRequestEntityTooLarge,
+			wantBody: "Request Entity Too Large",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.contentType != "" {
+				header.Set("Content-Type", tt.contentType)
+			}
+
+			code, headers, body := ts.request(t, http.MethodPost, tt.urlPath, header, tt.body)
+
+			assert.Equal(t, code, tt.wantCode)
+			assert.Equal(t, headers.Get("Content-Type"), "text/plain; charset=utf-8")
+			assert.StringContains(t, body, tt.wantBody)
+
+			if code == http.StatusCreated {
+				assert.Equal(t, headers.Get("Location"), body)
+			}
+		})
+	}
+}
+
+func TestPasteRateLimit(t *testing.T) {
+	app := newTestApplication(t)
+	app.pasteLimiter = newRateLimiter(2, time.Minute)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	for i := 0; i < 2; i++ {
+		code, _, _ := ts.request(t, http.MethodPost, "/paste", nil, "hello")
+		assert.Equal(t, code, http.StatusCreated)
+	}
+
+	code, headers, _ := ts.request(t, http.MethodPost, "/paste", nil, "hello")
+	assert.Equal(t, code, http.StatusTooManyRequests)
+	assert.Equal(t, headers.Get("Retry-After"), "60")
+}
+
+func TestPasteTitle(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "First line", content: "\n  pod started \nready", want: "pod started"},
+		{name: "Blank", content: "\n\n", want: "Untitled paste"},
+		{name: "Long line", content: strings.Repeat("x", 120), want: strings.Repeat("x", 99) + "…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, pasteTitle(tt.content), tt.want)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimiter는 클라이언트별로 고정된 시간 창 안의 요청 수를 제한합니다.
+// 창이 끝난 항목은 Prune이 주기적으로 정리합니다.
+type rateLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	clients map[string]*rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:   limit,
+		window:  window,
+		clients: make(map[string]*rateWindow),
+	}
+}
+
+// Allow는 key의 요청을 하나 기록하고 한도를 넘지 않았으면 true를 반환합니다.
+// 한도를 넘었다면 현재 창이 끝날 때까지 남은 시간도 함께 반환합니다.
+func (rl *rateLimiter) Allow(key string) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+
+	w, ok := rl.clients[key]
+	if !ok || now.Sub(w.start) >= rl.window {
+		rl.clients[key] = &rateWindow{start: now, count: 1}
+		return true, 0
+	}
+
+	if w.count >= rl.limit {
+		return false, rl.window - now.Sub(w.start)
+	}
+
+	w.count++
+	return true, 0
+}
+
+// Prune은 창이 끝난 클라이언트 항목을 삭제합니다. runPeriodically와 함께 사용합니다.
+func (rl *rateLimiter) Prune() error {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	for key, w := range rl.clients {
+		if now.Sub(w.start) >= rl.window {
+			delete(rl.clients, key)
+		}
+	}
+
+	return nil
+}
+
+// rateLimit은 클라이언트 IP 주소마다 rl의 한도를 적용하는 미들웨어를 반환합니다.
+// 한도를 넘은 요청에는 Retry-After 헤더와 함께 429 응답을 보냅니다.
+func (app *application) rateLimit(rl *rateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ok, retryAfter := rl.Allow(remoteIP(r))
+			if !ok {
+				seconds := int((retryAfter + time.Second - 1) / time.Second)
+				w.Header().Set("Retry-After", strconv.Itoa(seconds))
+				app.clientError(w, r, http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
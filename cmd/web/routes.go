@@ -5,13 +5,21 @@ import (
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/justinas/alice"
+	"snippetbox.wook.net/internal/models"
 	"snippetbox.wook.net/ui"
 )
 
 func (app *application) routes() http.Handler {
+	standard := alice.New(app.recoverPanic, app.logRequest, secureHeaders)
+	return standard.Then(app.router())
+}
+
+// router는 모든 경로를 등록한 라우터를 반환합니다. 테스트에서 Lookup()으로 경로를 확인할 수
+// 있도록 공통 미들웨어는 routes()에서 따로 감쌉니다.
+func (app *application) router() *httprouter.Router {
 	router := httprouter.New()
 	router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		app.notFound(w)
+		app.notFound(w, r)
 	})
 	// ui.Files 임베디드 파일 시스템을 가져와서 http.FS 유형으로 변환하여
 	// http.FileSystem 인터페이스를 만족하도록 합니다.
@@ -28,21 +36,121 @@ func (app *application) routes() http.Handler {
 	// Add a new GET /ping route.
 	router.HandlerFunc(http.MethodGet, "/ping", ping)
 
+	// 피드는 세션이나 CSRF 토큰이 필요 없으므로 dynamic 미들웨어 체인을 거치지 않습니다.
+	router.HandlerFunc(http.MethodGet, "/feed.atom", app.feedLatest)
+	router.HandlerFunc(http.MethodGet, "/feed.rss", app.feedLatest)
+	router.HandlerFunc(http.MethodGet, "/feed/user/:file", app.feedUser)
+	router.HandlerFunc(http.MethodGet, "/feed/tag/:file", app.feedTag)
+	router.HandlerFunc(http.MethodGet, "/oembed", app.oembed)
+
+	// 삽입용 페이지만 허용된 출처의 iframe 안에 표시될 수 있습니다.
+	router.Handler(http.MethodGet, "/embed/:id", alice.New(app.allowEmbedding).ThenFunc(app.snippetEmbed))
+
+	// SSE 스트림은 응답을 버퍼링하는 LoadAndSave를 거치면 안 되므로 dynamic 체인 밖에 둡니다.
+	router.HandlerFunc(http.MethodGet, "/events", app.eventStream)
+
+	// 익명 붙여넣기는 curl에서 바로 쓸 수 있어야 하므로 세션과 CSRF 검사를 거치지 않는 대신
+	// 별도의 요청 수 제한을 적용합니다.
+	router.Handler(http.MethodPost, "/paste", alice.New(app.rateLimit(app.pasteLimiter)).ThenFunc(app.paste))
+
+	// 공동 편집 WebSocket은 연결을 가로채므로 LoadAndSave를 거칠 수 없습니다. 세션은
+	// 핸들러에서 직접 읽고, 다른 사이트에서의 연결은 Origin 헤더 검사로 막습니다.
+	router.HandlerFunc(http.MethodGet, "/snippet/edit/:id/ws", app.snippetCollab)
+
 	dynamic := alice.New(app.sessionManager.LoadAndSave, noSurf, app.authenticate)
 
 	router.Handler(http.MethodGet, "/", dynamic.ThenFunc(app.home))
 	router.Handler(http.MethodGet, "/snippet/view/:id", dynamic.ThenFunc(app.snippetView))
+	router.Handler(http.MethodGet, "/trending", dynamic.ThenFunc(app.trendingView))
+	router.Handler(http.MethodGet, "/collection/:id", dynamic.ThenFunc(app.collectionView))
 	router.Handler(http.MethodGet, "/user/signup", dynamic.ThenFunc(app.userSignup))
 	router.Handler(http.MethodPost, "/user/signup", dynamic.ThenFunc(app.userSignupPost))
 	router.Handler(http.MethodGet, "/user/login", dynamic.ThenFunc(app.userLogin))
 	router.Handler(http.MethodPost, "/user/login", dynamic.ThenFunc(app.userLoginPost))
+	router.Handler(http.MethodGet, "/user/login/2fa", dynamic.ThenFunc(app.userLoginTwoFactor))
+	router.Handler(http.MethodPost, "/user/login/2fa", dynamic.ThenFunc(app.userLoginTwoFactorPost))
+	router.Handler(http.MethodGet, "/user/verify", dynamic.ThenFunc(app.userVerify))
+	router.Handler(http.MethodGet, "/user/verify/resend", dynamic.ThenFunc(app.userVerifyResend))
+	router.Handler(http.MethodPost, "/user/verify/resend", dynamic.Append(app.rateLimit(app.mailLimiter)).ThenFunc(app.userVerifyResendPost))
+	router.Handler(http.MethodGet, "/user/password/forgot", dynamic.ThenFunc(app.passwordForgot))
+	router.Handler(http.MethodPost, "/user/password/forgot", dynamic.Append(app.rateLimit(app.mailLimiter)).ThenFunc(app.passwordForgotPost))
+	router.Handler(http.MethodGet, "/user/password/reset", dynamic.ThenFunc(app.passwordReset))
+	router.Handler(http.MethodPost, "/user/password/reset", dynamic.ThenFunc(app.passwordResetPost))
+	router.Handler(http.MethodGet, "/account/email/confirm", dynamic.ThenFunc(app.accountEmailConfirm))
 
 	protected := dynamic.Append(app.requireAuthentication)
 
 	router.Handler(http.MethodGet, "/snippet/create", protected.ThenFunc(app.snippetCreate))
 	router.Handler(http.MethodPost, "/snippet/create", protected.ThenFunc(app.snippetCreatePost))
+	router.Handler(http.MethodGet, "/snippet/edit/:id", protected.ThenFunc(app.snippetEdit))
+	router.Handler(http.MethodPost, "/snippet/edit/:id/collaborators", protected.ThenFunc(app.collaboratorAddPost))
+	router.Handler(http.MethodPost, "/snippet/edit/:id/collaborators/:user/remove", protected.ThenFunc(app.collaboratorRemovePost))
 	router.Handler(http.MethodPost, "/user/logout", protected.ThenFunc(app.userLogoutPost))
+	router.Handler(http.MethodGet, "/collections", protected.ThenFunc(app.collectionList))
+	router.Handler(http.MethodPost, "/collections", protected.ThenFunc(app.collectionCreatePost))
+	router.Handler(http.MethodPost, "/collection/:id/share", protected.ThenFunc(app.collectionSharePost))
+	router.Handler(http.MethodPost, "/collection/:id/add", protected.ThenFunc(app.collectionAddPost))
+	router.Handler(http.MethodPost, "/collection/:id/remove", protected.ThenFunc(app.collectionRemovePost))
+	router.Handler(http.MethodPost, "/collection/:id/move", protected.ThenFunc(app.collectionMovePost))
+	router.Handler(http.MethodGet, "/account", protected.ThenFunc(app.accountView))
+	router.Handler(http.MethodPost, "/account/name", protected.ThenFunc(app.accountNamePost))
+	router.Handler(http.MethodPost, "/account/email", protected.Append(app.rateLimit(app.mailLimiter)).ThenFunc(app.accountEmailPost))
+	router.Handler(http.MethodPost, "/account/password", protected.ThenFunc(app.accountPasswordPost))
+	router.Handler(http.MethodGet, "/account/2fa", protected.ThenFunc(app.twoFactorView))
+	router.Handler(http.MethodPost, "/account/2fa", protected.ThenFunc(app.twoFactorEnablePost))
+	router.Handler(http.MethodPost, "/account/2fa/disable", protected.ThenFunc(app.twoFactorDisablePost))
+	router.Handler(http.MethodGet, "/account/export", protected.ThenFunc(app.accountExport))
+	router.Handler(http.MethodGet, "/account/delete", protected.ThenFunc(app.accountDelete))
+	router.Handler(http.MethodPost, "/account/delete", protected.ThenFunc(app.accountDeletePost))
+	router.Handler(http.MethodGet, "/account/sessions", protected.ThenFunc(app.sessionList))
+	router.Handler(http.MethodPost, "/user/logout/all", protected.ThenFunc(app.sessionRevokeAllPost))
+	router.Handler(http.MethodPost, "/account/sessions/:id/revoke", protected.ThenFunc(app.sessionRevokePost))
+	router.Handler(http.MethodGet, "/account/tokens", protected.ThenFunc(app.tokenList))
+	router.Handler(http.MethodPost, "/account/tokens", protected.ThenFunc(app.tokenCreatePost))
+	router.Handler(http.MethodPost, "/account/tokens/:id/revoke", protected.ThenFunc(app.tokenRevokePost))
+	router.Handler(http.MethodGet, "/account/webhooks", protected.ThenFunc(app.webhookList))
+	router.Handler(http.MethodPost, "/account/webhooks", protected.ThenFunc(app.webhookCreatePost))
+	router.Handler(http.MethodGet, "/account/webhooks/:id", protected.ThenFunc(app.webhookView))
+	router.Handler(http.MethodPost, "/account/webhooks/:id/delete", protected.ThenFunc(app.webhookDeletePost))
 
-	standard := alice.New(app.recoverPanic, app.logRequest, secureHeaders)
-	return standard.Then(router)
+	// JSON API는 세션 쿠키 또는 개인 액세스 토큰으로 인증합니다. 쿠키로 인증된 요청도
+	// CSRF 토큰 대신 readJSON()의 Content-Type 검사에 의존하므로 noSurf 미들웨어를 사용하지 않습니다.
+	api := alice.New(app.sessionManager.LoadAndSave, app.authenticate, app.authenticateToken)
+	apiWrite := api.Append(app.requireAPIAuthentication, app.requireScope(models.ScopeSnippetsWrite))
+
+	for _, route := range app.apiRoutes() {
+		chain := api
+		if route.write {
+			chain = apiWrite
+		}
+		router.Handler(route.method, route.path, chain.ThenFunc(route.handler))
+	}
+
+	// GraphQL도 JSON API와 같은 방식으로 인증합니다. 스키마가 읽기 전용이므로 GET 요청도 받습니다.
+	router.Handler(http.MethodGet, "/graphql", api.ThenFunc(app.graphqlHandler))
+	router.Handler(http.MethodPost, "/graphql", api.ThenFunc(app.graphqlHandler))
+
+	return router
+}
+
+// apiRoute는 /api/v1 아래의 경로 하나를 나타냅니다. write가 true인 경로는 인증과
+// snippets:write 권한 범위가 필요합니다.
+type apiRoute struct {
+	method  string
+	path    string
+	handler http.HandlerFunc
+	write   bool
+}
+
+// apiRoutes는 모든 JSON API 경로 목록입니다. 라우터 등록과 OpenAPI 문서를 비교하는
+// 테스트가 같은 목록을 사용하므로, 경로를 추가하면 ui/api/openapi.json도 함께 수정해야 합니다.
+func (app *application) apiRoutes() []apiRoute {
+	return []apiRoute{
+		{http.MethodGet, "/api/v1/openapi.json", app.apiOpenAPI, false},
+		{http.MethodGet, "/api/v1/snippets", app.apiSnippetList, false},
+		{http.MethodPost, "/api/v1/snippets", app.apiSnippetCreate, true},
+		{http.MethodGet, "/api/v1/snippets/:id", app.apiSnippetGet, false},
+		{http.MethodPatch, "/api/v1/snippets/:id", app.apiSnippetUpdate, true},
+		{http.MethodDelete, "/api/v1/snippets/:id", app.apiSnippetDelete, true},
+	}
 }
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"snippetbox.wook.net/internal/models"
+)
+
+const (
+	// sessionTouchInterval은 로그인 기록의 마지막 사용 시각을 갱신하는 최소 간격입니다.
+	// 요청마다 데이터베이스에 쓰지 않도록 세션에 마지막으로 갱신한 시각을 함께 저장합니다.
+	sessionTouchInterval = time.Minute
+
+	// loginLifetime은 로그인 유지를 선택하지 않은 로그인의 최대 유지 시간입니다. 이런
+	// 로그인의 쿠키는 브라우저를 닫으면 사라지지만, 브라우저를 계속 열어 두더라도 이 시간이
+	// 지나면 로그아웃됩니다. 로그인 유지를 선택한 로그인은 세션 관리자의 Lifetime과
+	// IdleTimeout을 따릅니다.
+	loginLifetime = 12 * time.Hour
+)
+
+// startUserSession은 로그인한 세션의 기록을 만들고 그 ID를 세션에 "sessionID"로 저장합니다.
+// authenticatedUserID를 세션에 넣을 때마다 호출해야 합니다. rememberMe가 true이면 세션
+// 쿠키를 브라우저를 닫아도 남는 영구 쿠키로 보냅니다.
+func (app *application) startUserSession(r *http.Request, userID int, rememberMe bool) error {
+	token := app.sessionManager.Token(r.Context())
+
+	id, err := app.sessions.Insert(userID, token, r.UserAgent(), remoteIP(r))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	app.sessionManager.Put(r.Context(), "sessionID", id)
+	app.sessionManager.Put(r.Context(), "sessionSeen", now.Unix())
+
+	app.sessionManager.RememberMe(r.Context(), rememberMe)
+	if rememberMe {
+		app.sessionManager.Remove(r.Context(), "sessionExpires")
+	} else {
+		app.sessionManager.Put(r.Context(), "sessionExpires", now.Add(loginLifetime).Unix())
+	}
+
+	return nil
+}
+
+// loginExpired는 로그인 유지를 선택하지 않은 로그인이 loginLifetime을 넘겼는지 확인합니다.
+func (app *application) loginExpired(ctx context.Context) bool {
+	expires := app.sessionManager.GetInt64(ctx, "sessionExpires")
+	return expires != 0 && time.Now().Unix() > expires
+}
+
+// activeUserSession은 userID로 로그인한 이 세션이 아직 유효한지 확인합니다. 로그인이
+// loginLifetime을 넘겼거나 세션의 로그인 기록이 없으면 false를 반환합니다.
+func (app *application) activeUserSession(ctx context.Context, userID int) (bool, error) {
+	if app.loginExpired(ctx) {
+		return false, nil
+	}
+
+	id := app.sessionManager.GetInt(ctx, "sessionID")
+	if id == 0 {
+		return false, nil
+	}
+
+	return app.sessions.Exists(userID, id)
+}
+
+// renewSessionToken은 RenewToken()으로 세션 토큰을 바꾸고, 로그인한 세션이면 로그인 기록의
+// 토큰도 함께 바꿉니다. 로그인한 상태에서 토큰을 바꿀 때는 이 메서드를 사용해야 합니다.
+func (app *application) renewSessionToken(ctx context.Context) error {
+	err := app.sessionManager.RenewToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	id := app.sessionManager.GetInt(ctx, "sessionID")
+	if id == 0 {
+		return nil
+	}
+
+	return app.sessions.SetToken(id, app.sessionManager.Token(ctx))
+}
+
+// touchUserSession은 로그인 기록의 마지막 사용 시각을 갱신합니다. 실패해도 요청을 막을
+// 이유는 없으므로 오류는 기록만 합니다.
+func (app *application) touchUserSession(r *http.Request) {
+	id := app.sessionManager.GetInt(r.Context(), "sessionID")
+	if id == 0 {
+		return
+	}
+
+	now := time.Now()
+	if now.Unix()-app.sessionManager.GetInt64(r.Context(), "sessionSeen") < int64(sessionTouchInterval/time.Second) {
+		return
+	}
+
+	err := app.sessions.Touch(id)
+	if err != nil {
+		app.errorLog.Print(err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "sessionSeen", now.Unix())
+}
+
+// logoutSession은 이 요청의 세션에서 로그인 정보를 지우고 로그인 기록을 삭제합니다.
+// 세션 고정 공격을 막으려면 먼저 RenewToken()을 호출해야 합니다.
+func (app *application) logoutSession(ctx context.Context) error {
+	userID := app.sessionManager.GetInt(ctx, "authenticatedUserID")
+	id := app.sessionManager.GetInt(ctx, "sessionID")
+
+	app.sessionManager.Remove(ctx, "authenticatedUserID")
+	app.sessionManager.Remove(ctx, "sessionID")
+	app.sessionManager.Remove(ctx, "sessionSeen")
+	app.sessionManager.Remove(ctx, "sessionExpires")
+	app.sessionManager.RememberMe(ctx, false)
+
+	if userID == 0 || id == 0 {
+		return nil
+	}
+
+	_, err := app.sessions.Delete(userID, id)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		return err
+	}
+
+	return nil
+}
+
+// destroyStoreSession은 세션 저장소에서 token의 세션을 삭제합니다. 로그인 기록에는 토큰이
+// 있으므로 저장소의 모든 세션을 읽지 않고 바로 삭제할 수 있습니다.
+func (app *application) destroyStoreSession(token string) error {
+	if token == "" {
+		return nil
+	}
+	return app.sessionManager.Store.Delete(token)
+}
+
+// destroyUserSessions는 userID로 로그인한 다른 세션을 모두 로그인 기록과 함께 삭제합니다.
+// 이 요청의 세션은 남겨 두므로, 이 세션도 끝내려면 호출한 쪽에서 토큰을 바꾸고
+// logoutSession()을 호출해야 합니다.
+func (app *application) destroyUserSessions(ctx context.Context, userID int) error {
+	keep := 0
+	if app.sessionManager.GetInt(ctx, "authenticatedUserID") == userID {
+		keep = app.sessionManager.GetInt(ctx, "sessionID")
+	}
+
+	tokens, err := app.sessions.DeleteForUser(userID, keep)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		err = app.destroyStoreSession(token)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (app *application) sessionList(w http.ResponseWriter, r *http.Request) {
+	sessions, err := app.sessions.ForUser(app.authenticatedUserID(r))
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Sessions = sessions
+	data.CurrentSessionID = app.sessionManager.GetInt(r.Context(), "sessionID")
+	app.render(w, r, http.StatusOK, "sessions.go.tpl", data)
+}
+
+// sessionRevokePost는 로그인 기록 하나와 그 기록에 해당하는 세션을 삭제합니다. 이 요청의
+// 세션을 철회하면 로그아웃과 같습니다.
+func (app *application) sessionRevokePost(w http.ResponseWriter, r *http.Request) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	id, err := strconv.Atoi(params.ByName("id"))
+	if err != nil || id < 1 {
+		app.notFound(w, r)
+		return
+	}
+
+	if id == app.sessionManager.GetInt(r.Context(), "sessionID") {
+		app.logoutEverywhere(w, r, false)
+		return
+	}
+
+	userID := app.authenticatedUserID(r)
+
+	token, err := app.sessions.Delete(userID, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w, r)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return
+	}
+
+	err = app.destroyStoreSession(token)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "The session has been logged out.")
+
+	http.Redirect(w, r, "/account/sessions", http.StatusSeeOther)
+}
+
+func (app *application) sessionRevokeAllPost(w http.ResponseWriter, r *http.Request) {
+	app.logoutEverywhere(w, r, true)
+}
+
+// logoutEverywhere는 이 요청의 세션을 로그아웃시킵니다. all이 true이면 같은 사용자의 다른
+// 세션도 모두 삭제합니다.
+func (app *application) logoutEverywhere(w http.ResponseWriter, r *http.Request, all bool) {
+	if all {
+		err := app.destroyUserSessions(r.Context(), app.authenticatedUserID(r))
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	err := app.sessionManager.RenewToken(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	err = app.logoutSession(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	if all {
+		app.sessionManager.Put(r.Context(), "flash", "You've been logged out everywhere.")
+	} else {
+		app.sessionManager.Put(r.Context(), "flash", "You've been logged out successfully !")
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"snippetbox.wook.net/internal/assert"
+	"snippetbox.wook.net/internal/models/mocks"
+	"snippetbox.wook.net/internal/totp"
+)
+
+// loginCookie는 email로 로그인하고 로그인 응답이 보낸 세션 쿠키를 반환합니다. 2단계 인증을
+// 사용하는 사용자이면 현재 TOTP 코드로 인증 코드 단계까지 마칩니다.
+func (ts *testServer) loginCookie(t *testing.T, email string, rememberMe bool) string {
+	_, _, body := ts.get(t, "/user/login")
+	csrfToken := extractCSRFToken(t, body)
+
+	form := url.Values{}
+	form.Add("email", email)
+	form.Add("password", "pa$$word")
+	form.Add("csrf_token", csrfToken)
+	if rememberMe {
+		form.Add("remember_me", "true")
+	}
+
+	code, header, _ := ts.postForm(t, "/user/login", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+
+	if header.Get("Location") == "/user/login/2fa" {
+		form = url.Values{}
+		form.Add("code", totp.Code(mocks.MockTOTPSecret, totp.Step(time.Now())))
+		form.Add("csrf_token", csrfToken)
+
+		code, header, _ = ts.postForm(t, "/user/login/2fa", form)
+		assert.Equal(t, code, http.StatusSeeOther)
+	}
+
+	assert.Equal(t, header.Get("Location"), "/snippet/create")
+
+	return header.Get("Set-Cookie")
+}
+
+func TestUserLoginRememberMe(t *testing.T) {
+	tests := []struct {
+		name        string
+		email       string
+		rememberMe  bool
+		wantPersist bool
+	}{
+		{"Not remembered", "alice@example.com", false, false},
+		{"Remembered", "alice@example.com", true, true},
+		{"Two-factor not remembered", "carol@example.com", false, false},
+		{"Two-factor remembered", "carol@example.com", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			cookie := ts.loginCookie(t, tt.email, tt.rememberMe)
+			assert.StringContains(t, cookie, "session=")
+			assert.Equal(t, strings.Contains(cookie, "Expires="), tt.wantPersist)
+
+			code, _, _ := ts.get(t, "/account")
+			assert.Equal(t, code, http.StatusOK)
+		})
+	}
+}
+
+// expireLogin은 ts의 로그인이 loginLifetime을 넘긴 것처럼 세션 저장소의 sessionExpires를 바꿉니다.
+func expireLogin(t *testing.T, app *application, ts *testServer) {
+	ctx, err := app.sessionManager.Load(context.Background(), ts.sessionCookie(t))
+	assert.NilError(t, err)
+
+	app.sessionManager.Put(ctx, "sessionExpires", time.Now().Add(-time.Minute).Unix())
+
+	_, _, err = app.sessionManager.Commit(ctx)
+	assert.NilError(t, err)
+}
+
+func TestLoginExpired(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.loginCookie(t, "alice@example.com", false)
+	expireLogin(t, app, ts)
+
+	// WebSocket 경로도 authenticate와 같이 만료된 로그인을 받지 않습니다.
+	_, code := ts.dialCollab(t, "/snippet/edit/1/ws", ts.URL)
+	assert.Equal(t, code, http.StatusUnauthorized)
+
+	code, header, _ := ts.get(t, "/account")
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/user/login")
+
+	sessions, err := app.sessions.ForUser(1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(sessions), 0)
+}
+
+func TestSessionList(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	other := newTestServer(t, app.routes())
+	defer other.Close()
+	other.login(t)
+
+	ts.login(t)
+
+	code, _, body := ts.get(t, "/account/sessions")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "127.0.0.1")
+	assert.StringContains(t, body, "Go-http-client")
+	assert.StringContains(t, body, "This session")
+	assert.StringContains(t, body, "<form action='/account/sessions/1/revoke' method='POST'>")
+	assert.StringContains(t, body, "<form action='/account/sessions/2/revoke' method='POST'>")
+}
+
+func TestSessionRevokePost(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	// other는 로그인 기록 1, ts는 로그인 기록 2를 사용합니다.
+	other := newTestServer(t, app.routes())
+	defer other.Close()
+	other.login(t)
+
+	csrfToken := ts.login(t)
+
+	tests := []struct {
+		name         string
+		urlPath      string
+		wantCode     int
+		wantLocation string
+	}{
+		{"Unknown session", "/account/sessions/99/revoke", http.StatusNotFound, ""},
+		{"Invalid ID", "/account/sessions/foo/revoke", http.StatusNotFound, ""},
+		{"Other session", "/account/sessions/1/revoke", http.StatusSeeOther, "/account/sessions"},
+		{"Already revoked", "/account/sessions/1/revoke", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+
+			code, header, _ := ts.postForm(t, tt.urlPath, form)
+			assert.Equal(t, code, tt.wantCode)
+			assert.Equal(t, header.Get("Location"), tt.wantLocation)
+		})
+	}
+
+	// 철회한 세션은 로그아웃되고 목록에서 사라집니다.
+	code, _, _ := other.get(t, "/account")
+	assert.Equal(t, code, http.StatusSeeOther)
+
+	code, _, body := ts.get(t, "/account/sessions")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "The session has been logged out.")
+	if strings.Contains(body, "/account/sessions/1/revoke") {
+		t.Errorf("want revoked session to be removed from the list")
+	}
+
+	// 이 세션을 철회하면 로그아웃됩니다.
+	form := url.Values{}
+	form.Add("csrf_token", csrfToken)
+
+	code, header, _ := ts.postForm(t, "/account/sessions/2/revoke", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/")
+
+	code, _, _ = ts.get(t, "/account")
+	assert.Equal(t, code, http.StatusSeeOther)
+
+	sessions, err := app.sessions.ForUser(1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(sessions), 0)
+}
+
+func TestSessionRevokeAllPost(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	other := newTestServer(t, app.routes())
+	defer other.Close()
+	other.login(t)
+
+	csrfToken := ts.login(t)
+
+	form := url.Values{}
+	form.Add("csrf_token", csrfToken)
+
+	code, header, _ := ts.postForm(t, "/user/logout/all", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/")
+
+	_, _, body := ts.get(t, "/")
+	assert.StringContains(t, body, "You&#39;ve been logged out everywhere.")
+
+	code, _, _ = ts.get(t, "/account")
+	assert.Equal(t, code, http.StatusSeeOther)
+
+	code, _, _ = other.get(t, "/account")
+	assert.Equal(t, code, http.StatusSeeOther)
+
+	sessions, err := app.sessions.ForUser(1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(sessions), 0)
+}
+
+func TestSessionRevokeAfterRenewToken(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	// ts는 로그인 기록 1, other는 로그인 기록 2를 사용합니다.
+	csrfToken := ts.login(t)
+
+	other := newTestServer(t, app.routes())
+	defer other.Close()
+	otherCSRFToken := other.login(t)
+
+	// 이름을 바꾸면 세션 토큰이 바뀌므로 로그인 기록의 토큰도 함께 바뀌어야 합니다.
+	form := url.Values{}
+	form.Add("name", "Alice Smith")
+	form.Add("csrf_token", csrfToken)
+
+	code, _, _ := ts.postForm(t, "/account/name", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+
+	code, _, _ = ts.get(t, "/account")
+	assert.Equal(t, code, http.StatusOK)
+
+	form = url.Values{}
+	form.Add("csrf_token", otherCSRFToken)
+
+	code, _, _ = other.postForm(t, "/account/sessions/1/revoke", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+
+	code, _, _ = ts.get(t, "/account")
+	assert.Equal(t, code, http.StatusSeeOther)
+}
+
+// TestSessionRevokeInFlight는 철회한 세션을 그때 처리 중이던 요청이 다시 저장하더라도
+// 로그인한 상태로 돌아오지 않는지 확인합니다.
+func TestSessionRevokeInFlight(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t)
+
+	other := newTestServer(t, app.routes())
+	defer other.Close()
+	csrfToken := other.login(t)
+
+	token := ts.sessionCookie(t)
+	data, found, err := app.sessionManager.Store.Find(token)
+	assert.NilError(t, err)
+	assert.Equal(t, found, true)
+
+	form := url.Values{}
+	form.Add("csrf_token", csrfToken)
+
+	code, _, _ := other.postForm(t, "/account/sessions/1/revoke", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+
+	err = app.sessionManager.Store.Commit(token, data, time.Now().Add(time.Hour))
+	assert.NilError(t, err)
+
+	code, header, _ := ts.get(t, "/account")
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/user/login")
+
+	// WebSocket 경로도 같은 세션을 받지 않습니다.
+	_, code = ts.dialCollab(t, "/snippet/edit/1/ws", ts.URL)
+	assert.Equal(t, code, http.StatusUnauthorized)
+}
+
+// TestSessionWithoutRecord는 로그인 기록을 만들기 전에 로그인한 세션을 로그아웃시키는지
+// 확인합니다. 이런 세션은 세션 목록에 나타나지 않아 철회할 수 없습니다.
+func TestSessionWithoutRecord(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	ts.login(t)
+
+	ctx, err := app.sessionManager.Load(context.Background(), ts.sessionCookie(t))
+	assert.NilError(t, err)
+
+	app.sessionManager.Remove(ctx, "sessionID")
+
+	_, _, err = app.sessionManager.Commit(ctx)
+	assert.NilError(t, err)
+
+	code, header, _ := ts.get(t, "/account")
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/user/login")
+}
@@ -4,20 +4,46 @@ import (
 	"html/template"
 	"io/fs"
 	"path/filepath"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"snippetbox.wook.net/internal/models"
 	"snippetbox.wook.net/ui"
 )
 
 type templateData struct {
-	CurrentYear     int
-	Snippet         *models.Snippet
-	Snippets        []*models.Snippet
-	Form            any
-	Flash           string
-	IsAuthenticated bool
-	CSRFToken       string
+	CurrentYear      int
+	Snippet          *models.Snippet
+	Snippets         []*models.Snippet
+	Rankings         []*models.Ranking
+	Period           string
+	Collection       *models.Collection
+	Collections      []*models.Collection
+	IsOwner          bool
+	CanEdit          bool
+	Form             any
+	Flash            string
+	IsAuthenticated  bool
+	CSRFToken        string
+	BaseURL          string
+	EmbedCode        string
+	Tokens           []*models.Token
+	NewToken         string
+	Scopes           []string
+	Webhook          *models.Webhook
+	Webhooks         []*models.Webhook
+	Deliveries       []*models.WebhookDelivery
+	NewSecret        string
+	Revisions        []*models.Revision
+	Collaborators    []*models.Collaborator
+	User             *models.User
+	TwoFactor        *models.TwoFactor
+	TOTPEnabled      bool
+	QRCode           template.HTML
+	RecoveryCodes    []string
+	Sessions         []*models.Session
+	CurrentSessionID int
 }
 
 func humaDate(t time.Time) string {
@@ -27,11 +53,33 @@ func humaDate(t time.Time) string {
 	return t.UTC().Format("02 Jan 2006 at 15:04")
 }
 
+// excerpt는 링크 미리보기에 사용할 수 있도록 공백을 하나로 합친 내용의 앞부분을
+// 최대 160자까지 반환합니다.
+func excerpt(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= 160 {
+		return s
+	}
+	return string([]rune(s)[:159]) + "…"
+}
+
+// contains는 values에 value가 있으면 true를 반환합니다. 체크박스의 선택 상태를 표시하는 데 사용합니다.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // 템플릿.FuncMap 객체를 초기화하여 전역 변수에 저장합니다.
 // 이것은 기본적으로 사용자 정의 템플릿 함수의 이름과 함수 자체 사이의 조회 역할을
 // 하는 문자열 키 맵입니다.
 var functions = template.FuncMap{
 	"humanDate": humaDate,
+	"excerpt":   excerpt,
+	"contains":  contains,
 }
 
 func newTemplateCache() (map[string]*template.Template, error) {
@@ -64,5 +112,14 @@ func newTemplateCache() (map[string]*template.Template, error) {
 
 		cache[name] = ts
 	}
+
+	// 삽입용 페이지는 내비게이션이나 머리글이 없는 별도의 레이아웃을 사용합니다.
+	// 이 파일이 자체적으로 "base" 템플릿을 정의하므로 render()로 그대로 렌더링할 수 있습니다.
+	ts, err := template.New("embed.go.tpl").Funcs(functions).ParseFS(ui.Files, "html/embed.go.tpl")
+	if err != nil {
+		return nil, err
+	}
+	cache["embed.go.tpl"] = ts
+
 	return cache, nil
 }
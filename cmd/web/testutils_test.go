@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -44,18 +45,47 @@ func newTestApplication(t *testing.T) *application {
 	formDecoder := form.NewDecoder()
 
 	sessionManager := scs.New()
-	sessionManager.Lifetime = 12 * time.Hour
+	sessionManager.Lifetime = 30 * 24 * time.Hour
+	sessionManager.IdleTimeout = 7 * 24 * time.Hour
+	sessionManager.Cookie.Persist = false
 	sessionManager.Cookie.Secure = true
 
-	return &application{
+	snippets := &mocks.SnippetModel{}
+
+	app := &application{
 		errorLog:       log.New(io.Discard, "", 0),
 		infoLog:        log.New(io.Discard, "", 0),
-		snippets:       &mocks.SnippetModel{}, // Use the mock.
-		users:          &mocks.UserModel{},    // Use the mock.
+		snippets:       snippets,           // Use the mock.
+		users:          &mocks.UserModel{}, // Use the mock.
+		trending:       &mocks.TrendingModel{},
+		collections:    &mocks.CollectionModel{},
+		tokens:         &mocks.TokenModel{},
+		webhooks:       &mocks.WebhookModel{},
+		revisions:      &mocks.RevisionModel{},
+		collaborators:  &mocks.CollaboratorModel{},
+		outbox:         &mocks.OutboxModel{},
+		passwordResets: &mocks.PasswordResetModel{},
+		twoFactor:      &mocks.TwoFactorModel{},
+		sessions:       &mocks.SessionModel{},
 		templateCache:  templateCache,
 		formDecoder:    formDecoder,
 		sessionManager: sessionManager,
+		views:          newViewCounter(snippets),
+		pasteLimiter:   newRateLimiter(100, time.Minute),
+		mailLimiter:    newRateLimiter(100, time.Minute),
+		totpLimiter:    newRateLimiter(100, time.Minute),
+		totpEnabled:    true,
+		secret:         []byte("test-secret"),
+		hub:            newEventHub(),
+		collab:         newCollabHub(),
 	}
+
+	app.graphql, err = app.newGraphQLSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app
 }
 
 // httptest.Server 인스턴스를 임베드하는 사용자 정의 testServer 유형을 정의합니다.
@@ -121,3 +151,48 @@ func (ts *testServer) postForm(t *testing.T, urlPath string, form url.Values) (i
 
 	return rs.StatusCode, rs.Header, string(body)
 }
+
+// 임의의 메서드와 본문으로 테스트 서버에 요청을 보내는 request 메서드를 만듭니다.
+// header에 담긴 값은 요청 헤더에 그대로 추가됩니다.
+func (ts *testServer) request(t *testing.T, method, urlPath string, header http.Header, body string) (int, http.Header, string) {
+	req, err := http.NewRequest(method, ts.URL+urlPath, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	rs, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Body.Close()
+
+	rsBody, err := io.ReadAll(rs.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rs.StatusCode, rs.Header, string(bytes.TrimSpace(rsBody))
+}
+
+// login은 모의 사용자 alice로 로그인하고, 이후 POST 요청에 사용할 수 있는 CSRF 토큰을 반환합니다.
+func (ts *testServer) login(t *testing.T) string {
+	_, _, body := ts.get(t, "/user/login")
+	csrfToken := extractCSRFToken(t, body)
+
+	form := url.Values{}
+	form.Add("email", "alice@example.com")
+	form.Add("password", "pa$$word")
+	form.Add("csrf_token", csrfToken)
+
+	code, _, _ := ts.postForm(t, "/user/login", form)
+	if code != http.StatusSeeOther {
+		t.Fatalf("login failed with status %d", code)
+	}
+
+	return csrfToken
+}
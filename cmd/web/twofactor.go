@@ -0,0 +1,423 @@
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"snippetbox.wook.net/internal/models"
+	"snippetbox.wook.net/internal/qr"
+	"snippetbox.wook.net/internal/totp"
+	"snippetbox.wook.net/internal/validator"
+)
+
+const (
+	// twoFactorLoginTTL은 비밀번호를 확인한 뒤 인증 코드를 입력해야 하는 시간입니다.
+	twoFactorLoginTTL = 5 * time.Minute
+	// twoFactorMaxAttempts는 로그인 한 번에 허용하는 잘못된 인증 코드 수입니다. 이를 넘으면
+	// 비밀번호부터 다시 입력해야 합니다.
+	twoFactorMaxAttempts = 5
+	// 비밀번호를 아는 공격자가 로그인을 반복하며 코드를 추측하지 못하도록, 로그인과 관계없이
+	// 사용자마다 twoFactorUserWindow 동안 twoFactorUserLimit번까지만 코드를 확인합니다.
+	twoFactorUserLimit  = 10
+	twoFactorUserWindow = 15 * time.Minute
+	// recoveryCodeCount는 2단계 인증을 켤 때 발급하는 복구 코드 수입니다.
+	recoveryCodeCount = 10
+	// totpIssuer는 인증 앱에 표시되는 서비스 이름입니다.
+	totpIssuer = "Snippetbox"
+)
+
+type twoFactorLoginForm struct {
+	Code                string `form:"code"`
+	validator.Validator `form:"-"`
+}
+
+type twoFactorEnableForm struct {
+	Secret              string `form:"secret"`
+	Code                string `form:"code"`
+	Password            string `form:"password"`
+	validator.Validator `form:"-"`
+}
+
+type twoFactorDisableForm struct {
+	Password            string `form:"password"`
+	validator.Validator `form:"-"`
+}
+
+// recoveryCodeAlphabet에는 헷갈리기 쉬운 0, 1, l, o가 없습니다.
+const recoveryCodeAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+
+// newRecoveryCodes는 "xxxxx-xxxxx" 형식의 복구 코드를 만듭니다. 코드마다 50비트의
+// 무작위 값을 담습니다.
+func newRecoveryCodes() ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+
+	b := make([]byte, 10)
+	for i := range codes {
+		_, err := rand.Read(b)
+		if err != nil {
+			return nil, err
+		}
+
+		code := make([]byte, len(b))
+		for j := range b {
+			code[j] = recoveryCodeAlphabet[b[j]&31]
+		}
+		codes[i] = string(code[:5]) + "-" + string(code[5:])
+	}
+
+	return codes, nil
+}
+
+// startTwoFactorLogin은 비밀번호를 확인한 사용자를 인증 코드 입력 단계로 보냅니다. 세션에는
+// 사용자 ID와 로그인 유지 여부만 남기며 authenticatedUserID는 코드를 확인한 뒤에 넣습니다.
+func (app *application) startTwoFactorLogin(w http.ResponseWriter, r *http.Request, userID int, rememberMe bool) {
+	err := app.sessionManager.RenewToken(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "twoFactorUserID", userID)
+	app.sessionManager.Put(r.Context(), "twoFactorExpires", time.Now().Add(twoFactorLoginTTL).Unix())
+	app.sessionManager.Put(r.Context(), "twoFactorAttempts", 0)
+	app.sessionManager.Put(r.Context(), "twoFactorRememberMe", rememberMe)
+
+	http.Redirect(w, r, "/user/login/2fa", http.StatusSeeOther)
+}
+
+// pendingTwoFactorUser는 인증 코드 입력을 기다리는 사용자의 ID를 반환합니다. 기다리는
+// 사용자가 없거나 시간이 지났으면 0을 반환합니다.
+func (app *application) pendingTwoFactorUser(r *http.Request) int {
+	if time.Now().Unix() > app.sessionManager.GetInt64(r.Context(), "twoFactorExpires") {
+		return 0
+	}
+	return app.sessionManager.GetInt(r.Context(), "twoFactorUserID")
+}
+
+func (app *application) clearTwoFactorLogin(r *http.Request) {
+	app.sessionManager.Remove(r.Context(), "twoFactorUserID")
+	app.sessionManager.Remove(r.Context(), "twoFactorExpires")
+	app.sessionManager.Remove(r.Context(), "twoFactorAttempts")
+	app.sessionManager.Remove(r.Context(), "twoFactorRememberMe")
+}
+
+// checkSecondFactor는 code가 사용자의 현재 TOTP 코드이거나 아직 쓰지 않은 복구 코드인지
+// 확인합니다. 두 경우 모두 코드는 다시 쓸 수 없게 됩니다. 일치하지 않으면
+// models.ErrInvalidCredentials를 반환하며, usedRecoveryCode는 복구 코드로 확인했을 때 true입니다.
+func (app *application) checkSecondFactor(userID int, code string) (usedRecoveryCode bool, err error) {
+	code = strings.TrimSpace(code)
+
+	if len(strings.ReplaceAll(code, " ", "")) != totp.Digits {
+		return true, app.twoFactor.UseRecoveryCode(userID, code)
+	}
+
+	secret, err := app.twoFactor.Secret(userID)
+	if err != nil {
+		return false, err
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return false, models.ErrInvalidCredentials
+	}
+
+	return false, app.twoFactor.UseStep(userID, step)
+}
+
+func (app *application) userLoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if app.pendingTwoFactorUser(r) == 0 {
+		http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Form = twoFactorLoginForm{}
+	app.render(w, r, http.StatusOK, "login_2fa.go.tpl", data)
+}
+
+func (app *application) userLoginTwoFactorPost(w http.ResponseWriter, r *http.Request) {
+	userID := app.pendingTwoFactorUser(r)
+	if userID == 0 {
+		app.clearTwoFactorLogin(r)
+		app.sessionManager.Put(r.Context(), "flash", "Your login has timed out. Please log in again.")
+		http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+		return
+	}
+
+	var form twoFactorLoginForm
+
+	err := app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Code), "code", "This field cannot be blank")
+
+	if form.Valid() {
+		ok, retryAfter := app.totpLimiter.Allow(strconv.Itoa(userID))
+		if !ok {
+			seconds := int((retryAfter + time.Second - 1) / time.Second)
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			form.AddFieldError("code", "Too many attempts. Please try again later.")
+
+			data := app.newTemplateData(r)
+			data.Form = form
+			app.render(w, r, http.StatusTooManyRequests, "login_2fa.go.tpl", data)
+			return
+		}
+	}
+
+	var usedRecoveryCode bool
+	if form.Valid() {
+		usedRecoveryCode, err = app.checkSecondFactor(userID, form.Code)
+		if errors.Is(err, models.ErrInvalidCredentials) {
+			form.AddFieldError("code", "Invalid authentication code")
+
+			// 코드를 계속 추측하지 못하도록 일정 횟수를 넘으면 처음부터 다시 로그인하게 합니다.
+			attempts := app.sessionManager.GetInt(r.Context(), "twoFactorAttempts") + 1
+			if attempts >= twoFactorMaxAttempts {
+				app.clearTwoFactorLogin(r)
+				app.sessionManager.Put(r.Context(), "flash", "Too many incorrect codes. Please log in again.")
+				http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+				return
+			}
+			app.sessionManager.Put(r.Context(), "twoFactorAttempts", attempts)
+		} else if errors.Is(err, models.ErrUnreadableSecret) {
+			// 암호화 키가 바뀌어 비밀 키를 읽을 수 없는 경우입니다. 사용자가 고칠 수 있는 문제가
+			// 아니므로 기록해 두고 복구 코드를 쓰도록 안내합니다. 잘못된 시도로 세지 않습니다.
+			app.errorLog.Print(err)
+			form.AddFieldError("code", "Authenticator codes can't be checked right now. Please use one of your recovery codes.")
+		} else if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	if !form.Valid() {
+		data := app.newTemplateData(r)
+		data.Form = form
+		app.render(w, r, http.StatusUnprocessableEntity, "login_2fa.go.tpl", data)
+		return
+	}
+
+	err = app.sessionManager.RenewToken(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	rememberMe := app.sessionManager.GetBool(r.Context(), "twoFactorRememberMe")
+
+	app.clearTwoFactorLogin(r)
+	app.sessionManager.Put(r.Context(), "authenticatedUserID", userID)
+
+	err = app.startUserSession(r, userID, rememberMe)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	if usedRecoveryCode {
+		app.sessionManager.Put(r.Context(), "flash", "You've used one of your recovery codes. Each code works only once.")
+	}
+
+	http.Redirect(w, r, "/snippet/create", http.StatusSeeOther)
+}
+
+// renderTwoFactor는 2단계 인증 설정 페이지를 그립니다. 2단계 인증이 꺼져 있으면 등록
+// 양식의 비밀 키로 QR 코드를 만들며, 양식이 없으면 새 비밀 키로 등록 양식을 만듭니다.
+// 비밀 키는 등록을 마칠 때까지 서버에 저장하지 않고 양식의 숨은 필드로 주고받습니다.
+func (app *application) renderTwoFactor(w http.ResponseWriter, r *http.Request, status int, user *models.User, form any) {
+	tf, err := app.twoFactor.Get(user.ID)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.User = user
+	data.TwoFactor = tf
+
+	if tf != nil {
+		if form == nil {
+			form = twoFactorDisableForm{}
+		}
+		data.Form = form
+		app.render(w, r, status, "twofactor.go.tpl", data)
+		return
+	}
+
+	// 암호화 키 없이 시작한 서버에서는 새로 켤 수 없다는 안내만 보여 줍니다.
+	if !app.totpEnabled {
+		app.render(w, r, status, "twofactor.go.tpl", data)
+		return
+	}
+
+	enable, ok := form.(twoFactorEnableForm)
+	if !ok {
+		secret, err := totp.NewSecret()
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+		enable = twoFactorEnableForm{Secret: totp.EncodeSecret(secret)}
+	}
+	data.Form = enable
+
+	secret, err := totp.DecodeSecret(enable.Secret)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	code, err := qr.Encode(totp.URI(totpIssuer, user.Email, secret))
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+	data.QRCode = template.HTML(code.SVG())
+
+	app.render(w, r, status, "twofactor.go.tpl", data)
+}
+
+func (app *application) twoFactorView(w http.ResponseWriter, r *http.Request) {
+	user, err := app.currentUser(r)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.renderTwoFactor(w, r, http.StatusOK, user, nil)
+}
+
+// twoFactorEnablePost는 인증 앱이 만든 코드로 비밀 키를 확인한 뒤 2단계 인증을 켜고
+// 복구 코드를 한 번만 보여 줍니다.
+func (app *application) twoFactorEnablePost(w http.ResponseWriter, r *http.Request) {
+	user, err := app.currentUser(r)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	if !app.totpEnabled {
+		app.notFound(w, r)
+		return
+	}
+
+	var form twoFactorEnableForm
+
+	err = app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	secret, err := totp.DecodeSecret(form.Secret)
+	if err != nil || len(secret) != totp.SecretSize {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Code), "code", "This field cannot be blank")
+	form.CheckField(validator.NotBlank(form.Password), "password", "This field cannot be blank")
+
+	var step int64
+	if form.Valid() {
+		var ok bool
+		step, ok = totp.Validate(secret, form.Code, time.Now())
+		if !ok {
+			form.AddFieldError("code", "Invalid authentication code")
+		}
+
+		err = app.checkPassword(user, form.Password)
+		if errors.Is(err, models.ErrInvalidCredentials) {
+			form.AddFieldError("password", "Password is incorrect")
+		} else if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	if !form.Valid() {
+		app.renderTwoFactor(w, r, http.StatusUnprocessableEntity, user, form)
+		return
+	}
+
+	codes, err := newRecoveryCodes()
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	err = app.twoFactor.Enable(user.ID, secret, step, codes)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	err = app.renewSessionToken(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.User = user
+	data.RecoveryCodes = codes
+	app.render(w, r, http.StatusOK, "twofactor.go.tpl", data)
+}
+
+func (app *application) twoFactorDisablePost(w http.ResponseWriter, r *http.Request) {
+	user, err := app.currentUser(r)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	var form twoFactorDisableForm
+
+	err = app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Password), "password", "This field cannot be blank")
+
+	if form.Valid() {
+		err = app.checkPassword(user, form.Password)
+		if errors.Is(err, models.ErrInvalidCredentials) {
+			form.AddFieldError("password", "Password is incorrect")
+		} else if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	if !form.Valid() {
+		app.renderTwoFactor(w, r, http.StatusUnprocessableEntity, user, form)
+		return
+	}
+
+	err = app.twoFactor.Disable(user.ID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	err = app.renewSessionToken(r.Context())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Two-factor authentication has been turned off.")
+
+	http.Redirect(w, r, "/account/2fa", http.StatusSeeOther)
+}
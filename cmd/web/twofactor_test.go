@@ -0,0 +1,339 @@
+package main
+
+import (
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"snippetbox.wook.net/internal/assert"
+	"snippetbox.wook.net/internal/models"
+	"snippetbox.wook.net/internal/models/mocks"
+	"snippetbox.wook.net/internal/totp"
+)
+
+// loginCarol은 2단계 인증을 사용하는 사용자 4의 비밀번호 단계를 마치고 CSRF 토큰을 반환합니다.
+func (ts *testServer) loginCarol(t *testing.T) string {
+	_, _, body := ts.get(t, "/user/login")
+	csrfToken := extractCSRFToken(t, body)
+
+	form := url.Values{}
+	form.Add("email", "carol@example.com")
+	form.Add("password", "pa$$word")
+	form.Add("csrf_token", csrfToken)
+
+	code, header, _ := ts.postForm(t, "/user/login", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/user/login/2fa")
+
+	return csrfToken
+}
+
+func TestUserLoginTwoFactor(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	// 비밀번호 단계 없이는 코드 입력 페이지를 볼 수 없습니다.
+	code, header, _ := ts.get(t, "/user/login/2fa")
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/user/login")
+
+	csrfToken := ts.loginCarol(t)
+
+	code, _, body := ts.get(t, "/user/login/2fa")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<form action='/user/login/2fa' method='POST' novalidate>")
+
+	// 코드를 확인하기 전에는 로그인 상태가 아닙니다.
+	code, _, _ = ts.get(t, "/account")
+	assert.Equal(t, code, http.StatusSeeOther)
+
+	tests := []struct {
+		name      string
+		code      string
+		wantCode  int
+		wantError string
+	}{
+		{"Empty code", "", http.StatusUnprocessableEntity, "This field cannot be blank"},
+		{"Old code", totp.Code(mocks.MockTOTPSecret, totp.Step(time.Now())-5), http.StatusUnprocessableEntity, "Invalid authentication code"},
+		{"Wrong recovery code", "zzzzz-zzzzz", http.StatusUnprocessableEntity, "Invalid authentication code"},
+		{"Valid code", totp.Code(mocks.MockTOTPSecret, totp.Step(time.Now())), http.StatusSeeOther, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("code", tt.code)
+			form.Add("csrf_token", csrfToken)
+
+			code, header, body := ts.postForm(t, "/user/login/2fa", form)
+			assert.Equal(t, code, tt.wantCode)
+			if tt.wantError != "" {
+				assert.StringContains(t, body, tt.wantError)
+			} else {
+				assert.Equal(t, header.Get("Location"), "/snippet/create")
+			}
+		})
+	}
+
+	code, _, body = ts.get(t, "/account")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "<td>carol@example.com</td>")
+}
+
+func TestUserLoginTwoFactorRecoveryCode(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	csrfToken := ts.loginCarol(t)
+
+	form := url.Values{}
+	form.Add("code", "ABCDE FGHIJ")
+	form.Add("csrf_token", csrfToken)
+
+	code, header, _ := ts.postForm(t, "/user/login/2fa", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/snippet/create")
+
+	_, _, body := ts.get(t, "/account")
+	assert.StringContains(t, body, "You&#39;ve used one of your recovery codes.")
+}
+
+// unreadableTwoFactorModel은 암호화 키가 바뀌어 비밀 키를 복호화할 수 없는 상황을 흉내 냅니다.
+type unreadableTwoFactorModel struct {
+	mocks.TwoFactorModel
+}
+
+func (m *unreadableTwoFactorModel) Secret(userID int) ([]byte, error) {
+	return nil, models.ErrUnreadableSecret
+}
+
+func TestUserLoginTwoFactorUserLimit(t *testing.T) {
+	app := newTestApplication(t)
+	app.totpLimiter = newRateLimiter(2, time.Minute)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	csrfToken := ts.loginCarol(t)
+
+	form := url.Values{}
+	form.Add("code", "000000")
+	form.Add("csrf_token", csrfToken)
+
+	for i := 0; i < 2; i++ {
+		code, _, _ := ts.postForm(t, "/user/login/2fa", form)
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+	}
+
+	// 한도는 로그인마다가 아니라 사용자마다 적용되므로, 비밀번호를 다시 입력해도 맞는 코드를
+	// 받지 않습니다.
+	ts.loginCarol(t)
+
+	form.Set("code", totp.Code(mocks.MockTOTPSecret, totp.Step(time.Now())))
+	code, header, body := ts.postForm(t, "/user/login/2fa", form)
+	assert.Equal(t, code, http.StatusTooManyRequests)
+	assert.StringContains(t, body, "Too many attempts. Please try again later.")
+	assert.Equal(t, header.Get("Retry-After") != "", true)
+
+	code, _, _ = ts.get(t, "/account")
+	assert.Equal(t, code, http.StatusSeeOther)
+}
+
+func TestUserLoginTwoFactorUnreadableSecret(t *testing.T) {
+	app := newTestApplication(t)
+	app.twoFactor = &unreadableTwoFactorModel{}
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	csrfToken := ts.loginCarol(t)
+
+	form := url.Values{}
+	form.Add("code", totp.Code(mocks.MockTOTPSecret, totp.Step(time.Now())))
+	form.Add("csrf_token", csrfToken)
+
+	code, _, body := ts.postForm(t, "/user/login/2fa", form)
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+	assert.StringContains(t, body, "Please use one of your recovery codes.")
+
+	// 복구 코드로는 로그인할 수 있습니다.
+	form.Set("code", "abcde-fghij")
+	code, header, _ := ts.postForm(t, "/user/login/2fa", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/snippet/create")
+}
+
+func TestUserLoginTwoFactorAttempts(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	csrfToken := ts.loginCarol(t)
+
+	form := url.Values{}
+	form.Add("code", "000000")
+	form.Add("csrf_token", csrfToken)
+
+	for i := 1; i < twoFactorMaxAttempts; i++ {
+		code, _, _ := ts.postForm(t, "/user/login/2fa", form)
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+	}
+
+	// 마지막 시도가 틀리면 비밀번호부터 다시 입력해야 하며, 맞는 코드도 더는 받지 않습니다.
+	code, header, _ := ts.postForm(t, "/user/login/2fa", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/user/login")
+
+	form.Set("code", totp.Code(mocks.MockTOTPSecret, totp.Step(time.Now())))
+	code, header, _ = ts.postForm(t, "/user/login/2fa", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/user/login")
+
+	code, _, _ = ts.get(t, "/account")
+	assert.Equal(t, code, http.StatusSeeOther)
+}
+
+var secretRX = regexp.MustCompile(`<input type='hidden' name='secret' value='([A-Z2-7]+)'>`)
+
+// enablingTwoFactorModel은 Enable에 전달된 시간 단계를 기록합니다.
+type enablingTwoFactorModel struct {
+	mocks.TwoFactorModel
+	step int64
+}
+
+func (m *enablingTwoFactorModel) Enable(userID int, secret []byte, step int64, recoveryCodes []string) error {
+	m.step = step
+	return nil
+}
+
+func TestTwoFactorEnable(t *testing.T) {
+	app := newTestApplication(t)
+	m := &enablingTwoFactorModel{}
+	app.twoFactor = m
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	csrfToken := ts.login(t)
+
+	code, _, body := ts.get(t, "/account/2fa")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, `<div class='qrcode'><svg xmlns="http://www.w3.org/2000/svg"`)
+
+	matches := secretRX.FindStringSubmatch(body)
+	if matches == nil {
+		t.Fatal("no secret found in body")
+	}
+	secret, err := totp.DecodeSecret(html.UnescapeString(matches[1]))
+	assert.NilError(t, err)
+
+	step := totp.Step(time.Now())
+
+	tests := []struct {
+		name      string
+		secret    string
+		code      string
+		password  string
+		wantCode  int
+		wantError string
+	}{
+		{"Invalid secret", "not-a-secret", "123456", "pa$$word", http.StatusBadRequest, ""},
+		{"Wrong code", matches[1], "000000", "pa$$word", http.StatusUnprocessableEntity, "Invalid authentication code"},
+		{"Wrong password", matches[1], totp.Code(secret, step), "wrongPa$$word", http.StatusUnprocessableEntity, "Password is incorrect"},
+		{"Valid submission", matches[1], totp.Code(secret, step), "pa$$word", http.StatusOK, "Save these recovery codes somewhere safe."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("secret", tt.secret)
+			form.Add("code", tt.code)
+			form.Add("password", tt.password)
+			form.Add("csrf_token", csrfToken)
+
+			code, _, body := ts.postForm(t, "/account/2fa", form)
+			assert.Equal(t, code, tt.wantCode)
+			if tt.wantError != "" {
+				assert.StringContains(t, body, tt.wantError)
+			}
+			if tt.wantCode == http.StatusUnprocessableEntity {
+				// 다시 표시한 양식은 같은 비밀 키를 유지합니다.
+				assert.StringContains(t, body, "value='"+tt.secret+"'")
+			}
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, len(regexp.MustCompile(`[a-z2-9]{5}-[a-z2-9]{5}`).FindAllString(body, -1)), recoveryCodeCount)
+			}
+		})
+	}
+
+	// 등록을 확인한 코드는 사용한 코드로 기록되어 로그인에 다시 쓸 수 없습니다.
+	assert.Equal(t, m.step, step)
+}
+
+func TestTwoFactorEnableUnavailable(t *testing.T) {
+	app := newTestApplication(t)
+	app.totpEnabled = false
+	m := &enablingTwoFactorModel{}
+	app.twoFactor = m
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	csrfToken := ts.login(t)
+
+	code, _, body := ts.get(t, "/account")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "Two-factor authentication isn't available on this server.")
+
+	code, _, body = ts.get(t, "/account/2fa")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "Two-factor authentication isn't available on this server.")
+	if secretRX.MatchString(body) {
+		t.Error("body offers a secret")
+	}
+
+	secret, err := totp.NewSecret()
+	assert.NilError(t, err)
+
+	form := url.Values{}
+	form.Add("secret", totp.EncodeSecret(secret))
+	form.Add("code", totp.Code(secret, totp.Step(time.Now())))
+	form.Add("password", "pa$$word")
+	form.Add("csrf_token", csrfToken)
+
+	code, _, _ = ts.postForm(t, "/account/2fa", form)
+	assert.Equal(t, code, http.StatusNotFound)
+	assert.Equal(t, m.step, 0)
+}
+
+func TestTwoFactorDisable(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	csrfToken := ts.loginCarol(t)
+
+	form := url.Values{}
+	form.Add("code", totp.Code(mocks.MockTOTPSecret, totp.Step(time.Now())))
+	form.Add("csrf_token", csrfToken)
+	ts.postForm(t, "/user/login/2fa", form)
+
+	code, _, body := ts.get(t, "/account/2fa")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "Two-factor authentication is on since 03 Jan 2022 at 09:00. You have 1 recovery codes left.")
+
+	form = url.Values{}
+	form.Add("password", "wrongPa$$word")
+	form.Add("csrf_token", csrfToken)
+
+	code, _, body = ts.postForm(t, "/account/2fa/disable", form)
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+	assert.StringContains(t, body, "Password is incorrect")
+
+	form.Set("password", "pa$$word")
+
+	code, header, _ := ts.postForm(t, "/account/2fa/disable", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, header.Get("Location"), "/account/2fa")
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"snippetbox.wook.net/internal/models"
+	"snippetbox.wook.net/internal/validator"
+)
+
+// verificationTTL은 이메일 인증 링크의 유효 기간입니다.
+const verificationTTL = 24 * time.Hour
+
+type resendVerificationForm struct {
+	Email               string `form:"email"`
+	validator.Validator `form:"-"`
+}
+
+// linkMAC은 메일로 보내는 링크 토큰의 서명을 계산합니다. purpose가 다르면 서명도 달라지므로
+// 한 용도의 토큰을 다른 용도로 쓸 수 없습니다. values에는 토큰이 유효한 동안 바뀌지 않아야
+// 하는 값을 넣습니다. 예를 들어 이메일 주소를 넣으면 링크를 보낸 뒤 주소가 바뀌었을 때
+// 이전 링크는 더 이상 쓸 수 없습니다.
+func (app *application) linkMAC(purpose string, userID int, expires int64, values ...string) []byte {
+	mac := hmac.New(sha256.New, app.secret)
+	fmt.Fprintf(mac, "%s\n%d", purpose, userID)
+	for _, v := range values {
+		fmt.Fprintf(mac, "\n%s", v)
+	}
+	fmt.Fprintf(mac, "\n%d", expires)
+	return mac.Sum(nil)
+}
+
+// newVerificationToken은 "<사용자 ID>.<만료 시각>.<서명>" 형식의 인증 토큰을 만듭니다.
+// 토큰은 데이터베이스에 저장하지 않으며 app.secret으로 서명하여 위조를 막습니다.
+func (app *application) newVerificationToken(userID int, email string, now time.Time) string {
+	expires := now.Add(verificationTTL).Unix()
+	sig := app.linkMAC("verify-email", userID, expires, email)
+
+	return fmt.Sprintf("%d.%d.%s", userID, expires, base64.RawURLEncoding.EncodeToString(sig))
+}
+
+// checkVerificationToken은 토큰의 서명과 만료 시각을 확인하고 인증할 사용자를 반환합니다.
+// 토큰이 올바르지 않으면 models.ErrNoRecord를 반환합니다.
+func (app *application) checkVerificationToken(token string, now time.Time) (*models.User, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, models.ErrNoRecord
+	}
+
+	userID, err := strconv.Atoi(parts[0])
+	if err != nil || userID < 1 {
+		return nil, models.ErrNoRecord
+	}
+
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() > expires {
+		return nil, models.ErrNoRecord
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, models.ErrNoRecord
+	}
+
+	user, err := app.users.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hmac.Equal(sig, app.linkMAC("verify-email", user.ID, expires, user.Email)) {
+		return nil, models.ErrNoRecord
+	}
+
+	return user, nil
+}
+
+// sendVerification은 이메일 주소 인증 링크를 메일로 보냅니다. 메일을 대기열에 넣지 못하더라도
+// 사용자는 인증 메일을 다시 요청할 수 있으므로 요청을 실패시키지 않고 오류만 기록합니다.
+// -base-url 플래그가 없을 때도 마찬가지로 메일을 보내지 않고 기록만 합니다.
+func (app *application) sendVerification(user *models.User) {
+	link, err := app.mailURL("/user/verify?token=")
+	if err != nil {
+		app.errorLog.Print(err)
+		return
+	}
+
+	token := app.newVerificationToken(user.ID, user.Email, time.Now())
+
+	err = app.sendEmail(user.Email, "verify.tmpl", map[string]string{
+		"Name": user.Name,
+		"Link": link + url.QueryEscape(token),
+	})
+	if err != nil {
+		app.errorLog.Print(err)
+	}
+}
+
+func (app *application) userVerify(w http.ResponseWriter, r *http.Request) {
+	user, err := app.checkVerificationToken(r.URL.Query().Get("token"), time.Now())
+	if err == nil {
+		err = app.users.Verify(user.ID, user.Email)
+	}
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			form := resendVerificationForm{}
+			form.AddNonFieldError("This verification link is invalid or has expired. Enter your email address to get a new one.")
+
+			data := app.newTemplateData(r)
+			data.Form = form
+			app.render(w, r, http.StatusBadRequest, "verify.go.tpl", data)
+		} else {
+			app.serverError(w, r, err)
+		}
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "Your email address has been verified. Please log in.")
+
+	http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+}
+
+func (app *application) userVerifyResend(w http.ResponseWriter, r *http.Request) {
+	data := app.newTemplateData(r)
+	data.Form = resendVerificationForm{}
+	app.render(w, r, http.StatusOK, "verify.go.tpl", data)
+}
+
+func (app *application) userVerifyResendPost(w http.ResponseWriter, r *http.Request) {
+	var form resendVerificationForm
+
+	err := app.decodePostForm(r, &form)
+	if err != nil {
+		app.clientError(w, r, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Email), "email", "This field cannot be blank")
+	form.CheckField(validator.Matches(form.Email, validator.EmailRX), "email", "This field must be a valid email address")
+
+	if !form.Valid() {
+		data := app.newTemplateData(r)
+		data.Form = form
+		app.render(w, r, http.StatusUnprocessableEntity, "verify.go.tpl", data)
+		return
+	}
+
+	// 어떤 주소로 가입했는지 알아낼 수 없도록 계정이 없거나 이미 인증된 경우에도
+	// 같은 응답을 보냅니다.
+	user, err := app.users.GetByEmail(form.Email)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.serverError(w, r, err)
+		return
+	}
+	if err == nil && !user.Verified {
+		app.sendVerification(user)
+	}
+
+	app.sessionManager.Put(r.Context(), "flash", "If that address belongs to an unverified account, we've sent a new verification link to it.")
+
+	http.Redirect(w, r, "/user/login", http.StatusSeeOther)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"snippetbox.wook.net/internal/assert"
+)
+
+var verifyLinkRX = regexp.MustCompile(`https://\S+/user/verify\?token=\S+`)
+
+func TestUserVerify(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	now := time.Now()
+	valid := app.newVerificationToken(3, "unverified@example.com", now)
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode int
+	}{
+		{
+			name:     "Valid token",
+			token:    valid,
+			wantCode: http.StatusSeeOther,
+		},
+		{
+			name:     "Expired token",
+			token:    app.newVerificationToken(3, "unverified@example.com", now.Add(-verificationTTL-time.Minute)),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Changed email",
+			token:    app.newVerificationToken(3, "old@example.com", now),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Other user",
+			token:    "1" + strings.TrimPrefix(valid, "3"),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Non-existent user",
+			token:    app.newVerificationToken(4, "unverified@example.com", now),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Malformed token",
+			token:    "not-a-token",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Empty token",
+			token:    "",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, header, body := ts.get(t, "/user/verify?token="+url.QueryEscape(tt.token))
+			assert.Equal(t, code, tt.wantCode)
+
+			if tt.wantCode == http.StatusSeeOther {
+				assert.Equal(t, header.Get("Location"), "/user/login")
+			} else {
+				assert.StringContains(t, body, "This verification link is invalid or has expired.")
+			}
+		})
+	}
+}
+
+func TestUserLoginUnverified(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	_, _, body := ts.get(t, "/user/login")
+	assert.StringContains(t, body, "<a href='/user/verify/resend'>")
+
+	form := url.Values{}
+	form.Add("email", "unverified@example.com")
+	form.Add("password", "pa$$word")
+	form.Add("csrf_token", extractCSRFToken(t, body))
+
+	code, _, body := ts.postForm(t, "/user/login", form)
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+	assert.StringContains(t, body, "Please verify your email address before logging in")
+}
+
+func TestUserVerifyResend(t *testing.T) {
+	app := newTestApplication(t)
+	outbox := newRecordingOutboxModel()
+	app.outbox = outbox
+	app.baseURL = "https://snippets.example.com"
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	code, _, body := ts.get(t, "/user/verify/resend")
+	assert.Equal(t, code, http.StatusOK)
+	csrfToken := extractCSRFToken(t, body)
+
+	tests := []struct {
+		name     string
+		email    string
+		wantCode int
+	}{
+		{"Unverified account", "unverified@example.com", http.StatusSeeOther},
+		{"Verified account", "alice@example.com", http.StatusSeeOther},
+		{"Unknown account", "nobody@example.com", http.StatusSeeOther},
+		{"Invalid email", "nobody@example.", http.StatusUnprocessableEntity},
+		{"Empty email", "", http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("email", tt.email)
+			form.Add("csrf_token", csrfToken)
+
+			code, header, _ := ts.postForm(t, "/user/verify/resend", form)
+			assert.Equal(t, code, tt.wantCode)
+			if tt.wantCode == http.StatusSeeOther {
+				assert.Equal(t, header.Get("Location"), "/user/login")
+			}
+		})
+	}
+
+	// 인증되지 않은 계정에만 메일을 보내고, 메일의 링크로 인증할 수 있어야 합니다.
+	assert.Equal(t, len(outbox.enqueued), 1)
+	assert.Equal(t, outbox.enqueued[0].To, "unverified@example.com")
+
+	link := verifyLinkRX.FindString(outbox.enqueued[0].TextBody)
+	assert.StringContains(t, link, "https://snippets.example.com/user/verify?token=")
+
+	code, _, _ = ts.get(t, strings.TrimPrefix(link, "https://snippets.example.com"))
+	assert.Equal(t, code, http.StatusSeeOther)
+}
+
+// TestUserVerifyResendNoBaseURL은 -base-url 플래그가 없으면 요청의 Host 헤더로 링크를
+// 만들지 않고 메일을 보내지 않는지 확인합니다.
+func TestUserVerifyResendNoBaseURL(t *testing.T) {
+	app := newTestApplication(t)
+	outbox := newRecordingOutboxModel()
+	app.outbox = outbox
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	_, _, body := ts.get(t, "/user/verify/resend")
+
+	form := url.Values{}
+	form.Add("email", "unverified@example.com")
+	form.Add("csrf_token", extractCSRFToken(t, body))
+
+	code, _, _ := ts.postForm(t, "/user/verify/resend", form)
+	assert.Equal(t, code, http.StatusSeeOther)
+	assert.Equal(t, len(outbox.enqueued), 0)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"sync"
+
+	"snippetbox.wook.net/internal/models"
+)
+
+// viewCounter는 스니펫 조회수를 메모리에 모아 두었다가 주기적으로 한 번에
+// 데이터베이스에 기록합니다. 덕분에 snippetView 핸들러는 UPDATE 문을 실행하지 않습니다.
+type viewCounter struct {
+	mu       sync.Mutex
+	counts   map[int]int
+	snippets models.SnippetModelInterface
+}
+
+func newViewCounter(snippets models.SnippetModelInterface) *viewCounter {
+	return &viewCounter{
+		counts:   make(map[int]int),
+		snippets: snippets,
+	}
+}
+
+// Inc는 주어진 스니펫의 대기 중인 조회수를 1 증가시킵니다.
+func (vc *viewCounter) Inc(id int) {
+	vc.mu.Lock()
+	vc.counts[id]++
+	vc.mu.Unlock()
+}
+
+// Pending은 아직 데이터베이스에 기록되지 않은 조회수를 반환합니다.
+func (vc *viewCounter) Pending(id int) int {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	return vc.counts[id]
+}
+
+// Flush는 대기 중인 조회수를 새 맵으로 교체한 뒤 데이터베이스에 기록합니다.
+// 기록에 실패하면 다음 플러시에서 다시 시도할 수 있도록 조회수를 되돌려 놓습니다.
+func (vc *viewCounter) Flush() error {
+	vc.mu.Lock()
+	counts := vc.counts
+	vc.counts = make(map[int]int)
+	vc.mu.Unlock()
+
+	if len(counts) == 0 {
+		return nil
+	}
+
+	err := vc.snippets.AddViews(counts)
+	if err != nil {
+		vc.mu.Lock()
+		for id, n := range counts {
+			vc.counts[id] += n
+		}
+		vc.mu.Unlock()
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"snippetbox.wook.net/internal/assert"
+	"snippetbox.wook.net/internal/models/mocks"
+)
+
+// recordingSnippetModel은 AddViews()에 전달된 조회수를 기록하는 모의 모델입니다.
+// fail이 true이면 AddViews()는 오류를 반환합니다.
+type recordingSnippetModel struct {
+	mocks.SnippetModel
+	flushed map[int]int
+	calls   int
+	fail    bool
+}
+
+func (m *recordingSnippetModel) AddViews(counts map[int]int) error {
+	m.calls++
+	if m.fail {
+		return errors.New("flush failed")
+	}
+	if m.flushed == nil {
+		m.flushed = make(map[int]int)
+	}
+	for id, n := range counts {
+		m.flushed[id] += n
+	}
+	return nil
+}
+
+func TestViewCounterFlush(t *testing.T) {
+	m := &recordingSnippetModel{}
+	vc := newViewCounter(m)
+
+	vc.Inc(1)
+	vc.Inc(1)
+	vc.Inc(2)
+	assert.Equal(t, vc.Pending(1), 2)
+
+	assert.NilError(t, vc.Flush())
+	assert.Equal(t, m.calls, 1)
+	assert.Equal(t, m.flushed[1], 2)
+	assert.Equal(t, m.flushed[2], 1)
+	assert.Equal(t, vc.Pending(1), 0)
+
+	// 대기 중인 조회수가 없으면 데이터베이스를 호출하지 않아야 합니다.
+	assert.NilError(t, vc.Flush())
+	assert.Equal(t, m.calls, 1)
+}
+
+func TestViewCounterFlushError(t *testing.T) {
+	m := &recordingSnippetModel{fail: true}
+	vc := newViewCounter(m)
+
+	vc.Inc(1)
+
+	if err := vc.Flush(); err == nil {
+		t.Fatal("expected an error")
+	}
+	// 기록에 실패한 조회수는 버려지지 않고 다음 플러시를 기다려야 합니다.
+	assert.Equal(t, vc.Pending(1), 1)
+
+	m.fail = false
+	assert.NilError(t, vc.Flush())
+	assert.Equal(t, m.flushed[1], 1)
+}
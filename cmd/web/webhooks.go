@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"snippetbox.wook.net/internal/models"
+)
+
+const (
+	// 한 번의 Deliver 호출에서 처리하는 최대 전달 항목 수입니다.
+	webhookBatchSize = 50
+	// 이 횟수만큼 실패하면 더 이상 다시 시도하지 않습니다.
+	webhookMaxAttempts = 8
+	// 첫 번째 재시도까지의 대기 시간이며, 실패할 때마다 두 배로 늘어납니다.
+	webhookBaseDelay = 30 * time.Second
+	webhookMaxDelay  = 6 * time.Hour
+)
+
+// errPrivateAddress는 웹훅 수신 주소가 공인 IP 주소가 아닐 때 반환됩니다.
+var errPrivateAddress = errors.New("webhook receiver is not a public address")
+
+// webhookDispatcher는 데이터베이스의 전달 대기열에서 때가 된 항목을 꺼내 서명한 뒤
+// 수신 URL로 POST합니다. 실패한 항목은 지수적으로 늘어나는 간격을 두고 다시 시도합니다.
+type webhookDispatcher struct {
+	webhooks models.WebhookModelInterface
+	client   *http.Client
+}
+
+func newWebhookDispatcher(webhooks models.WebhookModelInterface) *webhookDispatcher {
+	return &webhookDispatcher{
+		webhooks: webhooks,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			// 사용자가 등록한 URL로 내부 서비스에 요청을 보내지 못하도록 공인 IP 주소에만 연결합니다.
+			// 이름 풀이가 끝난 뒤 실제로 연결할 주소를 확인하므로 DNS 리바인딩으로도 우회할 수 없습니다.
+			// 프록시를 거치면 프록시의 주소만 확인하게 되므로 환경 변수의 프록시 설정은 사용하지 않습니다.
+			Transport: &http.Transport{
+				DialContext: (&net.Dialer{
+					Timeout: 5 * time.Second,
+					Control: publicOnlyControl,
+				}).DialContext,
+				TLSHandshakeTimeout: 5 * time.Second,
+				MaxIdleConns:        10,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			// 리디렉션은 따라가지 않고 실패로 처리합니다. 서명한 본문이 다른 곳으로 보내지는 것을 막습니다.
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// publicOnlyControl은 net.Dialer.Control로 사용하며, 공인 IP 주소가 아닌 곳으로의 연결을 거부합니다.
+func publicOnlyControl(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", errPrivateAddress, host)
+	}
+
+	return nil
+}
+
+// isPublicIP는 ip가 루프백, 사설, 링크 로컬, 멀티캐스트 또는 지정되지 않은 주소가 아니면
+// true를 반환합니다.
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() ||
+		thisNetwork.Contains(ip) || sharedAddressSpace.Contains(ip))
+}
+
+var (
+	// thisNetwork는 "이 네트워크"를 뜻하는 0.0.0.0/8 대역(RFC 1122)으로, 리눅스에서는 로컬 호스트로 연결됩니다.
+	thisNetwork = &net.IPNet{IP: net.IPv4(0, 0, 0, 0), Mask: net.CIDRMask(8, 32)}
+	// sharedAddressSpace는 통신사 NAT에서 사용하는 100.64.0.0/10 대역(RFC 6598)입니다.
+	sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+)
+
+// privateWebhookHost는 rawURL의 호스트가 localhost이거나 공인 IP 주소가 아닌 IP 주소이면
+// true를 반환합니다. 호스트 이름은 풀이하지 않으므로 실제 확인은 publicOnlyControl이 합니다.
+func privateWebhookHost(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && !isPublicIP(ip)
+}
+
+// signWebhook은 payload의 HMAC-SHA256 서명을 "sha256=<hex>" 형식으로 반환합니다.
+// 수신자는 같은 비밀 값으로 본문을 서명하여 X-Snippetbox-Signature-256 헤더와 비교하면 됩니다.
+func signWebhook(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff는 attempts번째 실패 뒤에 다음 시도까지 기다릴 시간을 반환합니다.
+func webhookBackoff(attempts int) time.Duration {
+	return backoff(webhookBaseDelay, webhookMaxDelay, attempts)
+}
+
+// Deliver는 때가 된 전달 항목을 처리합니다. runPeriodically와 함께 사용합니다.
+// 수신자의 오류는 전달 기록에 남기고, 데이터베이스 오류만 반환합니다.
+func (wd *webhookDispatcher) Deliver() error {
+	deliveries, err := wd.webhooks.Due(webhookBatchSize)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, d := range deliveries {
+		status, err := wd.send(d)
+		if err == nil {
+			err = wd.webhooks.MarkDelivered(d.ID, status)
+		} else {
+			attempts := d.Attempts + 1
+			next := time.Now().Add(webhookBackoff(attempts))
+			err = wd.webhooks.MarkFailed(d.ID, status, err.Error(), next, attempts >= webhookMaxAttempts)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// send는 전달 항목 하나를 POST하고 응답 상태 코드를 반환합니다. 2xx가 아닌 응답은 오류입니다.
+func (wd *webhookDispatcher) send(d *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Snippetbox-Webhook/1.0")
+	req.Header.Set("X-Snippetbox-Event", d.Event)
+	req.Header.Set("X-Snippetbox-Delivery", strconv.Itoa(d.ID))
+	req.Header.Set("X-Snippetbox-Signature-256", signWebhook(d.Secret, d.Payload))
+
+	rs, err := wd.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer rs.Body.Close()
+
+	// 연결을 재사용할 수 있도록 응답 본문을 조금 읽고 버립니다.
+	io.Copy(io.Discard, io.LimitReader(rs.Body, 4096))
+
+	if rs.StatusCode < 200 || rs.StatusCode > 299 {
+		return rs.StatusCode, fmt.Errorf("receiver responded with %s", rs.Status)
+	}
+
+	return rs.StatusCode, nil
+}
+
+// snippetEvent는 스니펫 이벤트를 웹훅 전달 대기열에 넣습니다. 대기열에 넣지 못하더라도
+// 스니펫 변경은 이미 끝났으므로 요청을 실패시키지 않고 오류만 기록합니다.
+func (app *application) snippetEvent(event string, snippet *models.Snippet) {
+	err := app.webhooks.Enqueue(event, snippet)
+	if err != nil {
+		app.errorLog.Print(err)
+	}
+}
+
+// enqueueExpiredSnippets는 만료된 스니펫의 이벤트를 대기열에 넣습니다. runPeriodically와 함께 사용합니다.
+func (app *application) enqueueExpiredSnippets() error {
+	_, err := app.webhooks.EnqueueExpired()
+	return err
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"snippetbox.wook.net/internal/assert"
+	"snippetbox.wook.net/internal/models"
+	"snippetbox.wook.net/internal/models/mocks"
+)
+
+type deliveryResult struct {
+	status  int
+	message string
+	next    time.Time
+	giveUp  bool
+}
+
+// recordingWebhookModel은 due에 담긴 전달 항목을 반환하고 그 결과를 기록하는 모의 모델입니다.
+type recordingWebhookModel struct {
+	mocks.WebhookModel
+	due       []*models.WebhookDelivery
+	delivered map[int]int
+	failed    map[int]deliveryResult
+	enqueued  []string
+}
+
+func (m *recordingWebhookModel) Due(limit int) ([]*models.WebhookDelivery, error) {
+	return m.due, nil
+}
+
+func (m *recordingWebhookModel) MarkDelivered(id, status int) error {
+	m.delivered[id] = status
+	return nil
+}
+
+func (m *recordingWebhookModel) MarkFailed(id, status int, message string, next time.Time, giveUp bool) error {
+	m.failed[id] = deliveryResult{status: status, message: message, next: next, giveUp: giveUp}
+	return nil
+}
+
+func (m *recordingWebhookModel) Enqueue(event string, snippet *models.Snippet) error {
+	m.enqueued = append(m.enqueued, event)
+	return nil
+}
+
+func newRecordingWebhookModel(due ...*models.WebhookDelivery) *recordingWebhookModel {
+	return &recordingWebhookModel{
+		due:       due,
+		delivered: make(map[int]int),
+		failed:    make(map[int]deliveryResult),
+	}
+}
+
+func TestWebhookDispatcherDeliver(t *testing.T) {
+	payload := []byte(`{"event":"snippet.created","snippet":{"id":1}}`)
+
+	var gotSignature, gotEvent, gotBody string
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/ok", http.StatusFound)
+			return
+		}
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		gotEvent = r.Header.Get("X-Snippetbox-Event")
+		gotSignature = r.Header.Get("X-Snippetbox-Signature-256")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	m := newRecordingWebhookModel(
+		&models.WebhookDelivery{ID: 1, URL: receiver.URL + "/ok", Secret: "s3cret", Event: models.EventSnippetCreated, Payload: payload},
+		&models.WebhookDelivery{ID: 2, URL: receiver.URL + "/broken", Secret: "s3cret", Event: models.EventSnippetCreated, Payload: payload, Attempts: 2},
+		&models.WebhookDelivery{ID: 3, URL: receiver.URL + "/broken", Secret: "s3cret", Event: models.EventSnippetCreated, Payload: payload, Attempts: webhookMaxAttempts - 1},
+		&models.WebhookDelivery{ID: 4, URL: receiver.URL + "/redirect", Secret: "s3cret", Event: models.EventSnippetCreated, Payload: payload},
+	)
+
+	start := time.Now()
+
+	// 테스트 수신 서버는 루프백 주소에서 실행되므로 주소를 확인하지 않는 전송 계층을 사용합니다.
+	wd := newWebhookDispatcher(m)
+	wd.client.Transport = http.DefaultTransport
+
+	err := wd.Deliver()
+	assert.NilError(t, err)
+
+	assert.Equal(t, m.delivered[1], http.StatusNoContent)
+	assert.Equal(t, gotBody, string(payload))
+	assert.Equal(t, gotEvent, models.EventSnippetCreated)
+	assert.Equal(t, gotSignature, signWebhook("s3cret", payload))
+
+	// 세 번째 시도가 실패했으므로 2분 뒤에 다시 시도합니다.
+	retry := m.failed[2]
+	assert.Equal(t, retry.status, http.StatusBadGateway)
+	assert.Equal(t, retry.giveUp, false)
+	assert.Equal(t, retry.next.Sub(start).Round(time.Minute), 2*time.Minute)
+	assert.StringContains(t, retry.message, "502 Bad Gateway")
+
+	assert.Equal(t, m.failed[3].giveUp, true)
+
+	// 리디렉션은 따라가지 않고 실패로 기록합니다.
+	assert.Equal(t, m.failed[4].status, http.StatusFound)
+	assert.Equal(t, len(m.delivered), 1)
+}
+
+func TestWebhookDispatcherPrivateAddress(t *testing.T) {
+	var called bool
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	m := newRecordingWebhookModel(
+		&models.WebhookDelivery{ID: 1, URL: receiver.URL, Secret: "s3cret", Event: models.EventSnippetCreated, Payload: []byte("{}")},
+	)
+
+	err := newWebhookDispatcher(m).Deliver()
+	assert.NilError(t, err)
+
+	assert.Equal(t, called, false)
+	assert.Equal(t, m.failed[1].status, 0)
+	assert.StringContains(t, m.failed[1].message, "webhook receiver is not a public address: 127.0.0.1")
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"::", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, isPublicIP(net.ParseIP(tt.ip)), tt.want)
+		})
+	}
+}
+
+func TestSignWebhook(t *testing.T) {
+	// echo -n 'hello' | openssl dgst -sha256 -hmac 'key'
+	want := "sha256=9307b3b915efb5171ff14d8cb55fbcc798c6c0ef1456d66ded1a6aa723a58b7b"
+	assert.Equal(t, signWebhook("key", []byte("hello")), want)
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: time.Minute},
+		{attempts: 5, want: 8 * time.Minute},
+		{attempts: 20, want: webhookMaxDelay},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, webhookBackoff(tt.attempts), tt.want)
+	}
+}
+
+func TestWebhookPages(t *testing.T) {
+	app := newTestApplication(t)
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	csrfToken := ts.login(t)
+
+	code, _, body := ts.get(t, "/account/webhooks")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "https://example.com/hooks/snippetbox")
+
+	code, _, body = ts.get(t, "/account/webhooks/1")
+	assert.Equal(t, code, http.StatusOK)
+	assert.StringContains(t, body, "502 Bad Gateway")
+
+	// 다른 사용자의 웹훅은 보이지 않습니다.
+	code, _, _ = ts.get(t, "/account/webhooks/2")
+	assert.Equal(t, code, http.StatusNotFound)
+
+	tests := []struct {
+		name     string
+		url      string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Valid URL",
+			url:      "https://ci.example.com/snippetbox",
+			wantCode: http.StatusOK,
+			wantBody: "<code>newsecret</code>",
+		},
+		{
+			name:     "Blank URL",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "This field cannot be blank",
+		},
+		{
+			name:     "Not a web URL",
+			url:      "ftp://example.com/hook",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "This field must be an absolute http or https URL",
+		},
+		{
+			name:     "Loopback address",
+			url:      "http://127.0.0.1:8080/hook",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "This URL must point to a public address",
+		},
+		{
+			name:     "Metadata address",
+			url:      "http://169.254.169.254/latest/meta-data",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "This URL must point to a public address",
+		},
+		{
+			name:     "Localhost",
+			url:      "http://localhost/hook",
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "This URL must point to a public address",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("url", tt.url)
+			form.Add("csrf_token", csrfToken)
+
+			code, _, body := ts.postForm(t, "/account/webhooks", form)
+			assert.Equal(t, code, tt.wantCode)
+			assert.StringContains(t, body, tt.wantBody)
+		})
+	}
+}
+
+func TestSnippetEvents(t *testing.T) {
+	app := newTestApplication(t)
+	m := newRecordingWebhookModel()
+	app.webhooks = m
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	header := http.Header{"Authorization": {"Bearer sbx_valid"}, "Content-Type": {"application/json"}}
+
+	ts.request(t, http.MethodPost, "/api/v1/snippets", header, `{"title":"New","content":"Body"}`)
+	ts.request(t, http.MethodPatch, "/api/v1/snippets/1", header, `{"title":"Changed"}`)
+	ts.request(t, http.MethodDelete, "/api/v1/snippets/1", header, "")
+
+	assert.Equal(t, len(m.enqueued), 3)
+	assert.Equal(t, m.enqueued[0], models.EventSnippetCreated)
+	assert.Equal(t, m.enqueued[1], models.EventSnippetUpdated)
+	assert.Equal(t, m.enqueued[2], models.EventSnippetDeleted)
+}
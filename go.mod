@@ -6,12 +6,23 @@ require github.com/go-sql-driver/mysql v1.7.1
 
 require github.com/justinas/alice v1.2.0
 
-require github.com/julienschmidt/httprouter v1.3.0
+require (
+	github.com/alexedwards/scs/mysqlstore v0.0.0-20230327161757-10d4299e3b24
+	github.com/alexedwards/scs/v2 v2.5.1
+	github.com/go-playground/form/v4 v4.2.0
+	github.com/gorilla/websocket v1.5.3
+	github.com/graphql-go/graphql v0.8.1
+	github.com/julienschmidt/httprouter v1.3.0
+	github.com/justinas/nosurf v1.1.1
+	golang.org/x/crypto v0.12.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230822172742-b8732ec3820d
+	google.golang.org/grpc v1.59.0
+	google.golang.org/protobuf v1.31.0
+)
 
 require (
-	github.com/alexedwards/scs/mysqlstore v0.0.0-20230327161757-10d4299e3b24 // indirect
-	github.com/alexedwards/scs/v2 v2.5.1 // indirect
-	github.com/go-playground/form/v4 v4.2.0 // indirect
-	github.com/justinas/nosurf v1.1.1 // indirect
-	golang.org/x/crypto v0.8.0 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	golang.org/x/net v0.14.0 // indirect
+	golang.org/x/sys v0.11.0 // indirect
+	golang.org/x/text v0.12.0 // indirect
 )
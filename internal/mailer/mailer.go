@@ -0,0 +1,66 @@
+// Package mailer는 이메일 메시지를 템플릿에서 만들고 SMTP로 보냅니다.
+package mailer
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	"text/template"
+)
+
+// Message는 수신자 한 명에게 보내는 이메일입니다. HTMLBody가 비어 있으면
+// 일반 텍스트로만 보냅니다.
+type Message struct {
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// Mailer는 메시지를 보내는 방법입니다. Send가 오류를 반환하면 메시지는 보내지지 않은
+// 것으로 보고 나중에 다시 보낼 수 있습니다.
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+// Render는 fsys의 name 템플릿 파일로 to에게 보낼 메시지를 만듭니다. 템플릿 파일에는
+// "subject", "plainBody", "htmlBody" 세 템플릿이 정의되어 있어야 합니다. 제목과 텍스트
+// 본문은 text/template으로, HTML 본문은 html/template으로 실행하므로 HTML 본문의 값만
+// 이스케이프됩니다.
+func Render(fsys fs.FS, name string, to string, data any) (*Message, error) {
+	text, err := template.New("").ParseFS(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := new(bytes.Buffer)
+	err = text.ExecuteTemplate(subject, "subject", data)
+	if err != nil {
+		return nil, err
+	}
+
+	plainBody := new(bytes.Buffer)
+	err = text.ExecuteTemplate(plainBody, "plainBody", data)
+	if err != nil {
+		return nil, err
+	}
+
+	html, err := htmltemplate.New("").ParseFS(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	htmlBody := new(bytes.Buffer)
+	err = html.ExecuteTemplate(htmlBody, "htmlBody", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		To:       to,
+		Subject:  strings.Join(strings.Fields(subject.String()), " "),
+		TextBody: strings.TrimSpace(plainBody.String()) + "\n",
+		HTMLBody: htmlBody.String(),
+	}, nil
+}
@@ -0,0 +1,127 @@
+package mailer
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"testing"
+	"testing/fstest"
+
+	"snippetbox.wook.net/internal/assert"
+	"snippetbox.wook.net/internal/mailer/mailertest"
+)
+
+var testTemplates = fstest.MapFS{
+	"mail/welcome.tmpl": {Data: []byte(`{{define "subject"}}
+Welcome, {{.Name}}!
+{{end}}
+{{define "plainBody"}}
+Hi {{.Name}}, visit {{.Link}}
+{{end}}
+{{define "htmlBody"}}<p>Hi {{.Name}}, <a href="{{.Link}}">visit</a></p>{{end}}`)},
+}
+
+func TestRender(t *testing.T) {
+	data := map[string]string{"Name": "<Bob>", "Link": "https://example.com/?a=1&b=2"}
+
+	msg, err := Render(testTemplates, "mail/welcome.tmpl", "bob@example.com", data)
+	assert.NilError(t, err)
+
+	assert.Equal(t, msg.To, "bob@example.com")
+	assert.Equal(t, msg.Subject, "Welcome, <Bob>!")
+	assert.Equal(t, msg.TextBody, "Hi <Bob>, visit https://example.com/?a=1&b=2\n")
+	assert.Equal(t, msg.HTMLBody, `<p>Hi &lt;Bob&gt;, <a href="https://example.com/?a=1&amp;b=2">visit</a></p>`)
+
+	_, err = Render(testTemplates, "mail/missing.tmpl", "bob@example.com", data)
+	if err == nil {
+		t.Error("got: nil; want: error for a missing template")
+	}
+}
+
+func newTestSMTP(t *testing.T) (*SMTP, *mailertest.Server) {
+	srv, err := mailertest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+
+	m, err := NewSMTP(srv.Host, srv.Port, "", "", "Snippetbox <no-reply@snippetbox.test>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return m, srv
+}
+
+func TestSMTPSend(t *testing.T) {
+	m, srv := newTestSMTP(t)
+
+	err := m.Send(&Message{
+		To:       "Bob <bob@example.com>",
+		Subject:  "Héllo",
+		TextBody: "plain body\n",
+		HTMLBody: "<p>html body</p>",
+	})
+	assert.NilError(t, err)
+
+	received := srv.Messages()
+	assert.Equal(t, len(received), 1)
+	assert.Equal(t, received[0].From, "no-reply@snippetbox.test")
+	assert.Equal(t, received[0].To[0], "bob@example.com")
+
+	msg, err := mail.ReadMessage(bytes.NewReader(received[0].Data))
+	assert.NilError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	assert.NilError(t, err)
+	assert.Equal(t, subject, "Héllo")
+	assert.StringContains(t, msg.Header.Get("From"), "<no-reply@snippetbox.test>")
+	assert.StringContains(t, msg.Header.Get("Message-Id"), "@snippetbox.test>")
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	assert.NilError(t, err)
+	assert.Equal(t, mediaType, "multipart/alternative")
+
+	var bodies []string
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		assert.NilError(t, err)
+
+		body, err := io.ReadAll(part)
+		assert.NilError(t, err)
+		bodies = append(bodies, string(body))
+	}
+
+	assert.Equal(t, len(bodies), 2)
+	assert.Equal(t, bodies[0], "plain body\n")
+	assert.Equal(t, bodies[1], "<p>html body</p>")
+}
+
+func TestSMTPSendErrors(t *testing.T) {
+	m, srv := newTestSMTP(t)
+
+	err := m.Send(&Message{To: "not an address", Subject: "Hi", TextBody: "Hi"})
+	if err == nil {
+		t.Error("got: nil; want: error for an invalid recipient")
+	}
+
+	srv.Reject(true)
+
+	err = m.Send(&Message{To: "bob@example.com", Subject: "Hi", TextBody: "Hi"})
+	if err == nil {
+		t.Error("got: nil; want: error for a rejected recipient")
+	}
+
+	assert.Equal(t, len(srv.Messages()), 0)
+
+	_, err = NewSMTP(srv.Host, srv.Port, "", "", "no sender")
+	if err == nil {
+		t.Error("got: nil; want: error for an invalid sender")
+	}
+}
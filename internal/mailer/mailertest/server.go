@@ -0,0 +1,179 @@
+// Package mailertest는 테스트에서 mailer.SMTP의 상대로 쓸 수 있는 메모리 안의 SMTP 서버를
+// 제공합니다. net/http/httptest처럼 루프백 주소의 임의 포트에서 실행됩니다.
+package mailertest
+
+import (
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Received는 서버가 받은 메시지 하나입니다. Data는 DATA 명령으로 받은 원본입니다.
+type Received struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// Server는 EHLO, MAIL, RCPT, DATA, RSET, NOOP, QUIT만 처리하는 SMTP 서버입니다.
+// STARTTLS와 AUTH는 지원하지 않습니다.
+type Server struct {
+	Host string
+	Port int
+
+	listener net.Listener
+	mu       sync.Mutex
+	conns    map[net.Conn]bool
+	messages []Received
+	reject   bool
+	wg       sync.WaitGroup
+}
+
+// NewServer는 서버를 시작합니다. 테스트가 끝나면 Close를 호출해야 합니다.
+func NewServer() (*Server, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	addr := l.Addr().(*net.TCPAddr)
+	s := &Server{Host: addr.IP.String(), Port: addr.Port, listener: l, conns: make(map[net.Conn]bool)}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// Addr는 서버의 "host:port" 주소입니다.
+func (s *Server) Addr() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// Close는 서버를 멈추고 열려 있는 연결을 모두 닫습니다.
+func (s *Server) Close() {
+	s.listener.Close()
+
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// Messages는 지금까지 받은 메시지를 받은 순서대로 반환합니다.
+func (s *Server) Messages() []Received {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Received(nil), s.messages...)
+}
+
+// Reject가 true이면 서버는 모든 수신자를 일시적 오류(451)로 거부합니다.
+// 재시도 동작을 확인할 때 사용합니다.
+func (s *Server) Reject(reject bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reject = reject
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(textproto.NewConn(conn))
+
+			conn.Close()
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+func (s *Server) handle(c *textproto.Conn) {
+	var msg Received
+
+	c.PrintfLine("220 mailertest ESMTP ready")
+
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			c.PrintfLine("250 mailertest")
+		case "MAIL":
+			msg = Received{From: address(arg)}
+			c.PrintfLine("250 OK")
+		case "RCPT":
+			s.mu.Lock()
+			reject := s.reject
+			s.mu.Unlock()
+
+			if reject {
+				c.PrintfLine("451 try again later")
+				continue
+			}
+			msg.To = append(msg.To, address(arg))
+			c.PrintfLine("250 OK")
+		case "DATA":
+			if len(msg.To) == 0 {
+				c.PrintfLine("503 need RCPT first")
+				continue
+			}
+			c.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+
+			data, err := c.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.Data = data
+
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+
+			msg = Received{}
+			c.PrintfLine("250 OK")
+		case "RSET":
+			msg = Received{}
+			c.PrintfLine("250 OK")
+		case "NOOP":
+			c.PrintfLine("250 OK")
+		case "QUIT":
+			c.PrintfLine("221 bye")
+			return
+		default:
+			c.PrintfLine("502 command not implemented")
+		}
+	}
+}
+
+// address는 "FROM:<alice@example.com>" 같은 인수에서 주소만 꺼냅니다.
+func address(arg string) string {
+	_, addr, _ := strings.Cut(arg, ":")
+	addr, _, _ = strings.Cut(strings.TrimSpace(addr), " ")
+	return strings.Trim(addr, "<>")
+}
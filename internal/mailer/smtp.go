@@ -0,0 +1,199 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTP는 SMTP 서버를 통해 메시지를 보내는 Mailer입니다. 서버가 STARTTLS를 지원하면
+// 항상 암호화된 연결로 전환합니다.
+type SMTP struct {
+	host    string
+	addr    string
+	auth    smtp.Auth
+	sender  *mail.Address
+	timeout time.Duration
+}
+
+// NewSMTP는 host:port의 SMTP 서버를 사용하는 Mailer를 만듭니다. username이 비어 있으면
+// 인증하지 않습니다. sender는 "Snippetbox <no-reply@example.com>"처럼 From 헤더에 쓰일 주소입니다.
+func NewSMTP(host string, port int, username, password, sender string) (*SMTP, error) {
+	from, err := mail.ParseAddress(sender)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: invalid sender %q: %w", sender, err)
+	}
+
+	m := &SMTP{
+		host:    host,
+		addr:    net.JoinHostPort(host, strconv.Itoa(port)),
+		sender:  from,
+		timeout: 10 * time.Second,
+	}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return m, nil
+}
+
+// Send는 메시지를 SMTP 서버에 전달합니다. 연결부터 전송까지 전체에 시간 제한을 둡니다.
+func (m *SMTP) Send(msg *Message) error {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("mailer: invalid recipient %q: %w", msg.To, err)
+	}
+
+	data, err := m.build(msg, to, time.Now())
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("tcp", m.addr, m.timeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(m.timeout))
+
+	c, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		err = c.StartTLS(&tls.Config{ServerName: m.host})
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("mailer: server does not support AUTH")
+		}
+		err = c.Auth(m.auth)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.Mail(m.sender.Address)
+	if err != nil {
+		return err
+	}
+
+	err = c.Rcpt(to.Address)
+	if err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		return err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// build는 메시지를 RFC 5322 형식으로 만듭니다. 본문은 quoted-printable로 인코딩하며,
+// HTML 본문이 있으면 텍스트 본문과 함께 multipart/alternative로 묶습니다.
+func (m *SMTP) build(msg *Message, to *mail.Address, now time.Time) ([]byte, error) {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		return nil, err
+	}
+
+	domain := m.sender.Address[strings.LastIndex(m.sender.Address, "@")+1:]
+
+	buf := new(bytes.Buffer)
+
+	header := textproto.MIMEHeader{}
+	header.Set("From", m.sender.String())
+	header.Set("To", to.String())
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header.Set("Date", now.Format(time.RFC1123Z))
+	header.Set("Message-Id", "<"+hex.EncodeToString(id)+"@"+domain+">")
+	header.Set("Mime-Version", "1.0")
+
+	if msg.HTMLBody == "" {
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		writeHeader(buf, header)
+
+		err = writeQuotedPrintable(buf, msg.TextBody)
+		return buf.Bytes(), err
+	}
+
+	mw := multipart.NewWriter(buf)
+
+	header.Set("Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+	writeHeader(buf, header)
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.TextBody},
+		{"text/html; charset=utf-8", msg.HTMLBody},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = writeQuotedPrintable(w, part.body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = mw.Close()
+	return buf.Bytes(), err
+}
+
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	for _, key := range []string{"From", "To", "Subject", "Date", "Message-Id", "Mime-Version", "Content-Type", "Content-Transfer-Encoding"} {
+		if value := header.Get(key); value != "" {
+			fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+		}
+	}
+	buf.WriteString("\r\n")
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+
+	_, err := qp.Write([]byte(body))
+	if err != nil {
+		return err
+	}
+
+	return qp.Close()
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type CollaboratorModelInterface interface {
+	Add(snippetID, userID int) error
+	Remove(snippetID, userID int) error
+	ForSnippet(snippetID int) ([]*Collaborator, error)
+	Exists(snippetID, userID int) (bool, error)
+}
+
+// Collaborator는 스니펫 작성자가 공동 편집에 초대한 사용자입니다.
+type Collaborator struct {
+	SnippetID int
+	UserID    int
+	Name      string
+	Email     string
+	Created   time.Time
+}
+
+type CollaboratorModel struct {
+	DB *sql.DB
+}
+
+// Add는 사용자를 스니펫의 공동 편집자로 추가합니다. 이미 초대한 사용자이면 아무 일도 하지 않습니다.
+func (m *CollaboratorModel) Add(snippetID, userID int) error {
+	stmt := `INSERT IGNORE INTO snippet_collaborators (snippet_id, user_id, created)
+	VALUES(?, ?, UTC_TIMESTAMP())`
+
+	_, err := m.DB.Exec(stmt, snippetID, userID)
+	return err
+}
+
+// Remove는 공동 편집자 초대를 취소합니다. 초대하지 않은 사용자이면 ErrNoRecord를 반환합니다.
+func (m *CollaboratorModel) Remove(snippetID, userID int) error {
+	result, err := m.DB.Exec("DELETE FROM snippet_collaborators WHERE snippet_id = ? AND user_id = ?", snippetID, userID)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
+
+// ForSnippet은 스니펫의 공동 편집자를 초대한 순서대로 반환합니다.
+func (m *CollaboratorModel) ForSnippet(snippetID int) ([]*Collaborator, error) {
+	stmt := `SELECT c.snippet_id, c.user_id, u.name, u.email, c.created
+	FROM snippet_collaborators c JOIN users u ON u.id = c.user_id
+	WHERE c.snippet_id = ? ORDER BY c.created, c.user_id`
+
+	rows, err := m.DB.Query(stmt, snippetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collaborators := []*Collaborator{}
+
+	for rows.Next() {
+		c := &Collaborator{}
+		err = rows.Scan(&c.SnippetID, &c.UserID, &c.Name, &c.Email, &c.Created)
+		if err != nil {
+			return nil, err
+		}
+		collaborators = append(collaborators, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return collaborators, nil
+}
+
+// Exists는 사용자가 스니펫의 공동 편집자로 초대되었는지 확인합니다.
+func (m *CollaboratorModel) Exists(snippetID, userID int) (bool, error) {
+	var exists bool
+
+	stmt := "SELECT EXISTS(SELECT true FROM snippet_collaborators WHERE snippet_id = ? AND user_id = ?)"
+
+	err := m.DB.QueryRow(stmt, snippetID, userID).Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,219 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+type CollectionModelInterface interface {
+	Insert(userID int, name string, public bool) (int, error)
+	Get(id int) (*Collection, error)
+	ForUser(userID int) ([]*Collection, error)
+	SetPublic(id int, public bool) error
+	AddSnippet(id, snippetID int) error
+	RemoveSnippet(id, snippetID int) error
+	MoveSnippet(id, snippetID, offset int) error
+}
+
+// Collection은 사용자가 이름을 붙여 모아 둔 스니펫 목록입니다.
+// Public이 false이면 소유자만 볼 수 있습니다.
+type Collection struct {
+	ID       int
+	UserID   int
+	Name     string
+	Public   bool
+	Created  time.Time
+	Snippets []*Snippet
+}
+
+type CollectionModel struct {
+	DB *sql.DB
+}
+
+func (m *CollectionModel) Insert(userID int, name string, public bool) (int, error) {
+	stmt := `INSERT INTO collections (user_id, name, public, created)
+	VALUES(?, ?, ?, UTC_TIMESTAMP())`
+
+	result, err := m.DB.Exec(stmt, userID, name, public)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// Get은 컬렉션과 그 안의 스니펫을 position 순서대로 반환합니다.
+// 만료된 스니펫은 목록에 포함되지 않습니다.
+func (m *CollectionModel) Get(id int) (*Collection, error) {
+	c := &Collection{}
+
+	err := m.DB.QueryRow(`SELECT id, user_id, name, public, created FROM collections
+	WHERE id = ?`, id).Scan(&c.ID, &c.UserID, &c.Name, &c.Public, &c.Created)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		} else {
+			return nil, err
+		}
+	}
+
+	stmt := `SELECT s.id, s.title, s.content, s.created, s.expires, s.views
+	FROM collection_snippets cs
+	JOIN snippets s ON s.id = cs.snippet_id
+	WHERE cs.collection_id = ? AND s.expires > UTC_TIMESTAMP()
+	ORDER BY cs.position`
+
+	rows, err := m.DB.Query(stmt, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	c.Snippets = []*Snippet{}
+
+	for rows.Next() {
+		s := &Snippet{}
+		err = rows.Scan(&s.ID, &s.Title, &s.Content, &s.Created, &s.Expires, &s.Views)
+		if err != nil {
+			return nil, err
+		}
+		c.Snippets = append(c.Snippets, s)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// ForUser는 사용자가 소유한 컬렉션을 이름순으로 반환합니다. 스니펫 목록은 채우지 않습니다.
+func (m *CollectionModel) ForUser(userID int) ([]*Collection, error) {
+	stmt := `SELECT id, user_id, name, public, created FROM collections
+	WHERE user_id = ? ORDER BY name`
+
+	rows, err := m.DB.Query(stmt, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := []*Collection{}
+
+	for rows.Next() {
+		c := &Collection{}
+		err = rows.Scan(&c.ID, &c.UserID, &c.Name, &c.Public, &c.Created)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+func (m *CollectionModel) SetPublic(id int, public bool) error {
+	_, err := m.DB.Exec("UPDATE collections SET public = ? WHERE id = ?", public, id)
+	return err
+}
+
+// AddSnippet은 스니펫을 컬렉션의 맨 끝에 추가합니다. 만료된 스니펫이나 존재하지 않는
+// 스니펫은 ErrNoRecord를, 이미 들어 있는 스니펫은 ErrDuplicateSnippet을 반환합니다.
+func (m *CollectionModel) AddSnippet(id, snippetID int) error {
+	stmt := `INSERT INTO collection_snippets (collection_id, snippet_id, position)
+	SELECT ?, s.id, (SELECT COALESCE(MAX(position), 0) + 1 FROM collection_snippets WHERE collection_id = ?)
+	FROM snippets s
+	WHERE s.id = ? AND s.expires > UTC_TIMESTAMP()`
+
+	result, err := m.DB.Exec(stmt, id, id, snippetID)
+	if err != nil {
+		var mySQLError *mysql.MySQLError
+		if errors.As(err, &mySQLError) {
+			if mySQLError.Number == 1062 && strings.Contains(mySQLError.Message, "PRIMARY") {
+				return ErrDuplicateSnippet
+			}
+		}
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
+
+func (m *CollectionModel) RemoveSnippet(id, snippetID int) error {
+	stmt := "DELETE FROM collection_snippets WHERE collection_id = ? AND snippet_id = ?"
+
+	_, err := m.DB.Exec(stmt, id, snippetID)
+	return err
+}
+
+// MoveSnippet은 스니펫을 offset이 음수이면 앞쪽으로, 양수이면 뒤쪽으로 한 칸 옮깁니다.
+// 이웃한 스니펫과 position 값을 맞바꾸며, 이미 맨 앞이나 맨 뒤라면 아무 일도 하지 않습니다.
+func (m *CollectionModel) MoveSnippet(id, snippetID, offset int) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var position int
+
+	err = tx.QueryRow(`SELECT position FROM collection_snippets
+	WHERE collection_id = ? AND snippet_id = ? FOR UPDATE`, id, snippetID).Scan(&position)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoRecord
+		} else {
+			return err
+		}
+	}
+
+	stmt := `SELECT snippet_id, position FROM collection_snippets
+	WHERE collection_id = ? AND position > ? ORDER BY position LIMIT 1 FOR UPDATE`
+	if offset < 0 {
+		stmt = `SELECT snippet_id, position FROM collection_snippets
+		WHERE collection_id = ? AND position < ? ORDER BY position DESC LIMIT 1 FOR UPDATE`
+	}
+
+	var otherID, otherPosition int
+
+	err = tx.QueryRow(stmt, id, position).Scan(&otherID, &otherPosition)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		} else {
+			return err
+		}
+	}
+
+	swap := "UPDATE collection_snippets SET position = ? WHERE collection_id = ? AND snippet_id = ?"
+
+	_, err = tx.Exec(swap, otherPosition, id, snippetID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(swap, position, id, otherID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
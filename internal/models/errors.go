@@ -10,4 +10,10 @@ var (
 	ErrInvalidCredentials = errors.New("models: invalid credetials")
 
 	ErrDupliacteEmail = errors.New("models: duplicate email")
+
+	ErrUnverified = errors.New("models: email address not verified")
+
+	ErrDuplicateSnippet = errors.New("models: snippet already in collection")
+
+	ErrUnreadableSecret = errors.New("models: two-factor secret cannot be decrypted")
 )
@@ -0,0 +1,41 @@
+package mocks
+
+import (
+	"time"
+
+	"snippetbox.wook.net/internal/models"
+)
+
+// CollaboratorModel에서 사용자 4(Carol)는 mockSnippet의 공동 편집자입니다.
+type CollaboratorModel struct{}
+
+func (m *CollaboratorModel) Add(snippetID, userID int) error {
+	return nil
+}
+
+func (m *CollaboratorModel) Remove(snippetID, userID int) error {
+	if snippetID == 1 && userID == 4 {
+		return nil
+	}
+	return models.ErrNoRecord
+}
+
+func (m *CollaboratorModel) ForSnippet(snippetID int) ([]*models.Collaborator, error) {
+	if snippetID != 1 {
+		return []*models.Collaborator{}, nil
+	}
+
+	return []*models.Collaborator{
+		{
+			SnippetID: 1,
+			UserID:    4,
+			Name:      "Carol Brown",
+			Email:     "carol@example.com",
+			Created:   time.Date(2022, 1, 4, 9, 0, 0, 0, time.UTC),
+		},
+	}, nil
+}
+
+func (m *CollaboratorModel) Exists(snippetID, userID int) (bool, error) {
+	return snippetID == 1 && userID == 4, nil
+}
@@ -0,0 +1,75 @@
+package mocks
+
+import (
+	"time"
+
+	"snippetbox.wook.net/internal/models"
+)
+
+// mockCollections의 1번 컬렉션은 alice(사용자 1)의 공개 컬렉션이고,
+// 2번 컬렉션은 다른 사용자의 비공개 컬렉션입니다.
+var mockCollections = map[int]*models.Collection{
+	1: {
+		ID:       1,
+		UserID:   1,
+		Name:     "Onboarding",
+		Public:   true,
+		Created:  time.Now(),
+		Snippets: []*models.Snippet{mockSnippet},
+	},
+	2: {
+		ID:       2,
+		UserID:   2,
+		Name:     "Private notes",
+		Public:   false,
+		Created:  time.Now(),
+		Snippets: []*models.Snippet{},
+	},
+}
+
+type CollectionModel struct{}
+
+func (m *CollectionModel) Insert(userID int, name string, public bool) (int, error) {
+	return 3, nil
+}
+
+func (m *CollectionModel) Get(id int) (*models.Collection, error) {
+	c, ok := mockCollections[id]
+	if !ok {
+		return nil, models.ErrNoRecord
+	}
+	return c, nil
+}
+
+func (m *CollectionModel) ForUser(userID int) ([]*models.Collection, error) {
+	collections := []*models.Collection{}
+	for _, c := range mockCollections {
+		if c.UserID == userID {
+			collections = append(collections, c)
+		}
+	}
+	return collections, nil
+}
+
+func (m *CollectionModel) SetPublic(id int, public bool) error {
+	return nil
+}
+
+func (m *CollectionModel) AddSnippet(id, snippetID int) error {
+	switch snippetID {
+	case 1:
+		return models.ErrDuplicateSnippet
+	case 2:
+		return nil
+	default:
+		return models.ErrNoRecord
+	}
+}
+
+func (m *CollectionModel) RemoveSnippet(id, snippetID int) error {
+	return nil
+}
+
+func (m *CollectionModel) MoveSnippet(id, snippetID, offset int) error {
+	return nil
+}
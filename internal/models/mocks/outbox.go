@@ -0,0 +1,25 @@
+package mocks
+
+import (
+	"time"
+
+	"snippetbox.wook.net/internal/models"
+)
+
+type OutboxModel struct{}
+
+func (m *OutboxModel) Enqueue(to, subject, textBody, htmlBody string) error {
+	return nil
+}
+
+func (m *OutboxModel) Due(limit int) ([]*models.Email, error) {
+	return []*models.Email{}, nil
+}
+
+func (m *OutboxModel) MarkSent(id int) error {
+	return nil
+}
+
+func (m *OutboxModel) MarkFailed(id int, message string, next time.Time, giveUp bool) error {
+	return nil
+}
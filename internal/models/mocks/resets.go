@@ -0,0 +1,25 @@
+package mocks
+
+import (
+	"time"
+
+	"snippetbox.wook.net/internal/models"
+)
+
+// 모의 재설정 토큰 "valid-reset-token"은 alice(사용자 1)의 것입니다.
+type PasswordResetModel struct{}
+
+func (m *PasswordResetModel) Insert(userID int, ttl time.Duration) (string, error) {
+	return "valid-reset-token", nil
+}
+
+func (m *PasswordResetModel) Get(plaintext string) (int, error) {
+	if plaintext == "valid-reset-token" {
+		return 1, nil
+	}
+	return 0, models.ErrNoRecord
+}
+
+func (m *PasswordResetModel) Consume(plaintext string) (int, error) {
+	return m.Get(plaintext)
+}
@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"time"
+
+	"snippetbox.wook.net/internal/models"
+)
+
+type RevisionModel struct{}
+
+func (m *RevisionModel) Insert(snippetID, userID int, content string) (int, error) {
+	switch snippetID {
+	case 1:
+		return 1, nil
+	default:
+		return 0, models.ErrNoRecord
+	}
+}
+
+func (m *RevisionModel) ForSnippet(snippetID int) ([]*models.Revision, error) {
+	if snippetID != 1 {
+		return []*models.Revision{}, nil
+	}
+
+	return []*models.Revision{
+		{
+			ID:        1,
+			SnippetID: 1,
+			UserID:    1,
+			Author:    "Alice Jones",
+			Content:   mockSnippet.Content,
+			Created:   time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC),
+		},
+	}, nil
+}
@@ -0,0 +1,121 @@
+package mocks
+
+import (
+	"sync"
+	"time"
+
+	"snippetbox.wook.net/internal/models"
+)
+
+// SessionModel은 로그인 기록을 메모리에 저장합니다. 다른 모의 모델과 달리 상태를 가지므로
+// 테스트에서 로그인한 세션의 목록과 철회를 확인할 수 있습니다.
+type SessionModel struct {
+	mu       sync.Mutex
+	sessions []*models.Session
+	nextID   int
+}
+
+func (m *SessionModel) Insert(userID int, token, userAgent, ip string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	now := time.Now().UTC()
+	m.sessions = append(m.sessions, &models.Session{
+		ID:        m.nextID,
+		UserID:    userID,
+		Token:     token,
+		UserAgent: userAgent,
+		IP:        ip,
+		Created:   now,
+		LastSeen:  now,
+	})
+
+	return m.nextID, nil
+}
+
+func (m *SessionModel) Exists(userID, id int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.sessions {
+		if s.ID == id && s.UserID == userID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (m *SessionModel) ForUser(userID int) ([]*models.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sessions := []*models.Session{}
+	for i := len(m.sessions) - 1; i >= 0; i-- {
+		if m.sessions[i].UserID == userID {
+			s := *m.sessions[i]
+			sessions = append(sessions, &s)
+		}
+	}
+
+	return sessions, nil
+}
+
+func (m *SessionModel) Touch(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.sessions {
+		if s.ID == id {
+			s.LastSeen = time.Now().UTC()
+		}
+	}
+
+	return nil
+}
+
+func (m *SessionModel) SetToken(id int, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.sessions {
+		if s.ID == id {
+			s.Token = token
+		}
+	}
+
+	return nil
+}
+
+func (m *SessionModel) Delete(userID, id int) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, s := range m.sessions {
+		if s.ID == id && s.UserID == userID {
+			m.sessions = append(m.sessions[:i], m.sessions[i+1:]...)
+			return s.Token, nil
+		}
+	}
+
+	return "", models.ErrNoRecord
+}
+
+func (m *SessionModel) DeleteForUser(userID, keepID int) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tokens := []string{}
+	kept := m.sessions[:0]
+	for _, s := range m.sessions {
+		if s.UserID != userID || s.ID == keepID {
+			kept = append(kept, s)
+		} else {
+			tokens = append(tokens, s.Token)
+		}
+	}
+	m.sessions = kept
+
+	return tokens, nil
+}
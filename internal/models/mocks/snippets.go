@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"strings"
 	"time"
 
 	"snippetbox.wook.net/internal/models"
@@ -8,15 +9,18 @@ import (
 
 var mockSnippet = &models.Snippet{
 	ID:      1,
+	UserID:  1,
+	Author:  "Alice Jones",
 	Title:   "An old silent pond",
 	Content: "An old silent pond...",
+	Tags:    []string{"haiku"},
 	Created: time.Now(),
 	Expires: time.Now(),
 }
 
 type SnippetModel struct{}
 
-func (m *SnippetModel) Insert(title string, content string, expires int) (int, error) {
+func (m *SnippetModel) Insert(userID int, title string, content string, expires int, tags []string) (int, error) {
 	return 2, nil
 }
 
@@ -31,3 +35,96 @@ func (m *SnippetModel) Get(id int) (*models.Snippet, error) {
 func (m *SnippetModel) Latest() ([]*models.Snippet, error) {
 	return []*models.Snippet{mockSnippet}, nil
 }
+
+func (m *SnippetModel) LatestByUser(userID int) ([]*models.Snippet, error) {
+	if userID == mockSnippet.UserID {
+		return []*models.Snippet{mockSnippet}, nil
+	}
+	return []*models.Snippet{}, nil
+}
+
+func (m *SnippetModel) LatestByTag(tag string) ([]*models.Snippet, error) {
+	for _, t := range mockSnippet.Tags {
+		if t == tag {
+			return []*models.Snippet{mockSnippet}, nil
+		}
+	}
+	return []*models.Snippet{}, nil
+}
+
+func (m *SnippetModel) AddViews(counts map[int]int) error {
+	return nil
+}
+
+func (m *SnippetModel) List(query string, page, pageSize int) ([]*models.Snippet, int, error) {
+	if query != "" && !strings.Contains(mockSnippet.Title, query) && !strings.Contains(mockSnippet.Content, query) {
+		return []*models.Snippet{}, 0, nil
+	}
+	if page > 1 {
+		return []*models.Snippet{}, 1, nil
+	}
+	return []*models.Snippet{mockSnippet}, 1, nil
+}
+
+func (m *SnippetModel) Update(id int, title string, content string, tags []string) error {
+	return nil
+}
+
+func (m *SnippetModel) Delete(id int) error {
+	switch id {
+	case 1:
+		return nil
+	default:
+		return models.ErrNoRecord
+	}
+}
+
+func (m *SnippetModel) GetMany(ids []int) ([]*models.Snippet, error) {
+	snippets := []*models.Snippet{}
+	for _, id := range ids {
+		if id == mockSnippet.ID {
+			snippets = append(snippets, mockSnippet)
+		}
+	}
+	return snippets, nil
+}
+
+func (m *SnippetModel) Page(filter models.SnippetFilter, after, limit int) (*models.SnippetPage, error) {
+	page := &models.SnippetPage{Snippets: []*models.Snippet{}}
+
+	if filter.Query != "" && !strings.Contains(mockSnippet.Title, filter.Query) && !strings.Contains(mockSnippet.Content, filter.Query) {
+		return page, nil
+	}
+	if filter.Tag != "" && filter.Tag != "haiku" {
+		return page, nil
+	}
+	if filter.UserID != 0 && filter.UserID != mockSnippet.UserID {
+		return page, nil
+	}
+
+	page.Total = 1
+	if (after == 0 || mockSnippet.ID < after) && limit > 0 {
+		page.Snippets = append(page.Snippets, mockSnippet)
+	}
+	return page, nil
+}
+
+func (m *SnippetModel) PageByUsers(userIDs []int, after, limit int) (map[int]*models.SnippetPage, error) {
+	pages := make(map[int]*models.SnippetPage, len(userIDs))
+	for _, id := range userIDs {
+		pages[id], _ = m.Page(models.SnippetFilter{UserID: id}, after, limit)
+	}
+	return pages, nil
+}
+
+func (m *SnippetModel) TagCounts(tags []string) (map[string]int, error) {
+	counts := make(map[string]int, len(tags))
+	for _, tag := range tags {
+		if tag == "haiku" {
+			counts[tag] = 1
+		} else {
+			counts[tag] = 0
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,58 @@
+package mocks
+
+import (
+	"time"
+
+	"snippetbox.wook.net/internal/models"
+)
+
+// 모의 토큰은 모두 alice(사용자 1)의 것이며, readonly 토큰에는 쓰기 권한이 없습니다.
+var mockTokens = map[string]*models.Token{
+	"sbx_valid": {
+		ID:      1,
+		UserID:  1,
+		Name:    "laptop",
+		Scopes:  []string{models.ScopeSnippetsRead, models.ScopeSnippetsWrite},
+		Created: time.Now(),
+	},
+	"sbx_readonly": {
+		ID:      2,
+		UserID:  1,
+		Name:    "dashboard",
+		Scopes:  []string{models.ScopeSnippetsRead},
+		Created: time.Now(),
+	},
+}
+
+type TokenModel struct{}
+
+func (m *TokenModel) Insert(userID int, name string, scopes []string) (string, error) {
+	return "sbx_new", nil
+}
+
+func (m *TokenModel) ForUser(userID int) ([]*models.Token, error) {
+	tokens := []*models.Token{}
+	for _, t := range mockTokens {
+		if t.UserID == userID {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens, nil
+}
+
+func (m *TokenModel) Revoke(userID, id int) error {
+	for _, t := range mockTokens {
+		if t.ID == id && t.UserID == userID {
+			return nil
+		}
+	}
+	return models.ErrNoRecord
+}
+
+func (m *TokenModel) Authenticate(plaintext string) (*models.Token, error) {
+	t, ok := mockTokens[plaintext]
+	if !ok {
+		return nil, models.ErrInvalidCredentials
+	}
+	return t, nil
+}
@@ -0,0 +1,13 @@
+package mocks
+
+import "snippetbox.wook.net/internal/models"
+
+type TrendingModel struct{}
+
+func (m *TrendingModel) Refresh() error {
+	return nil
+}
+
+func (m *TrendingModel) Get(period string, limit int) ([]*models.Ranking, error) {
+	return []*models.Ranking{{Rank: 1, Score: 42, Snippet: mockSnippet}}, nil
+}
@@ -0,0 +1,53 @@
+package mocks
+
+import (
+	"time"
+
+	"snippetbox.wook.net/internal/models"
+)
+
+// MockTOTPSecret은 사용자 4(carol)의 TOTP 비밀 키입니다. RFC 6238의 시험용 키와 같습니다.
+// 사용자 4의 복구 코드는 "abcde-fghij" 하나뿐입니다.
+var MockTOTPSecret = []byte("12345678901234567890")
+
+type TwoFactorModel struct{}
+
+func (m *TwoFactorModel) Enable(userID int, secret []byte, step int64, recoveryCodes []string) error {
+	return nil
+}
+
+func (m *TwoFactorModel) Get(userID int) (*models.TwoFactor, error) {
+	if userID == 4 {
+		return &models.TwoFactor{
+			UserID:        4,
+			RecoveryCodes: 1,
+			Created:       time.Date(2022, 1, 3, 9, 0, 0, 0, time.UTC),
+		}, nil
+	}
+	return nil, models.ErrNoRecord
+}
+
+func (m *TwoFactorModel) Secret(userID int) ([]byte, error) {
+	if userID == 4 {
+		return MockTOTPSecret, nil
+	}
+	return nil, models.ErrNoRecord
+}
+
+func (m *TwoFactorModel) UseStep(userID int, step int64) error {
+	if userID == 4 {
+		return nil
+	}
+	return models.ErrInvalidCredentials
+}
+
+func (m *TwoFactorModel) UseRecoveryCode(userID int, code string) error {
+	if userID == 4 && models.NormalizeRecoveryCode(code) == "abcdefghij" {
+		return nil
+	}
+	return models.ErrInvalidCredentials
+}
+
+func (m *TwoFactorModel) Disable(userID int) error {
+	return nil
+}
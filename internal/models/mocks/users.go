@@ -1,28 +1,153 @@
 package mocks
 
-import "snippetbox.wook.net/internal/models"
+import (
+	"time"
+
+	"snippetbox.wook.net/internal/models"
+)
 
 type UserModel struct{}
 
-func (m *UserModel) Insert(name, email, password string) error {
+func (m *UserModel) Insert(name, email, password string) (int, error) {
 	switch email {
 	case "dupe@example.com":
-		return models.ErrDupliacteEmail
+		return 0, models.ErrDupliacteEmail
 	default:
-		return nil
+		return 2, nil
 	}
 }
 func (m *UserModel) Authenticate(email, password string) (int, error) {
 	if email == "alice@example.com" && password == "pa$$word" {
 		return 1, nil
 	}
+	if email == "unverified@example.com" && password == "pa$$word" {
+		return 0, models.ErrUnverified
+	}
+	if email == "carol@example.com" && password == "pa$$word" {
+		return 4, nil
+	}
 	return 0, models.ErrInvalidCredentials
 }
 func (m *UserModel) Exists(id int) (bool, error) {
 	switch id {
-	case 1:
+	case 1, 3, 4:
 		return true, nil
 	default:
 		return false, nil
 	}
 }
+
+func (m *UserModel) Get(id int) (*models.User, error) {
+	switch id {
+	case 1:
+		return &models.User{
+			ID:       1,
+			Name:     "Alice Jones",
+			Email:    "alice@example.com",
+			Created:  time.Date(2022, 1, 1, 9, 0, 0, 0, time.UTC),
+			Verified: true,
+		}, nil
+	case 3:
+		// 사용자 3은 아직 이메일 주소를 인증하지 않았으므로 로그인할 수 없습니다.
+		return &models.User{
+			ID:      3,
+			Name:    "Bob Smith",
+			Email:   "unverified@example.com",
+			Created: time.Date(2022, 1, 2, 9, 0, 0, 0, time.UTC),
+		}, nil
+	case 4:
+		// 사용자 4는 2단계 인증을 사용합니다. mocks.TwoFactorModel을 참고하세요.
+		return &models.User{
+			ID:       4,
+			Name:     "Carol Brown",
+			Email:    "carol@example.com",
+			Created:  time.Date(2022, 1, 3, 9, 0, 0, 0, time.UTC),
+			Verified: true,
+		}, nil
+	default:
+		return nil, models.ErrNoRecord
+	}
+}
+
+func (m *UserModel) GetByEmail(email string) (*models.User, error) {
+	switch email {
+	case "alice@example.com":
+		return m.Get(1)
+	case "unverified@example.com":
+		return m.Get(3)
+	case "carol@example.com":
+		return m.Get(4)
+	default:
+		return nil, models.ErrNoRecord
+	}
+}
+
+func (m *UserModel) GetMany(ids []int) ([]*models.User, error) {
+	users := []*models.User{}
+	for _, id := range ids {
+		if u, err := m.Get(id); err == nil {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+func (m *UserModel) Verify(id int, email string) error {
+	switch {
+	case id == 1 && email == "alice@example.com":
+		return nil
+	case id == 3 && email == "unverified@example.com":
+		return nil
+	default:
+		return models.ErrNoRecord
+	}
+}
+
+func (m *UserModel) UpdatePassword(id int, password string) error {
+	_, err := m.Get(id)
+	return err
+}
+
+func (m *UserModel) UpdateName(id int, name string) error {
+	_, err := m.Get(id)
+	return err
+}
+
+func (m *UserModel) UpdateEmail(id int, email string) error {
+	switch email {
+	case "dupe@example.com", "alice@example.com", "unverified@example.com", "carol@example.com":
+		return models.ErrDupliacteEmail
+	}
+	_, err := m.Get(id)
+	return err
+}
+
+func (m *UserModel) Export(id int) (*models.UserExport, error) {
+	u, err := m.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &models.UserExport{
+		ID:          u.ID,
+		Name:        u.Name,
+		Email:       u.Email,
+		Created:     u.Created,
+		Snippets:    []*models.Snippet{},
+		Collections: []models.ExportCollection{},
+		Revisions:   []models.ExportRevision{},
+		Tokens:      []models.ExportToken{},
+		Webhooks:    []models.ExportWebhook{},
+		Sessions:    []models.ExportSession{},
+	}
+	if id == mockSnippet.UserID {
+		e.Snippets = append(e.Snippets, mockSnippet)
+	}
+
+	return e, nil
+}
+
+func (m *UserModel) Delete(id int, keepSnippets bool) error {
+	_, err := m.Get(id)
+	return err
+}
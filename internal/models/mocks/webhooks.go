@@ -0,0 +1,102 @@
+package mocks
+
+import (
+	"time"
+
+	"snippetbox.wook.net/internal/models"
+)
+
+// 모의 웹훅 1은 alice(사용자 1)의 것이고, 웹훅 2는 다른 사용자의 것입니다.
+var mockWebhooks = []*models.Webhook{
+	{
+		ID:      1,
+		UserID:  1,
+		URL:     "https://example.com/hooks/snippetbox",
+		Secret:  "mocksecret",
+		Created: time.Now(),
+	},
+	{
+		ID:      2,
+		UserID:  2,
+		URL:     "https://example.org/hook",
+		Secret:  "othersecret",
+		Created: time.Now(),
+	},
+}
+
+var mockDelivery = &models.WebhookDelivery{
+	ID:          1,
+	WebhookID:   1,
+	URL:         "https://example.com/hooks/snippetbox",
+	Secret:      "mocksecret",
+	Event:       models.EventSnippetCreated,
+	SnippetID:   1,
+	Payload:     []byte(`{"event":"snippet.created"}`),
+	Status:      models.DeliveryFailed,
+	Attempts:    8,
+	LastStatus:  502,
+	LastError:   "502 Bad Gateway",
+	NextAttempt: time.Now(),
+	Created:     time.Now(),
+}
+
+type WebhookModel struct{}
+
+func (m *WebhookModel) Insert(userID int, url string) (int, string, error) {
+	return 3, "newsecret", nil
+}
+
+func (m *WebhookModel) Get(id int) (*models.Webhook, error) {
+	for _, h := range mockWebhooks {
+		if h.ID == id {
+			return h, nil
+		}
+	}
+	return nil, models.ErrNoRecord
+}
+
+func (m *WebhookModel) ForUser(userID int) ([]*models.Webhook, error) {
+	webhooks := []*models.Webhook{}
+	for _, h := range mockWebhooks {
+		if h.UserID == userID {
+			webhooks = append(webhooks, h)
+		}
+	}
+	return webhooks, nil
+}
+
+func (m *WebhookModel) Delete(userID, id int) error {
+	for _, h := range mockWebhooks {
+		if h.ID == id && h.UserID == userID {
+			return nil
+		}
+	}
+	return models.ErrNoRecord
+}
+
+func (m *WebhookModel) Enqueue(event string, snippet *models.Snippet) error {
+	return nil
+}
+
+func (m *WebhookModel) EnqueueExpired() (int, error) {
+	return 0, nil
+}
+
+func (m *WebhookModel) Due(limit int) ([]*models.WebhookDelivery, error) {
+	return []*models.WebhookDelivery{}, nil
+}
+
+func (m *WebhookModel) MarkDelivered(id, status int) error {
+	return nil
+}
+
+func (m *WebhookModel) MarkFailed(id, status int, message string, next time.Time, giveUp bool) error {
+	return nil
+}
+
+func (m *WebhookModel) Deliveries(webhookID, limit int) ([]*models.WebhookDelivery, error) {
+	if webhookID == mockDelivery.WebhookID {
+		return []*models.WebhookDelivery{mockDelivery}, nil
+	}
+	return []*models.WebhookDelivery{}, nil
+}
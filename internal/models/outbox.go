@@ -0,0 +1,101 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type OutboxModelInterface interface {
+	Enqueue(to, subject, textBody, htmlBody string) error
+	Due(limit int) ([]*Email, error)
+	MarkSent(id int) error
+	MarkFailed(id int, message string, next time.Time, giveUp bool) error
+}
+
+// Email은 보낼 편지함(outbox)의 한 항목입니다. 메일은 요청을 처리하는 동안 바로 보내지 않고
+// 이 테이블에 넣어 두었다가 백그라운드에서 보내므로, 서버가 다시 시작되거나 SMTP 서버가
+// 잠시 응답하지 않아도 사라지지 않습니다. Status는 웹훅 전달과 같은 Delivery* 값을 사용합니다.
+type Email struct {
+	ID          int
+	To          string
+	Subject     string
+	TextBody    string
+	HTMLBody    string
+	Status      string
+	Attempts    int
+	LastError   string
+	NextAttempt time.Time
+	Created     time.Time
+}
+
+type OutboxModel struct {
+	DB *sql.DB
+}
+
+// Enqueue는 메일을 대기열에 넣습니다. 메일은 다음 Due 호출에서 바로 꺼낼 수 있습니다.
+func (m *OutboxModel) Enqueue(to, subject, textBody, htmlBody string) error {
+	stmt := `INSERT INTO outbox (recipient, subject, text_body, html_body, status, next_attempt, created)
+	VALUES(?, ?, ?, ?, ?, UTC_TIMESTAMP(), UTC_TIMESTAMP())`
+
+	_, err := m.DB.Exec(stmt, to, subject, textBody, htmlBody, DeliveryPending)
+	return err
+}
+
+// Due는 다음 시도 시각이 지난 대기 중인 메일을 오래된 순서로 최대 limit개 반환합니다.
+func (m *OutboxModel) Due(limit int) ([]*Email, error) {
+	stmt := `SELECT id, recipient, subject, text_body, html_body, status, attempts, last_error, next_attempt, created
+	FROM outbox WHERE status = ? AND next_attempt <= UTC_TIMESTAMP()
+	ORDER BY next_attempt, id LIMIT ?`
+
+	rows, err := m.DB.Query(stmt, DeliveryPending, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	emails := []*Email{}
+
+	for rows.Next() {
+		e := &Email{}
+		err = rows.Scan(&e.ID, &e.To, &e.Subject, &e.TextBody, &e.HTMLBody, &e.Status, &e.Attempts,
+			&e.LastError, &e.NextAttempt, &e.Created)
+		if err != nil {
+			return nil, err
+		}
+		emails = append(emails, e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return emails, nil
+}
+
+// MarkSent는 메일을 보낸 것으로 기록합니다. 본문에는 인증 링크 같은 비밀 값이 들어 있을 수
+// 있으므로 보낸 뒤에는 본문을 지우고 기록만 남깁니다.
+func (m *OutboxModel) MarkSent(id int) error {
+	stmt := `UPDATE outbox SET status = ?, attempts = attempts + 1, last_error = '', text_body = '', html_body = ''
+	WHERE id = ?`
+
+	_, err := m.DB.Exec(stmt, DeliveryDelivered, id)
+	return err
+}
+
+// MarkFailed는 실패한 시도를 기록합니다. giveUp이 false이면 next에 다시 시도하고,
+// true이면 더 이상 시도하지 않습니다.
+func (m *OutboxModel) MarkFailed(id int, message string, next time.Time, giveUp bool) error {
+	newStatus := DeliveryPending
+	if giveUp {
+		newStatus = DeliveryFailed
+	}
+
+	if len(message) > 255 {
+		message = message[:255]
+	}
+
+	stmt := `UPDATE outbox SET status = ?, attempts = attempts + 1, last_error = ?, next_attempt = ?
+	WHERE id = ?`
+
+	_, err := m.DB.Exec(stmt, newStatus, message, next.UTC(), id)
+	return err
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"time"
+)
+
+type PasswordResetModelInterface interface {
+	Insert(userID int, ttl time.Duration) (string, error)
+	Get(plaintext string) (int, error)
+	Consume(plaintext string) (int, error)
+}
+
+// PasswordResetModel은 비밀번호 재설정 토큰을 관리합니다. 개인 액세스 토큰과 같이
+// 데이터베이스에는 토큰의 SHA-256 해시만 저장합니다.
+type PasswordResetModel struct {
+	DB *sql.DB
+}
+
+// Insert는 ttl 동안 유효한 재설정 토큰을 만들고 평문 토큰을 반환합니다. 사용자에게
+// 이전에 발급한 토큰은 모두 무효가 되므로 가장 최근에 보낸 링크만 사용할 수 있습니다.
+func (m *PasswordResetModel) Insert(userID int, ttl time.Duration) (string, error) {
+	randomBytes := make([]byte, 20)
+
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(randomBytes)
+
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM password_resets WHERE user_id = ?", userID)
+	if err != nil {
+		return "", err
+	}
+
+	stmt := `INSERT INTO password_resets (hash, user_id, expires, created)
+	VALUES(?, ?, ?, UTC_TIMESTAMP())`
+
+	_, err = tx.Exec(stmt, hashToken(plaintext), userID, time.Now().Add(ttl).UTC())
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", err
+	}
+
+	return plaintext, nil
+}
+
+// Get은 유효한 재설정 토큰의 사용자 ID를 반환합니다. 토큰은 그대로 남습니다.
+// 토큰이 없거나 만료되었으면 ErrNoRecord를 반환합니다.
+func (m *PasswordResetModel) Get(plaintext string) (int, error) {
+	var userID int
+
+	stmt := "SELECT user_id FROM password_resets WHERE hash = ? AND expires > UTC_TIMESTAMP()"
+
+	err := m.DB.QueryRow(stmt, hashToken(plaintext)).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNoRecord
+		}
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// Consume은 유효한 재설정 토큰을 삭제하고 그 사용자 ID를 반환합니다. 같은 토큰으로
+// 동시에 요청하더라도 DELETE에 성공한 요청 하나만 사용자 ID를 받습니다.
+func (m *PasswordResetModel) Consume(plaintext string) (int, error) {
+	userID, err := m.Get(plaintext)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := m.DB.Exec("DELETE FROM password_resets WHERE hash = ?", hashToken(plaintext))
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, ErrNoRecord
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type RevisionModelInterface interface {
+	Insert(snippetID, userID int, content string) (int, error)
+	ForSnippet(snippetID int) ([]*Revision, error)
+}
+
+// Revision은 공동 편집 세션에서 저장한 스니펫 내용입니다. Author는 저장한 사용자의 이름입니다.
+type Revision struct {
+	ID        int
+	SnippetID int
+	UserID    int
+	Author    string
+	Content   string
+	Created   time.Time
+}
+
+type RevisionModel struct {
+	DB *sql.DB
+}
+
+// Insert는 새 리비전을 기록하고 스니펫의 내용을 그 리비전으로 바꿉니다.
+// 스니펫이 없거나 만료되었으면 ErrNoRecord를 반환합니다.
+func (m *RevisionModel) Insert(snippetID, userID int, content string) (int, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE snippets SET content = ?
+	WHERE id = ? AND expires > UTC_TIMESTAMP()`, content, snippetID)
+	if err != nil {
+		return 0, err
+	}
+
+	// 내용이 같으면 RowsAffected가 0이 되므로 존재 여부는 따로 확인합니다.
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		var exists bool
+		err = tx.QueryRow(`SELECT EXISTS(SELECT true FROM snippets
+		WHERE id = ? AND expires > UTC_TIMESTAMP())`, snippetID).Scan(&exists)
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			return 0, ErrNoRecord
+		}
+	}
+
+	result, err = tx.Exec(`INSERT INTO snippet_revisions (snippet_id, user_id, content, created)
+	VALUES(?, ?, ?, UTC_TIMESTAMP())`, snippetID, userID, content)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// ForSnippet은 스니펫의 리비전을 최신순으로 반환합니다.
+func (m *RevisionModel) ForSnippet(snippetID int) ([]*Revision, error) {
+	stmt := `SELECT r.id, r.snippet_id, r.user_id, COALESCE(u.name, ''), r.content, r.created
+	FROM snippet_revisions r LEFT JOIN users u ON u.id = r.user_id
+	WHERE r.snippet_id = ? ORDER BY r.id DESC`
+
+	rows, err := m.DB.Query(stmt, snippetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*Revision{}
+
+	for rows.Next() {
+		rev := &Revision{}
+		err = rows.Scan(&rev.ID, &rev.SnippetID, &rev.UserID, &rev.Author, &rev.Content, &rev.Created)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type SessionModelInterface interface {
+	Insert(userID int, token, userAgent, ip string) (int, error)
+	Exists(userID, id int) (bool, error)
+	ForUser(userID int) ([]*Session, error)
+	Touch(id int) error
+	SetToken(id int, token string) error
+	Delete(userID, id int) (string, error)
+	DeleteForUser(userID, keepID int) ([]string, error)
+}
+
+// Session은 한 번의 로그인에 대한 정보입니다. 세션 데이터 자체는 scs 저장소에 있으며,
+// 그 데이터의 "sessionID" 값이 이 레코드의 ID입니다. Token은 scs 저장소의 세션 토큰으로,
+// 세션을 철회할 때 저장소의 항목을 바로 삭제하는 데 사용합니다.
+type Session struct {
+	ID        int
+	UserID    int
+	Token     string
+	UserAgent string
+	IP        string
+	Created   time.Time
+	LastSeen  time.Time
+}
+
+type SessionModel struct {
+	DB *sql.DB
+}
+
+// Insert는 새 로그인을 기록하고 그 ID를 반환합니다. 너무 긴 User-Agent는 잘라서 저장합니다.
+func (m *SessionModel) Insert(userID int, token, userAgent, ip string) (int, error) {
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	stmt := `INSERT INTO user_sessions (user_id, token, user_agent, ip, created, last_seen)
+	VALUES(?, ?, ?, ?, UTC_TIMESTAMP(), UTC_TIMESTAMP())`
+
+	result, err := m.DB.Exec(stmt, userID, token, userAgent, ip)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// Exists는 사용자의 로그인 기록 id가 아직 남아 있는지 확인합니다. 철회된 세션이나 로그인
+// 기록을 만들기 전에 로그인한 세션은 false입니다.
+func (m *SessionModel) Exists(userID, id int) (bool, error) {
+	var exists bool
+
+	stmt := "SELECT EXISTS(SELECT true FROM user_sessions WHERE id = ? AND user_id = ?)"
+
+	err := m.DB.QueryRow(stmt, id, userID).Scan(&exists)
+	return exists, err
+}
+
+// ForUser는 사용자의 로그인 기록을 마지막으로 사용한 순서대로 반환합니다. scs 저장소의
+// sessions 테이블에서 이미 만료되었거나 사라진 세션의 기록은 제외합니다.
+func (m *SessionModel) ForUser(userID int) ([]*Session, error) {
+	stmt := `SELECT us.id, us.user_id, us.token, us.user_agent, us.ip, us.created, us.last_seen
+	FROM user_sessions us JOIN sessions s ON s.token = us.token
+	WHERE us.user_id = ? AND UTC_TIMESTAMP(6) < s.expiry
+	ORDER BY us.last_seen DESC, us.id DESC`
+
+	rows, err := m.DB.Query(stmt, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*Session{}
+
+	for rows.Next() {
+		s := &Session{}
+		err = rows.Scan(&s.ID, &s.UserID, &s.Token, &s.UserAgent, &s.IP, &s.Created, &s.LastSeen)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Touch는 세션을 마지막으로 사용한 시각을 현재 시각으로 바꿉니다.
+func (m *SessionModel) Touch(id int) error {
+	_, err := m.DB.Exec("UPDATE user_sessions SET last_seen = UTC_TIMESTAMP() WHERE id = ?", id)
+	return err
+}
+
+// SetToken은 세션 토큰이 바뀌었을 때 기록된 토큰을 바꿉니다.
+func (m *SessionModel) SetToken(id int, token string) error {
+	_, err := m.DB.Exec("UPDATE user_sessions SET token = ? WHERE id = ?", token, id)
+	return err
+}
+
+// Delete는 사용자의 로그인 기록 하나를 삭제하고 그 세션 토큰을 반환합니다. 다른 사용자의
+// 기록이거나 없는 기록이면 ErrNoRecord를 반환합니다.
+func (m *SessionModel) Delete(userID, id int) (string, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var token string
+
+	err = tx.QueryRow("SELECT token FROM user_sessions WHERE id = ? AND user_id = ? FOR UPDATE", id, userID).Scan(&token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNoRecord
+		}
+		return "", err
+	}
+
+	_, err = tx.Exec("DELETE FROM user_sessions WHERE id = ?", id)
+	if err != nil {
+		return "", err
+	}
+
+	return token, tx.Commit()
+}
+
+// DeleteForUser는 keepID를 제외한 사용자의 로그인 기록을 모두 삭제하고 그 세션 토큰을
+// 반환합니다. keepID가 0이면 모두 삭제합니다.
+func (m *SessionModel) DeleteForUser(userID, keepID int) ([]string, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT token FROM user_sessions WHERE user_id = ? AND id <> ? FOR UPDATE", userID, keepID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []string{}
+
+	for rows.Next() {
+		var token string
+		err = rows.Scan(&token)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec("DELETE FROM user_sessions WHERE user_id = ? AND id <> ?", userID, keepID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+
+	"snippetbox.wook.net/internal/assert"
+)
+
+func TestSessionModelForUser(t *testing.T) {
+	if testing.Short() {
+		t.Skip("models: skipping integration test")
+	}
+
+	db := newTestDB(t)
+
+	// 로그인 기록 1의 세션은 아직 유효하고, 2의 세션은 만료되었으며, 3의 세션은 저장소에서
+	// 이미 지워졌습니다.
+	_, err := db.Exec(`INSERT INTO user_sessions (user_id, token, user_agent, ip, created, last_seen) VALUES
+	(1, REPEAT('a', 43), 'Go-http-client/1.1', '127.0.0.1', '2022-01-06 10:00:00', '2022-01-06 10:30:00'),
+	(1, REPEAT('b', 43), 'Go-http-client/1.1', '127.0.0.1', '2022-01-06 11:00:00', '2022-01-06 11:30:00'),
+	(1, REPEAT('c', 43), 'Go-http-client/1.1', '127.0.0.1', '2022-01-06 12:00:00', '2022-01-06 12:30:00')`)
+	assert.NilError(t, err)
+
+	_, err = db.Exec(`INSERT INTO sessions (token, data, expiry) VALUES
+	(REPEAT('a', 43), '', DATE_ADD(UTC_TIMESTAMP(6), INTERVAL 1 DAY)),
+	(REPEAT('b', 43), '', DATE_SUB(UTC_TIMESTAMP(6), INTERVAL 1 MINUTE))`)
+	assert.NilError(t, err)
+
+	m := SessionModel{db}
+
+	sessions, err := m.ForUser(1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(sessions), 1)
+	assert.Equal(t, sessions[0].ID, 1)
+
+	tests := []struct {
+		name   string
+		userID int
+		id     int
+		want   bool
+	}{
+		{"Own session", 1, 1, true},
+		{"Expired session", 1, 2, true},
+		{"Other user", 2, 1, false},
+		{"Unknown session", 1, 99, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exists, err := m.Exists(tt.userID, tt.id)
+			assert.NilError(t, err)
+			assert.Equal(t, exists, tt.want)
+		})
+	}
+}
@@ -3,21 +3,60 @@ package models
 import (
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 )
 
 type SnippetModelInterface interface {
-	Insert(title string, content string, expires int) (int, error)
+	Insert(userID int, title string, content string, expires int, tags []string) (int, error)
 	Get(id int) (*Snippet, error)
 	Latest() ([]*Snippet, error)
+	LatestByUser(userID int) ([]*Snippet, error)
+	LatestByTag(tag string) ([]*Snippet, error)
+	List(query string, page, pageSize int) ([]*Snippet, int, error)
+	Update(id int, title string, content string, tags []string) error
+	Delete(id int) error
+	AddViews(counts map[int]int) error
+	GetMany(ids []int) ([]*Snippet, error)
+	Page(filter SnippetFilter, after, limit int) (*SnippetPage, error)
+	PageByUsers(userIDs []int, after, limit int) (map[int]*SnippetPage, error)
+	TagCounts(tags []string) (map[string]int, error)
 }
 
+// UserID가 0이면 작성자가 없는 스니펫입니다. Author는 작성자의 이름입니다.
+// 구조체 태그는 JSON API의 응답 필드 이름을 정합니다.
 type Snippet struct {
-	ID      int
-	Title   string
-	Content string
-	Created time.Time
-	Expires time.Time
+	ID      int       `json:"id"`
+	UserID  int       `json:"user_id"`
+	Author  string    `json:"author"`
+	Title   string    `json:"title"`
+	Content string    `json:"content"`
+	Tags    []string  `json:"tags"`
+	Created time.Time `json:"created"`
+	Expires time.Time `json:"expires"`
+	Views   int       `json:"views"`
+}
+
+// snippetColumns는 작성자 이름을 포함하여 Snippet 구조체를 채우는 데 필요한 열입니다.
+// 쿼리는 snippets 테이블을 s로, users 테이블을 u로 LEFT JOIN해야 하며
+// 결과는 scanSnippet()으로 읽습니다.
+const snippetColumns = `s.id, s.user_id, COALESCE(u.name, ''), s.title, s.content, s.created, s.expires, s.views
+	FROM snippets s LEFT JOIN users u ON u.id = s.user_id`
+
+// likeEscaper는 사용자 입력이 LIKE 패턴의 와일드카드로 해석되지 않도록 이스케이프합니다.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSnippet(row scanner) (*Snippet, error) {
+	s := &Snippet{}
+	err := row.Scan(&s.ID, &s.UserID, &s.Author, &s.Title, &s.Content, &s.Created, &s.Expires, &s.Views)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
 }
 
 // sql.DB connection 풀을 감싸는 SnippetModel 유형을 정의합니다.
@@ -25,19 +64,26 @@ type SnippetModel struct {
 	DB *sql.DB
 }
 
-func (m *SnippetModel) Insert(title string, content string, expires int) (int, error) {
+func (m *SnippetModel) Insert(userID int, title string, content string, expires int, tags []string) (int, error) {
 	// 실행할 SQL 문을 작성합니다. 가독성을 위해 두 줄로 나누었습니다.
 	// 가독성을 위해 (일반 큰따옴표 대신에
 	// 큰따옴표로 묶은 이유입니다).
-	stmt := `INSERT INTO snippets (title, content, created, expires)
-	VALUES(?, ?, UTC_TIMESTAMP(), DATE_ADD(UTC_TIMESTAMP(), INTERVAL ? DAY))`
+	stmt := `INSERT INTO snippets (user_id, title, content, created, expires)
+	VALUES(?, ?, ?, UTC_TIMESTAMP(), DATE_ADD(UTC_TIMESTAMP(), INTERVAL ? DAY))`
+
+	// 스니펫과 태그가 함께 저장되도록 트랜잭션을 사용합니다.
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
 
 	// 임베디드 연결 풀에서 Exec() 메서드를 사용하여
 	// 문을 실행합니다. 첫 번째 매개변수는 SQL 문이며, 그 뒤에 플레이스홀더 매개변수에 대한
 	// 플레이스홀더 매개변수의 제목, 내용 및 만료 값입니다. 이
 	// 메서드는 몇 가지 기본 정보를 포함하는 sql.Result 유형을 반환합니다.
 	// 문이 실행되었을 때 어떤 일이 일어났는지에 대한 몇 가지 기본 정보가 포함된 쿼리 결과 유형을 반환합니다.
-	result, err := m.DB.Exec(stmt, title, content, expires)
+	result, err := tx.Exec(stmt, userID, title, content, expires)
 	if err != nil {
 		return 0, err
 	}
@@ -49,6 +95,18 @@ func (m *SnippetModel) Insert(title string, content string, expires int) (int, e
 		return 0, err
 	}
 
+	for _, tag := range tags {
+		_, err = tx.Exec("INSERT INTO snippet_tags (snippet_id, tag) VALUES(?, ?)", id, tag)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
 	// 반환된 ID의 유형이 int64이므로 반환하기 전에 int 유형으로 변환합니다.
 	return int(id), nil
 }
@@ -56,9 +114,8 @@ func (m *SnippetModel) Insert(title string, content string, expires int) (int, e
 // 해당 ID를 기반으로 특정 스니펫이 반환됩니다.
 func (m *SnippetModel) Get(id int) (*Snippet, error) {
 
-	s := &Snippet{}
-	err := m.DB.QueryRow(`SELECT id, title, content, created, expires FROM snippets
-	WHERE expires > UTC_TIMESTAMP() AND id = ?`, id).Scan(&s.ID, &s.Title, &s.Content, &s.Created, &s.Expires)
+	s, err := scanSnippet(m.DB.QueryRow(`SELECT `+snippetColumns+`
+	WHERE s.expires > UTC_TIMESTAMP() AND s.id = ?`, id))
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -67,18 +124,39 @@ func (m *SnippetModel) Get(id int) (*Snippet, error) {
 			return nil, err
 		}
 	}
+
+	err = m.loadTags([]*Snippet{s})
+	if err != nil {
+		return nil, err
+	}
 	return s, nil
 }
 
 // 가장 최근에 생성된 10개 스니펫이 반환됩니다.
 func (m *SnippetModel) Latest() ([]*Snippet, error) {
+	return m.latest("")
+}
+
+// 주어진 사용자가 작성한 스니펫 중 가장 최근 10개가 반환됩니다.
+func (m *SnippetModel) LatestByUser(userID int) ([]*Snippet, error) {
+	return m.latest("AND s.user_id = ?", userID)
+}
+
+// 주어진 태그가 붙은 스니펫 중 가장 최근 10개가 반환됩니다.
+func (m *SnippetModel) LatestByTag(tag string) ([]*Snippet, error) {
+	return m.latest("AND s.id IN (SELECT snippet_id FROM snippet_tags WHERE tag = ?)", tag)
+}
+
+// latest는 Latest()와 피드가 공유하는 쿼리입니다. filter는 WHERE 절에 덧붙일 조건이며
+// args는 그 조건의 플레이스홀더 값입니다.
+func (m *SnippetModel) latest(filter string, args ...any) ([]*Snippet, error) {
 	// 실행할 SQL 문을 작성합니다.
-	stmt := `SELECT id, title, content, created, expires FROM snippets
-	WHERE expires > UTC_TIMESTAMP() ORDER BY id DESC LIMIT 10`
+	stmt := `SELECT ` + snippetColumns + `
+	WHERE s.expires > UTC_TIMESTAMP() ` + filter + ` ORDER BY s.id DESC LIMIT 10`
 
 	// 연결 풀에서 Query() 메서드를 사용하여
 	// SQL 문을 실행합니다. 그러면 쿼리 결과가 포함된 sql.Rows 결과 집합이 반환됩니다.
-	rows, err := m.DB.Query(stmt)
+	rows, err := m.DB.Query(stmt, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -96,13 +174,11 @@ func (m *SnippetModel) Latest() ([]*Snippet, error) {
 	// 모든 행에 대한 반복이 완료되면 결과 집합이 자동으로 닫히고
 	//기본 데이터베이스 연결을 해제합니다.
 	for rows.Next() {
-		// 0이 된 새로운 코드조각 구조체에 대한 포인터를 생성합니다.
-		s := &Snippet{}
 		// 행의 각 필드에서 값을 행의 각 필드에 있는
 		// 새 코드조각 객체로 복사합니다. 다시 말하지만, row.Scan()의 인수는 데이터를 복사하려는 위치에 대한 포인터여야 하며,
 		// 인수의 수는 문에서 반환된 열의 수와 정확히 같아야 합니다.
 		// 열의 수와 정확히 같아야 합니다.
-		err = rows.Scan(&s.ID, &s.Title, &s.Content, &s.Created, &s.Expires)
+		s, err := scanSnippet(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -115,6 +191,403 @@ func (m *SnippetModel) Latest() ([]*Snippet, error) {
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
+
+	err = m.loadTags(snippets)
+	if err != nil {
+		return nil, err
+	}
 	// 모든 것이 정상적으로 진행되었다면 코드조각 조각을 반환합니다.
 	return snippets, nil
 }
+
+// loadTags는 주어진 스니펫들의 태그를 한 번의 쿼리로 가져와 Tags 필드를 채웁니다.
+func (m *SnippetModel) loadTags(snippets []*Snippet) error {
+	if len(snippets) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*Snippet, len(snippets))
+	args := make([]any, 0, len(snippets))
+
+	for _, s := range snippets {
+		s.Tags = []string{}
+		byID[s.ID] = s
+		args = append(args, s.ID)
+	}
+
+	rows, err := m.DB.Query(`SELECT snippet_id, tag FROM snippet_tags
+	WHERE snippet_id IN (`+placeholders(len(args))+`) ORDER BY tag`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var tag string
+
+		err = rows.Scan(&id, &tag)
+		if err != nil {
+			return err
+		}
+		byID[id].Tags = append(byID[id].Tags, tag)
+	}
+
+	return rows.Err()
+}
+
+// placeholders는 IN 절에 사용할 n개의 플레이스홀더 목록("?, ?, ?")을 반환합니다.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// List는 만료되지 않은 스니펫을 최신순으로 page번째 페이지(1부터 시작)만큼 반환하며,
+// 두 번째 반환값은 전체 스니펫 수입니다. query가 비어 있지 않으면 제목이나 내용에
+// query가 포함된 스니펫만 반환합니다.
+//
+// JSON API(/api/v1)와 gRPC의 List는 이미 페이지 번호와 전체 수를 응답 형식으로
+// 공개했으므로 OFFSET 방식을 유지합니다. 조건은 Page()와 같은 SnippetFilter.where()로
+// 만들고 쿼리도 같은 find()를 사용합니다.
+func (m *SnippetModel) List(query string, page, pageSize int) ([]*Snippet, int, error) {
+	p, err := m.find(SnippetFilter{Query: query}, 0, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return p.Snippets, p.Total, nil
+}
+
+// Update는 스니펫의 제목, 내용, 태그를 바꿉니다. 태그는 주어진 목록으로 통째로 교체됩니다.
+func (m *SnippetModel) Update(id int, title string, content string, tags []string) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("UPDATE snippets SET title = ?, content = ? WHERE id = ?", title, content, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM snippet_tags WHERE snippet_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		_, err = tx.Exec("INSERT INTO snippet_tags (snippet_id, tag) VALUES(?, ?)", id, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Delete는 스니펫과 그 스니펫을 참조하는 태그, 컬렉션 항목, 조회수 기록을 모두 삭제합니다.
+// 스니펫이 없으면 ErrNoRecord를 반환합니다.
+func (m *SnippetModel) Delete(id int) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range []string{
+		"DELETE FROM snippet_tags WHERE snippet_id = ?",
+		"DELETE FROM collection_snippets WHERE snippet_id = ?",
+		"DELETE FROM snippet_views WHERE snippet_id = ?",
+		"DELETE FROM snippet_trending WHERE snippet_id = ?",
+		"DELETE FROM snippet_revisions WHERE snippet_id = ?",
+		"DELETE FROM snippet_collaborators WHERE snippet_id = ?",
+	} {
+		_, err = tx.Exec(stmt, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.Exec("DELETE FROM snippets WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoRecord
+	}
+
+	return tx.Commit()
+}
+
+// AddViews는 스니펫 ID별로 집계된 조회수를 하나의 트랜잭션으로 views 열에 더합니다.
+// 조회 요청마다 UPDATE를 실행하지 않도록 백그라운드 플러셔에서만 호출됩니다.
+func (m *SnippetModel) AddViews(counts map[int]int) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	// Commit()이 성공한 뒤의 Rollback()은 아무 일도 하지 않으므로 항상 지연 호출해도 안전합니다.
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare("UPDATE snippets SET views = views + ? WHERE id = ?")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	// 인기 순위 계산에 사용할 수 있도록 조회수를 한 시간 단위 버킷에도 누적합니다.
+	bucketStmt, err := tx.Prepare(`INSERT INTO snippet_views (snippet_id, bucket, views)
+	VALUES(?, DATE_FORMAT(UTC_TIMESTAMP(), '%Y-%m-%d %H:00:00'), ?)
+	ON DUPLICATE KEY UPDATE views = views + VALUES(views)`)
+	if err != nil {
+		return err
+	}
+	defer bucketStmt.Close()
+
+	for id, n := range counts {
+		_, err = stmt.Exec(n, id)
+		if err != nil {
+			return err
+		}
+		_, err = bucketStmt.Exec(id, n)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SnippetFilter는 Page()로 가져올 스니펫의 조건입니다. 비어 있는 필드는 조건에서 빠집니다.
+type SnippetFilter struct {
+	Query  string
+	Tag    string
+	UserID int
+}
+
+// where는 filter에 맞는 만료되지 않은 스니펫의 WHERE 절과 플레이스홀더 값을 반환합니다.
+// 스니펫 목록을 가져오는 쿼리는 모두 이 함수로 조건을 만듭니다.
+func (f SnippetFilter) where() (string, []any) {
+	where := "s.expires > UTC_TIMESTAMP()"
+	args := []any{}
+
+	if f.Query != "" {
+		pattern := "%" + likeEscaper.Replace(f.Query) + "%"
+		where += " AND (s.title LIKE ? OR s.content LIKE ?)"
+		args = append(args, pattern, pattern)
+	}
+	if f.Tag != "" {
+		where += " AND s.id IN (SELECT snippet_id FROM snippet_tags WHERE tag = ?)"
+		args = append(args, f.Tag)
+	}
+	if f.UserID != 0 {
+		where += " AND s.user_id = ?"
+		args = append(args, f.UserID)
+	}
+
+	return where, args
+}
+
+// SnippetPage는 커서 기반 목록의 한 페이지입니다. Total은 커서와 관계없이 조건에 맞는
+// 전체 스니펫 수입니다.
+type SnippetPage struct {
+	Snippets []*Snippet
+	Total    int
+}
+
+// scanSnippets는 scanSnippet()으로 모든 행을 읽고 태그를 채웁니다.
+func (m *SnippetModel) scanSnippets(rows *sql.Rows) ([]*Snippet, error) {
+	defer rows.Close()
+
+	snippets := []*Snippet{}
+
+	for rows.Next() {
+		s, err := scanSnippet(rows)
+		if err != nil {
+			return nil, err
+		}
+		snippets = append(snippets, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err := m.loadTags(snippets)
+	if err != nil {
+		return nil, err
+	}
+
+	return snippets, nil
+}
+
+// GetMany는 주어진 ID의 만료되지 않은 스니펫을 한 번의 쿼리로 가져옵니다. 없거나 만료된
+// 스니펫은 결과에서 빠지며, 순서는 정해져 있지 않습니다.
+func (m *SnippetModel) GetMany(ids []int) ([]*Snippet, error) {
+	if len(ids) == 0 {
+		return []*Snippet{}, nil
+	}
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	rows, err := m.DB.Query(`SELECT `+snippetColumns+`
+	WHERE s.expires > UTC_TIMESTAMP() AND s.id IN (`+placeholders(len(ids))+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.scanSnippets(rows)
+}
+
+// Page는 filter에 맞는 만료되지 않은 스니펫을 최신순으로 최대 limit개 반환합니다.
+// after가 0보다 크면 ID가 after보다 작은 스니펫, 즉 이전 페이지의 마지막 스니펫
+// 다음부터 가져옵니다. 페이지 번호 대신 ID를 커서로 쓰므로 그 사이에 새 스니펫이
+// 추가되어도 결과가 밀리지 않습니다.
+func (m *SnippetModel) Page(filter SnippetFilter, after, limit int) (*SnippetPage, error) {
+	return m.find(filter, after, limit, 0)
+}
+
+// find는 List()와 Page()가 공유하는 쿼리입니다. Total은 after와 offset을 적용하기 전의
+// 스니펫 수입니다.
+func (m *SnippetModel) find(filter SnippetFilter, after, limit, offset int) (*SnippetPage, error) {
+	where, args := filter.where()
+
+	page := &SnippetPage{}
+
+	err := m.DB.QueryRow("SELECT COUNT(*) FROM snippets s WHERE "+where, args...).Scan(&page.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	if after > 0 {
+		where += " AND s.id < ?"
+		args = append(args, after)
+	}
+
+	rows, err := m.DB.Query(`SELECT `+snippetColumns+`
+	WHERE `+where+` ORDER BY s.id DESC LIMIT ? OFFSET ?`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, err
+	}
+
+	page.Snippets, err = m.scanSnippets(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// PageByUsers는 여러 사용자에 대해 Page(SnippetFilter{UserID: id}, after, limit)를
+// 두 번의 쿼리로 한꺼번에 실행합니다. 결과에는 모든 userIDs가 키로 들어 있습니다.
+func (m *SnippetModel) PageByUsers(userIDs []int, after, limit int) (map[int]*SnippetPage, error) {
+	pages := make(map[int]*SnippetPage, len(userIDs))
+	if len(userIDs) == 0 {
+		return pages, nil
+	}
+
+	// 사용자 조건은 IN 절로 바꾸고 나머지 조건은 Page()와 같은 where()로 만듭니다.
+	where, args := SnippetFilter{}.where()
+	where += " AND s.user_id IN (" + placeholders(len(userIDs)) + ")"
+	for _, id := range userIDs {
+		args = append(args, id)
+		pages[id] = &SnippetPage{Snippets: []*Snippet{}}
+	}
+
+	rows, err := m.DB.Query(`SELECT s.user_id, COUNT(*) FROM snippets s
+	WHERE `+where+` GROUP BY s.user_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, total int
+
+		err = rows.Scan(&id, &total)
+		if err != nil {
+			return nil, err
+		}
+		pages[id].Total = total
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if after > 0 {
+		where += " AND s.id < ?"
+		args = append(args, after)
+	}
+
+	// ROW_NUMBER()로 사용자별 순위를 매겨 사용자마다 최신 limit개만 남깁니다.
+	stmt := `SELECT id, user_id, author, title, content, created, expires, views FROM (
+		SELECT s.id, s.user_id, COALESCE(u.name, '') AS author, s.title, s.content, s.created, s.expires, s.views,
+		ROW_NUMBER() OVER (PARTITION BY s.user_id ORDER BY s.id DESC) AS n
+		FROM snippets s LEFT JOIN users u ON u.id = s.user_id
+		WHERE ` + where + `
+	) ranked WHERE n <= ? ORDER BY user_id, id DESC`
+
+	snippetRows, err := m.DB.Query(stmt, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+
+	snippets, err := m.scanSnippets(snippetRows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range snippets {
+		pages[s.UserID].Snippets = append(pages[s.UserID].Snippets, s)
+	}
+
+	return pages, nil
+}
+
+// TagCounts는 각 태그가 붙은 만료되지 않은 스니펫 수를 반환합니다. 결과에는 모든 tags가
+// 키로 들어 있습니다.
+func (m *SnippetModel) TagCounts(tags []string) (map[string]int, error) {
+	counts := make(map[string]int, len(tags))
+	if len(tags) == 0 {
+		return counts, nil
+	}
+
+	args := make([]any, len(tags))
+	for i, tag := range tags {
+		args[i] = tag
+		counts[tag] = 0
+	}
+
+	rows, err := m.DB.Query(`SELECT t.tag, COUNT(*) FROM snippet_tags t
+	JOIN snippets s ON s.id = t.snippet_id
+	WHERE s.expires > UTC_TIMESTAMP() AND t.tag IN (`+placeholders(len(args))+`)
+	GROUP BY t.tag`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag string
+		var n int
+
+		err = rows.Scan(&tag, &n)
+		if err != nil {
+			return nil, err
+		}
+		counts[tag] = n
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+
+	"snippetbox.wook.net/internal/assert"
+)
+
+func TestSnippetModelPageByUsers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("models: skipping integration test")
+	}
+
+	db := newTestDB(t)
+
+	// Alice(사용자 1)는 스니펫 1, 2, 4를, 사용자 2는 만료된 스니펫 3만 가지고 있습니다.
+	_, err := db.Exec(`INSERT INTO snippets (user_id, title, content, created, expires) VALUES
+	(1, 'First', 'First', UTC_TIMESTAMP(), DATE_ADD(UTC_TIMESTAMP(), INTERVAL 1 DAY)),
+	(1, 'Second', 'Second', UTC_TIMESTAMP(), DATE_ADD(UTC_TIMESTAMP(), INTERVAL 1 DAY)),
+	(2, 'Expired', 'Expired', UTC_TIMESTAMP(), DATE_SUB(UTC_TIMESTAMP(), INTERVAL 1 MINUTE)),
+	(1, 'Third', 'Third', UTC_TIMESTAMP(), DATE_ADD(UTC_TIMESTAMP(), INTERVAL 1 DAY))`)
+	assert.NilError(t, err)
+
+	_, err = db.Exec("INSERT INTO snippet_tags (snippet_id, tag) VALUES (4, 'haiku')")
+	assert.NilError(t, err)
+
+	m := SnippetModel{db}
+
+	tests := []struct {
+		name      string
+		after     int
+		limit     int
+		wantIDs   string
+		wantTotal int
+	}{
+		{"First page", 0, 2, "[4 2]", 3},
+		{"Next page", 2, 2, "[1]", 3},
+		{"Past the end", 1, 2, "[]", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages, err := m.PageByUsers([]int{1, 2, 99}, tt.after, tt.limit)
+			assert.NilError(t, err)
+			assert.Equal(t, len(pages), 3)
+
+			ids := []int{}
+			for _, s := range pages[1].Snippets {
+				assert.Equal(t, s.UserID, 1)
+				assert.Equal(t, s.Author, "Alice Jones")
+				ids = append(ids, s.ID)
+			}
+			assert.Equal(t, fmt.Sprint(ids), tt.wantIDs)
+			assert.Equal(t, pages[1].Total, tt.wantTotal)
+
+			// 만료된 스니펫만 있거나 없는 사용자도 빈 페이지로 들어 있습니다.
+			for _, id := range []int{2, 99} {
+				assert.Equal(t, len(pages[id].Snippets), 0)
+				assert.Equal(t, pages[id].Total, 0)
+			}
+		})
+	}
+
+	pages, err := m.PageByUsers([]int{1}, 0, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, fmt.Sprint(pages[1].Snippets[0].Tags), "[haiku]")
+
+	pages, err = m.PageByUsers([]int{}, 0, 10)
+	assert.NilError(t, err)
+	assert.Equal(t, len(pages), 0)
+}
+
+func TestSnippetModelListAndPage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("models: skipping integration test")
+	}
+
+	db := newTestDB(t)
+
+	_, err := db.Exec(`INSERT INTO snippets (user_id, title, content, created, expires) VALUES
+	(1, 'Go', 'First', UTC_TIMESTAMP(), DATE_ADD(UTC_TIMESTAMP(), INTERVAL 1 DAY)),
+	(1, 'Go', 'Second', UTC_TIMESTAMP(), DATE_ADD(UTC_TIMESTAMP(), INTERVAL 1 DAY)),
+	(1, 'Go', 'Expired', UTC_TIMESTAMP(), DATE_SUB(UTC_TIMESTAMP(), INTERVAL 1 MINUTE)),
+	(1, 'Rust', 'Third', UTC_TIMESTAMP(), DATE_ADD(UTC_TIMESTAMP(), INTERVAL 1 DAY)),
+	(1, 'Go', 'Fourth', UTC_TIMESTAMP(), DATE_ADD(UTC_TIMESTAMP(), INTERVAL 1 DAY))`)
+	assert.NilError(t, err)
+
+	m := SnippetModel{db}
+
+	// 페이지 번호로 가져온 List()와 커서로 가져온 Page()는 같은 조건으로 같은 결과를 냅니다.
+	tests := []struct {
+		name      string
+		page      int
+		after     int
+		wantIDs   string
+		wantTotal int
+	}{
+		{"First page", 1, 0, "[5 2]", 3},
+		{"Second page", 2, 2, "[1]", 3},
+		{"Past the end", 3, 1, "[]", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snippets, total, err := m.List("Go", tt.page, 2)
+			assert.NilError(t, err)
+
+			ids := []int{}
+			for _, s := range snippets {
+				ids = append(ids, s.ID)
+			}
+			assert.Equal(t, fmt.Sprint(ids), tt.wantIDs)
+			assert.Equal(t, total, tt.wantTotal)
+
+			page, err := m.Page(SnippetFilter{Query: "Go"}, tt.after, 2)
+			assert.NilError(t, err)
+
+			ids = []int{}
+			for _, s := range page.Snippets {
+				ids = append(ids, s.ID)
+			}
+			assert.Equal(t, fmt.Sprint(ids), tt.wantIDs)
+			assert.Equal(t, page.Total, tt.wantTotal)
+		})
+	}
+}
@@ -1,32 +1,198 @@
+-- 이 스키마는 기준 스키마에 migrations/의 마이그레이션을 차례로 적용한 결과와 같아야 합니다.
+-- 테이블이나 열을 바꿀 때는 새 마이그레이션을 추가하고 이 파일도 함께 수정합니다.
 CREATE TABLE snippets (
     id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    user_id INTEGER NOT NULL DEFAULT 0,
     title VARCHAR(100) NOT NULL,
     content TEXT NOT NULL,
     created DATETIME NOT NULL,
-    expires DATETIME NOT NULL
+    expires DATETIME NOT NULL,
+    views INTEGER NOT NULL DEFAULT 0
 );
 
 CREATE INDEX idx_snippets_created ON snippets(created);
 
+CREATE INDEX idx_snippets_user_id ON snippets(user_id);
+
+CREATE TABLE snippet_tags (
+    snippet_id INTEGER NOT NULL,
+    tag VARCHAR(30) NOT NULL,
+    PRIMARY KEY (snippet_id, tag)
+);
+
+CREATE INDEX idx_snippet_tags_tag ON snippet_tags(tag);
+
+CREATE TABLE snippet_views (
+    snippet_id INTEGER NOT NULL,
+    bucket DATETIME NOT NULL,
+    views INTEGER NOT NULL,
+    PRIMARY KEY (snippet_id, bucket)
+);
+
+CREATE TABLE snippet_trending (
+    period VARCHAR(8) NOT NULL,
+    snippet_id INTEGER NOT NULL,
+    score DOUBLE NOT NULL,
+    computed DATETIME NOT NULL,
+    PRIMARY KEY (period, snippet_id)
+);
+
 CREATE TABLE users (
     id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
     name VARCHAR(255) NOT NULL,
     email VARCHAR(255) NOT NULL,
     hashed_password CHAR(60) NOT NULL,
+    created DATETIME NOT NULL,
+    verified_at DATETIME NULL
+);
+
+CREATE TABLE collections (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    public BOOLEAN NOT NULL DEFAULT FALSE,
+    created DATETIME NOT NULL
+);
+
+CREATE INDEX idx_collections_user_id ON collections(user_id);
+
+CREATE TABLE collection_snippets (
+    collection_id INTEGER NOT NULL,
+    snippet_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (collection_id, snippet_id)
+);
+
+CREATE TABLE tokens (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    scopes VARCHAR(255) NOT NULL,
+    hash BINARY(32) NOT NULL,
+    created DATETIME NOT NULL,
+    last_used DATETIME NULL,
+    CONSTRAINT tokens_uc_hash UNIQUE (hash)
+);
+
+CREATE INDEX idx_tokens_user_id ON tokens(user_id);
+
+CREATE TABLE snippet_revisions (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    snippet_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    content TEXT NOT NULL,
     created DATETIME NOT NULL
 );
 
+CREATE INDEX idx_snippet_revisions_snippet_id ON snippet_revisions(snippet_id);
+
+CREATE TABLE snippet_collaborators (
+    snippet_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    created DATETIME NOT NULL,
+    PRIMARY KEY (snippet_id, user_id)
+);
+
+CREATE INDEX idx_snippet_collaborators_user_id ON snippet_collaborators(user_id);
+
+CREATE TABLE webhooks (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    user_id INTEGER NOT NULL,
+    url VARCHAR(2048) NOT NULL,
+    secret CHAR(48) NOT NULL,
+    created DATETIME NOT NULL
+);
+
+CREATE INDEX idx_webhooks_user_id ON webhooks(user_id);
+
+CREATE TABLE webhook_deliveries (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    webhook_id INTEGER NOT NULL,
+    event VARCHAR(32) NOT NULL,
+    snippet_id INTEGER NOT NULL,
+    payload MEDIUMBLOB NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_status INTEGER NOT NULL DEFAULT 0,
+    last_error VARCHAR(255) NOT NULL DEFAULT '',
+    next_attempt DATETIME NOT NULL,
+    created DATETIME NOT NULL
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt);
+
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, snippet_id, event);
+
+CREATE TABLE password_resets (
+    hash BINARY(32) NOT NULL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    expires DATETIME NOT NULL,
+    created DATETIME NOT NULL
+);
+
+CREATE INDEX idx_password_resets_user_id ON password_resets(user_id);
+
+CREATE TABLE outbox (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    recipient VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    text_body MEDIUMTEXT NOT NULL,
+    html_body MEDIUMTEXT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error VARCHAR(255) NOT NULL DEFAULT '',
+    next_attempt DATETIME NOT NULL,
+    created DATETIME NOT NULL
+);
+
+CREATE INDEX idx_outbox_due ON outbox(status, next_attempt);
+
+CREATE TABLE two_factor (
+    user_id INTEGER NOT NULL PRIMARY KEY,
+    secret VARBINARY(64) NOT NULL,
+    last_step BIGINT NOT NULL DEFAULT 0,
+    created DATETIME NOT NULL
+);
+
+CREATE TABLE user_sessions (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    user_id INTEGER NOT NULL,
+    token CHAR(43) NOT NULL,
+    user_agent VARCHAR(255) NOT NULL,
+    ip VARCHAR(45) NOT NULL,
+    created DATETIME NOT NULL,
+    last_seen DATETIME NOT NULL
+);
+
+CREATE INDEX idx_user_sessions_user_id ON user_sessions(user_id);
+
+-- scs의 mysqlstore가 사용하는 세션 저장소입니다. 기준 스키마에 이미 있으므로 마이그레이션은 없습니다.
+CREATE TABLE sessions (
+    token CHAR(43) PRIMARY KEY,
+    data BLOB NOT NULL,
+    expiry TIMESTAMP(6) NOT NULL
+);
+
+CREATE INDEX sessions_expiry_idx ON sessions (expiry);
+
+CREATE TABLE recovery_codes (
+    user_id INTEGER NOT NULL,
+    hash BINARY(32) NOT NULL,
+    PRIMARY KEY (user_id, hash)
+);
+
 ALTER TABLE
     users
 ADD
     CONSTRAINT users_uc_email UNIQUE (email);
 
 INSERT INTO
-    users (name, email, hashed_password, created)
+    users (name, email, hashed_password, created, verified_at)
 VALUES
     (
         'Alice Jones',
         'alice@example.com',
         '$2a$12$NuTjWXm3KKntReFwyBVHyuf/to.HEwTy.eS206TNfkGfr6HzGJSWG',
-        '2022-01-01 10:00:00'
+        '2022-01-01 10:00:00',
+        '2022-01-01 10:05:00'
     );
\ No newline at end of file
@@ -1,3 +1,35 @@
+DROP TABLE snippet_collaborators;
+
+DROP TABLE sessions;
+
+DROP TABLE user_sessions;
+
+DROP TABLE recovery_codes;
+
+DROP TABLE two_factor;
+
+DROP TABLE password_resets;
+
+DROP TABLE outbox;
+
+DROP TABLE snippet_revisions;
+
+DROP TABLE webhook_deliveries;
+
+DROP TABLE webhooks;
+
+DROP TABLE tokens;
+
+DROP TABLE collection_snippets;
+
+DROP TABLE collections;
+
+DROP TABLE snippet_trending;
+
+DROP TABLE snippet_views;
+
 DROP TABLE users;
 
+DROP TABLE snippet_tags;
+
 DROP TABLE snippets;
\ No newline at end of file
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+)
+
+// 개인 액세스 토큰에 부여할 수 있는 권한 범위입니다.
+const (
+	ScopeSnippetsRead  = "snippets:read"
+	ScopeSnippetsWrite = "snippets:write"
+)
+
+// Scopes는 토큰에 부여할 수 있는 모든 권한 범위입니다.
+var Scopes = []string{ScopeSnippetsRead, ScopeSnippetsWrite}
+
+// tokenPrefix는 평문 토큰 앞에 붙여서 비밀 정보 스캐너가 토큰을 알아볼 수 있게 합니다.
+const tokenPrefix = "sbx_"
+
+type TokenModelInterface interface {
+	Insert(userID int, name string, scopes []string) (string, error)
+	ForUser(userID int) ([]*Token, error)
+	Revoke(userID, id int) error
+	Authenticate(plaintext string) (*Token, error)
+}
+
+// Token은 API 요청에 사용하는 개인 액세스 토큰입니다. 데이터베이스에는 토큰의
+// SHA-256 해시만 저장되므로 평문 토큰은 생성 직후 한 번만 알 수 있습니다.
+type Token struct {
+	ID       int
+	UserID   int
+	Name     string
+	Scopes   []string
+	Created  time.Time
+	LastUsed time.Time
+}
+
+// HasScope는 토큰에 주어진 권한 범위가 있으면 true를 반환합니다.
+func (t *Token) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type TokenModel struct {
+	DB *sql.DB
+}
+
+func hashToken(plaintext string) []byte {
+	hash := sha256.Sum256([]byte(plaintext))
+	return hash[:]
+}
+
+// Insert는 새 토큰을 생성하여 해시를 저장하고 평문 토큰을 반환합니다.
+func (m *TokenModel) Insert(userID int, name string, scopes []string) (string, error) {
+	randomBytes := make([]byte, 20)
+
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext := tokenPrefix + strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(randomBytes))
+
+	stmt := `INSERT INTO tokens (user_id, name, scopes, hash, created)
+	VALUES(?, ?, ?, ?, UTC_TIMESTAMP())`
+
+	_, err = m.DB.Exec(stmt, userID, name, strings.Join(scopes, ","), hashToken(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	return plaintext, nil
+}
+
+func (m *TokenModel) ForUser(userID int) ([]*Token, error) {
+	stmt := `SELECT id, user_id, name, scopes, created, last_used FROM tokens
+	WHERE user_id = ? ORDER BY created DESC`
+
+	rows, err := m.DB.Query(stmt, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []*Token{}
+
+	for rows.Next() {
+		t, err := scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Revoke는 사용자가 소유한 토큰을 삭제합니다. 해당 사용자의 토큰이 아니면 ErrNoRecord를 반환합니다.
+func (m *TokenModel) Revoke(userID, id int) error {
+	result, err := m.DB.Exec("DELETE FROM tokens WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
+
+// Authenticate는 평문 토큰에 해당하는 토큰을 찾아 마지막 사용 시각을 갱신합니다.
+// 일치하는 토큰이 없으면 ErrInvalidCredentials를 반환합니다.
+func (m *TokenModel) Authenticate(plaintext string) (*Token, error) {
+	if !strings.HasPrefix(plaintext, tokenPrefix) {
+		return nil, ErrInvalidCredentials
+	}
+
+	hash := hashToken(plaintext)
+
+	stmt := `SELECT id, user_id, name, scopes, created, last_used FROM tokens WHERE hash = ?`
+
+	t, err := scanToken(m.DB.QueryRow(stmt, hash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidCredentials
+		} else {
+			return nil, err
+		}
+	}
+
+	_, err = m.DB.Exec("UPDATE tokens SET last_used = UTC_TIMESTAMP() WHERE id = ?", t.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func scanToken(row scanner) (*Token, error) {
+	t := &Token{}
+
+	var scopes string
+	var lastUsed sql.NullTime
+
+	err := row.Scan(&t.ID, &t.UserID, &t.Name, &scopes, &t.Created, &lastUsed)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Scopes = strings.Split(scopes, ",")
+	t.LastUsed = lastUsed.Time
+
+	return t, nil
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"database/sql"
+)
+
+// 인기 순위를 계산하는 기간입니다. PeriodAll은 감쇠 없이 누적 조회수로 정렬한
+// '가장 많이 본' 순위입니다.
+const (
+	Period24h = "24h"
+	Period7d  = "7d"
+	Period30d = "30d"
+	PeriodAll = "all"
+)
+
+// trendingPeriods는 각 기간의 범위(시간)와 점수의 반감기(시간)를 정의합니다.
+// 반감기가 지난 조회수는 점수에 절반만 반영됩니다.
+var trendingPeriods = []struct {
+	name     string
+	hours    int
+	halfLife float64
+}{
+	{Period24h, 24, 6},
+	{Period7d, 7 * 24, 2 * 24},
+	{Period30d, 30 * 24, 7 * 24},
+}
+
+// 각 기간마다 캐시 테이블에 저장하는 최대 스니펫 수입니다.
+const trendingLimit = 50
+
+type TrendingModelInterface interface {
+	Refresh() error
+	Get(period string, limit int) ([]*Ranking, error)
+}
+
+type Ranking struct {
+	Rank    int
+	Score   float64
+	Snippet *Snippet
+}
+
+type TrendingModel struct {
+	DB *sql.DB
+}
+
+// Refresh는 snippet_views 버킷으로부터 모든 기간의 순위를 다시 계산하여
+// snippet_trending 캐시 테이블을 한 트랜잭션 안에서 교체합니다.
+// 만료된 스니펫은 계산에서 제외됩니다.
+func (m *TrendingModel) Refresh() error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM snippet_trending")
+	if err != nil {
+		return err
+	}
+
+	// 각 버킷의 조회수에 경과 시간에 따른 지수 감쇠를 적용하여 합산합니다.
+	stmt := `INSERT INTO snippet_trending (period, snippet_id, score, computed)
+	SELECT ?, v.snippet_id,
+		SUM(v.views * EXP(-LN(2) * TIMESTAMPDIFF(MINUTE, v.bucket, UTC_TIMESTAMP()) / 60 / ?)) AS score,
+		UTC_TIMESTAMP()
+	FROM snippet_views v
+	JOIN snippets s ON s.id = v.snippet_id
+	WHERE v.bucket > DATE_SUB(UTC_TIMESTAMP(), INTERVAL ? HOUR) AND s.expires > UTC_TIMESTAMP()
+	GROUP BY v.snippet_id
+	ORDER BY score DESC
+	LIMIT ?`
+
+	for _, p := range trendingPeriods {
+		_, err = tx.Exec(stmt, p.name, p.halfLife, p.hours, trendingLimit)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`INSERT INTO snippet_trending (period, snippet_id, score, computed)
+	SELECT ?, id, views, UTC_TIMESTAMP() FROM snippets
+	WHERE expires > UTC_TIMESTAMP() AND views > 0
+	ORDER BY views DESC
+	LIMIT ?`, PeriodAll, trendingLimit)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Get은 캐시된 순위를 점수가 높은 순서로 반환합니다. 순위가 계산된 이후에
+// 만료된 스니펫은 여기서 다시 한 번 걸러냅니다.
+func (m *TrendingModel) Get(period string, limit int) ([]*Ranking, error) {
+	stmt := `SELECT t.score, s.id, s.title, s.content, s.created, s.expires, s.views
+	FROM snippet_trending t
+	JOIN snippets s ON s.id = t.snippet_id
+	WHERE t.period = ? AND s.expires > UTC_TIMESTAMP()
+	ORDER BY t.score DESC
+	LIMIT ?`
+
+	rows, err := m.DB.Query(stmt, period, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rankings := []*Ranking{}
+
+	for rows.Next() {
+		r := &Ranking{Rank: len(rankings) + 1, Snippet: &Snippet{}}
+		s := r.Snippet
+
+		err = rows.Scan(&r.Score, &s.ID, &s.Title, &s.Content, &s.Created, &s.Expires, &s.Views)
+		if err != nil {
+			return nil, err
+		}
+
+		rankings = append(rankings, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rankings, nil
+}
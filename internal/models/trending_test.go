@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+
+	"snippetbox.wook.net/internal/assert"
+)
+
+func TestTrendingModelRefresh(t *testing.T) {
+	if testing.Short() {
+		t.Skip("models: skipping integration test")
+	}
+
+	db := newTestDB(t)
+
+	// 스니펫 1은 한 시간 전에, 스니펫 2는 사흘 전에 조회되었습니다. 스니펫 3은 가장 많이
+	// 조회되었지만 만료되었으므로 어느 순위에도 들어가지 않습니다.
+	_, err := db.Exec(`INSERT INTO snippets (title, content, created, expires, views) VALUES
+	('Fresh', 'Fresh', UTC_TIMESTAMP(), DATE_ADD(UTC_TIMESTAMP(), INTERVAL 1 DAY), 10),
+	('Older', 'Older', UTC_TIMESTAMP(), DATE_ADD(UTC_TIMESTAMP(), INTERVAL 1 DAY), 40),
+	('Expired', 'Expired', UTC_TIMESTAMP(), DATE_SUB(UTC_TIMESTAMP(), INTERVAL 1 MINUTE), 100)`)
+	assert.NilError(t, err)
+
+	_, err = db.Exec(`INSERT INTO snippet_views (snippet_id, bucket, views) VALUES
+	(1, DATE_SUB(UTC_TIMESTAMP(), INTERVAL 1 HOUR), 10),
+	(2, DATE_SUB(UTC_TIMESTAMP(), INTERVAL 3 DAY), 40),
+	(3, DATE_SUB(UTC_TIMESTAMP(), INTERVAL 1 HOUR), 100)`)
+	assert.NilError(t, err)
+
+	m := TrendingModel{db}
+
+	// 캐시 테이블을 교체하므로 여러 번 호출해도 됩니다.
+	for i := 0; i < 2; i++ {
+		err = m.Refresh()
+		assert.NilError(t, err)
+	}
+
+	tests := []struct {
+		period  string
+		wantIDs []int
+	}{
+		{Period24h, []int{1}},
+		// 사흘 전의 조회수 40은 반감기 2일로 약 14가 되어 스니펫 1의 약 10보다 높습니다.
+		{Period7d, []int{2, 1}},
+		{Period30d, []int{2, 1}},
+		{PeriodAll, []int{2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			rankings, err := m.Get(tt.period, 10)
+			assert.NilError(t, err)
+
+			ids := []int{}
+			for i, r := range rankings {
+				assert.Equal(t, r.Rank, i+1)
+				ids = append(ids, r.Snippet.ID)
+			}
+			assert.Equal(t, fmt.Sprint(ids), fmt.Sprint(tt.wantIDs))
+		})
+	}
+}
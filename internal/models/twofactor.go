@@ -0,0 +1,234 @@
+package models
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TwoFactorModelInterface interface {
+	Enable(userID int, secret []byte, step int64, recoveryCodes []string) error
+	Get(userID int) (*TwoFactor, error)
+	Secret(userID int) ([]byte, error)
+	UseStep(userID int, step int64) error
+	UseRecoveryCode(userID int, code string) error
+	Disable(userID int) error
+}
+
+// TwoFactor는 사용자의 TOTP 2단계 인증 설정입니다. LastStep은 마지막으로 받아들인 코드의
+// 시간 단계이고, RecoveryCodes는 남은 복구 코드의 수입니다. 비밀 키는 Secret()으로 따로
+// 가져옵니다.
+type TwoFactor struct {
+	UserID        int
+	LastStep      int64
+	RecoveryCodes int
+	Created       time.Time
+}
+
+// TwoFactorModel은 TOTP 비밀 키를 Key로 AES-256-GCM 암호화하여 저장합니다. 사용자 ID를
+// 추가 인증 데이터로 쓰므로 암호문을 다른 사용자의 행으로 옮기면 복호화되지 않습니다.
+// 복구 코드는 API 토큰과 같이 SHA-256 해시만 저장합니다.
+type TwoFactorModel struct {
+	DB  *sql.DB
+	Key []byte
+}
+
+// Enable은 사용자의 2단계 인증을 켜고 복구 코드를 새로 저장합니다. 이미 켜져 있었다면
+// 이전 비밀 키와 복구 코드를 모두 바꿉니다. step은 등록을 확인한 코드의 시간 단계로,
+// 같은 코드로 바로 로그인하지 못하도록 마지막으로 사용한 단계로 기록합니다.
+func (m *TwoFactorModel) Enable(userID int, secret []byte, step int64, recoveryCodes []string) error {
+	sealed, err := m.seal(userID, secret)
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := `INSERT INTO two_factor (user_id, secret, last_step, created)
+	VALUES(?, ?, ?, UTC_TIMESTAMP())
+	ON DUPLICATE KEY UPDATE secret = VALUES(secret), last_step = VALUES(last_step), created = VALUES(created)`
+
+	_, err = tx.Exec(stmt, userID, sealed, step)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM recovery_codes WHERE user_id = ?", userID)
+	if err != nil {
+		return err
+	}
+
+	for _, code := range recoveryCodes {
+		_, err = tx.Exec("INSERT INTO recovery_codes (user_id, hash) VALUES(?, ?)", userID, hashToken(NormalizeRecoveryCode(code)))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Get은 사용자의 2단계 인증 설정을 반환합니다. 비밀 키는 복호화하지 않습니다.
+// 2단계 인증을 쓰지 않는 사용자는 ErrNoRecord를 반환합니다.
+func (m *TwoFactorModel) Get(userID int) (*TwoFactor, error) {
+	tf := &TwoFactor{}
+
+	stmt := `SELECT t.user_id, t.last_step, t.created,
+	(SELECT COUNT(*) FROM recovery_codes r WHERE r.user_id = t.user_id)
+	FROM two_factor t WHERE t.user_id = ?`
+
+	err := m.DB.QueryRow(stmt, userID).Scan(&tf.UserID, &tf.LastStep, &tf.Created, &tf.RecoveryCodes)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+
+	return tf, nil
+}
+
+// Secret은 사용자의 TOTP 비밀 키를 복호화하여 반환합니다. 2단계 인증을 쓰지 않는 사용자는
+// ErrNoRecord를, 등록할 때와 다른 키를 사용하고 있어 복호화할 수 없으면 ErrUnreadableSecret을
+// 반환합니다.
+func (m *TwoFactorModel) Secret(userID int) ([]byte, error) {
+	var sealed []byte
+
+	err := m.DB.QueryRow("SELECT secret FROM two_factor WHERE user_id = ?", userID).Scan(&sealed)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+
+	secret, err := m.open(userID, sealed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnreadableSecret, err)
+	}
+
+	return secret, nil
+}
+
+// UseStep은 step을 마지막으로 사용한 시간 단계로 기록합니다. 같은 코드를 다시 쓰지 못하도록
+// 이미 기록된 단계보다 크지 않으면 ErrInvalidCredentials를 반환합니다.
+func (m *TwoFactorModel) UseStep(userID int, step int64) error {
+	result, err := m.DB.Exec("UPDATE two_factor SET last_step = ? WHERE user_id = ? AND last_step < ?", step, userID, step)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrInvalidCredentials
+	}
+
+	return nil
+}
+
+// UseRecoveryCode는 복구 코드를 삭제합니다. 복구 코드는 한 번만 쓸 수 있으며, 사용자의
+// 코드가 아니거나 이미 쓴 코드라면 ErrInvalidCredentials를 반환합니다.
+func (m *TwoFactorModel) UseRecoveryCode(userID int, code string) error {
+	result, err := m.DB.Exec("DELETE FROM recovery_codes WHERE user_id = ? AND hash = ?", userID, hashToken(NormalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrInvalidCredentials
+	}
+
+	return nil
+}
+
+// Disable은 사용자의 2단계 인증 설정과 복구 코드를 삭제합니다.
+func (m *TwoFactorModel) Disable(userID int) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM recovery_codes WHERE user_id = ?", userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM two_factor WHERE user_id = ?", userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AnyEnabled는 2단계 인증을 켠 사용자가 한 명이라도 있는지 확인합니다. 암호화 키 없이
+// 시작해도 되는지 판단할 때 씁니다.
+func (m *TwoFactorModel) AnyEnabled() (bool, error) {
+	var exists bool
+
+	err := m.DB.QueryRow("SELECT EXISTS(SELECT true FROM two_factor)").Scan(&exists)
+
+	return exists, err
+}
+
+// NormalizeRecoveryCode는 사용자가 입력한 복구 코드에서 공백과 하이픈을 없애고 소문자로
+// 바꿉니다. 복구 코드는 이 형태로 해시됩니다.
+func NormalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
+
+func (m *TwoFactorModel) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(m.Key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal은 nonce 뒤에 암호문을 붙인 값을 반환합니다.
+func (m *TwoFactorModel) seal(userID int, plaintext []byte) ([]byte, error) {
+	gcm, err := m.aead()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, []byte(strconv.Itoa(userID))), nil
+}
+
+func (m *TwoFactorModel) open(userID int, sealed []byte) ([]byte, error) {
+	gcm, err := m.aead()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("models: two-factor secret is too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, []byte(strconv.Itoa(userID)))
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"database/sql"
 	"errors"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,9 +12,18 @@ import (
 )
 
 type UserModelInterface interface {
-	Insert(name, email, password string) error
+	Insert(name, email, password string) (int, error)
 	Authenticate(email, password string) (int, error)
 	Exists(id int) (bool, error)
+	Get(id int) (*User, error)
+	GetByEmail(email string) (*User, error)
+	GetMany(ids []int) ([]*User, error)
+	Verify(id int, email string) error
+	UpdatePassword(id int, password string) error
+	UpdateName(id int, name string) error
+	UpdateEmail(id int, email string) error
+	Export(id int) (*UserExport, error)
+	Delete(id int, keepSnippets bool) error
 }
 
 type User struct {
@@ -22,22 +32,24 @@ type User struct {
 	Email          string
 	HashedPassword []byte
 	Created        time.Time
+	Verified       bool
 }
 
 type UserModel struct {
 	DB *sql.DB
 }
 
-func (m *UserModel) Insert(name, email, password string) error {
+// Insert는 아직 이메일 주소가 인증되지 않은 새 사용자를 추가하고 그 ID를 반환합니다.
+func (m *UserModel) Insert(name, email, password string) (int, error) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 12)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	stmt := `INSERT INTO users (name, email, hashed_password, created)
 	VALUES(?, ?, ?, UTC_TIMESTAMP())`
 
-	_, err = m.DB.Exec(stmt, name, email, string(hashedPassword))
+	result, err := m.DB.Exec(stmt, name, email, string(hashedPassword))
 
 	if err != nil {
 		// 이 함수가 오류를 반환하면 errors.As() 함수를 사용하여 오류의 유형이 *mysql.MySQLError인지 확인합니다.
@@ -48,22 +60,30 @@ func (m *UserModel) Insert(name, email, password string) error {
 		var mySQLError *mysql.MySQLError
 		if errors.As(err, &mySQLError) {
 			if mySQLError.Number == 1062 && strings.Contains(mySQLError.Message, "users_uc_email") {
-				return ErrDupliacteEmail
+				return 0, ErrDupliacteEmail
 			}
 		}
-		return err
+		return 0, err
 	}
 
-	return nil
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
 }
 
+// Authenticate는 이메일과 비밀번호가 일치하는 사용자의 ID를 반환합니다. 비밀번호가 맞더라도
+// 이메일 주소를 아직 인증하지 않은 사용자는 ErrUnverified를 반환합니다.
 func (m *UserModel) Authenticate(email, password string) (int, error) {
 	var id int
 	var hashedPassword []byte
+	var verified bool
 
-	stmt := "SELECT id, hashed_password FROM users WHERE email = ?"
+	stmt := "SELECT id, hashed_password, verified_at IS NOT NULL FROM users WHERE email = ?"
 
-	err := m.DB.QueryRow(stmt, email).Scan(&id, &hashedPassword)
+	err := m.DB.QueryRow(stmt, email).Scan(&id, &hashedPassword, &verified)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return 0, ErrInvalidCredentials
@@ -81,6 +101,10 @@ func (m *UserModel) Authenticate(email, password string) (int, error) {
 		}
 	}
 
+	if !verified {
+		return 0, ErrUnverified
+	}
+
 	return id, nil
 }
 
@@ -92,3 +116,407 @@ func (m *UserModel) Exists(id int) (bool, error) {
 	err := m.DB.QueryRow(stmt, id).Scan(&exists)
 	return exists, err
 }
+
+// Get은 사용자 정보를 반환합니다. 해시된 비밀번호는 채우지 않습니다.
+func (m *UserModel) Get(id int) (*User, error) {
+	u := &User{}
+
+	stmt := "SELECT id, name, email, created, verified_at IS NOT NULL FROM users WHERE id = ?"
+
+	err := m.DB.QueryRow(stmt, id).Scan(&u.ID, &u.Name, &u.Email, &u.Created, &u.Verified)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		} else {
+			return nil, err
+		}
+	}
+
+	return u, nil
+}
+
+// GetByEmail은 이메일 주소로 사용자를 찾습니다. 해시된 비밀번호는 채우지 않습니다.
+func (m *UserModel) GetByEmail(email string) (*User, error) {
+	u := &User{}
+
+	stmt := "SELECT id, name, email, created, verified_at IS NOT NULL FROM users WHERE email = ?"
+
+	err := m.DB.QueryRow(stmt, email).Scan(&u.ID, &u.Name, &u.Email, &u.Created, &u.Verified)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		} else {
+			return nil, err
+		}
+	}
+
+	return u, nil
+}
+
+// GetMany는 주어진 ID의 사용자를 한 번의 쿼리로 가져옵니다. 없는 사용자는 결과에서 빠지며,
+// 순서는 정해져 있지 않습니다.
+func (m *UserModel) GetMany(ids []int) ([]*User, error) {
+	if len(ids) == 0 {
+		return []*User{}, nil
+	}
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	rows, err := m.DB.Query(`SELECT id, name, email, created, verified_at IS NOT NULL FROM users
+	WHERE id IN (`+placeholders(len(ids))+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*User{}
+
+	for rows.Next() {
+		u := &User{}
+		err = rows.Scan(&u.ID, &u.Name, &u.Email, &u.Created, &u.Verified)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// Verify는 사용자의 이메일 주소를 인증된 상태로 표시합니다. 인증 링크를 보낸 뒤 이메일
+// 주소가 바뀌었다면 ErrNoRecord를 반환합니다. 이미 인증된 사용자는 그대로 둡니다.
+func (m *UserModel) Verify(id int, email string) error {
+	// 이메일 주소를 조건에 넣어 한 문장으로 갱신하므로, 확인과 갱신 사이에 주소가 바뀌어도
+	// 새 주소가 인증되지 않습니다.
+	stmt := `UPDATE users SET verified_at = UTC_TIMESTAMP()
+	WHERE id = ? AND email = ? AND verified_at IS NULL`
+
+	result, err := m.DB.Exec(stmt, id, email)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+
+	// 갱신된 행이 없으면 이미 인증된 사용자인지, 링크가 더 이상 맞지 않는지 구분합니다.
+	var verified bool
+
+	err = m.DB.QueryRow("SELECT EXISTS(SELECT true FROM users WHERE id = ? AND email = ? AND verified_at IS NOT NULL)", id, email).Scan(&verified)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return ErrNoRecord
+	}
+
+	return nil
+}
+
+// UpdatePassword는 사용자의 비밀번호를 새 bcrypt 해시로 바꿉니다. 사용자가 없으면
+// ErrNoRecord를 반환합니다.
+func (m *UserModel) UpdatePassword(id int, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	if err != nil {
+		return err
+	}
+
+	result, err := m.DB.Exec("UPDATE users SET hashed_password = ? WHERE id = ?", string(hashedPassword), id)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
+
+// UpdateName은 사용자의 이름을 바꿉니다.
+func (m *UserModel) UpdateName(id int, name string) error {
+	_, err := m.DB.Exec("UPDATE users SET name = ? WHERE id = ?", name, id)
+	return err
+}
+
+// UpdateEmail은 사용자의 이메일 주소를 바꿉니다. 새 주소는 확인 링크로 인증된 것이므로
+// 아직 인증되지 않은 사용자였다면 인증된 상태로 표시합니다. 다른 사용자가 이미 쓰고 있는
+// 주소라면 ErrDupliacteEmail을 반환합니다.
+func (m *UserModel) UpdateEmail(id int, email string) error {
+	stmt := `UPDATE users SET email = ?, verified_at = COALESCE(verified_at, UTC_TIMESTAMP())
+	WHERE id = ?`
+
+	_, err := m.DB.Exec(stmt, email, id)
+	if err != nil {
+		var mySQLError *mysql.MySQLError
+		if errors.As(err, &mySQLError) {
+			if mySQLError.Number == 1062 && strings.Contains(mySQLError.Message, "users_uc_email") {
+				return ErrDupliacteEmail
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// UserExport는 사용자가 내려받는 자신의 모든 데이터입니다. 비밀번호 해시, 토큰 해시,
+// 웹훅 서명 키처럼 사용자에게 보여 줄 필요가 없는 값은 포함하지 않습니다.
+type UserExport struct {
+	ID          int                `json:"id"`
+	Name        string             `json:"name"`
+	Email       string             `json:"email"`
+	Created     time.Time          `json:"created"`
+	Snippets    []*Snippet         `json:"snippets"`
+	Collections []ExportCollection `json:"collections"`
+	Revisions   []ExportRevision   `json:"revisions"`
+	Tokens      []ExportToken      `json:"tokens"`
+	Webhooks    []ExportWebhook    `json:"webhooks"`
+	Sessions    []ExportSession    `json:"sessions"`
+}
+
+type ExportCollection struct {
+	Name       string    `json:"name"`
+	Public     bool      `json:"public"`
+	Created    time.Time `json:"created"`
+	SnippetIDs []int     `json:"snippet_ids"`
+}
+
+type ExportRevision struct {
+	SnippetID int       `json:"snippet_id"`
+	Content   string    `json:"content"`
+	Created   time.Time `json:"created"`
+}
+
+type ExportToken struct {
+	Name     string     `json:"name"`
+	Scopes   []string   `json:"scopes"`
+	Created  time.Time  `json:"created"`
+	LastUsed *time.Time `json:"last_used"`
+}
+
+type ExportSession struct {
+	UserAgent string    `json:"user_agent"`
+	IP        string    `json:"ip"`
+	Created   time.Time `json:"created"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+type ExportWebhook struct {
+	URL     string    `json:"url"`
+	Created time.Time `json:"created"`
+}
+
+// Export는 사용자의 계정 정보와 사용자가 만든 모든 데이터를 반환합니다. 만료된 스니펫도
+// 포함합니다. 사용자가 없으면 ErrNoRecord를 반환합니다.
+func (m *UserModel) Export(id int) (*UserExport, error) {
+	u, err := m.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &UserExport{
+		ID:          u.ID,
+		Name:        u.Name,
+		Email:       u.Email,
+		Created:     u.Created,
+		Collections: []ExportCollection{},
+		Revisions:   []ExportRevision{},
+		Tokens:      []ExportToken{},
+		Webhooks:    []ExportWebhook{},
+		Sessions:    []ExportSession{},
+	}
+
+	snippets := &SnippetModel{DB: m.DB}
+
+	rows, err := m.DB.Query("SELECT "+snippetColumns+" WHERE s.user_id = ? ORDER BY s.id", id)
+	if err != nil {
+		return nil, err
+	}
+	// scanSnippets가 태그도 함께 불러옵니다.
+	e.Snippets, err = snippets.scanSnippets(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.queryEach(`SELECT c.name, c.public, c.created, COALESCE(GROUP_CONCAT(cs.snippet_id ORDER BY cs.position), '')
+	FROM collections c LEFT JOIN collection_snippets cs ON cs.collection_id = c.id
+	WHERE c.user_id = ? GROUP BY c.id ORDER BY c.id`, id, func(rows *sql.Rows) error {
+		var c ExportCollection
+		var ids string
+		err := rows.Scan(&c.Name, &c.Public, &c.Created, &ids)
+		if err != nil {
+			return err
+		}
+		c.SnippetIDs = []int{}
+		for _, s := range strings.Split(ids, ",") {
+			if n, err := strconv.Atoi(s); err == nil {
+				c.SnippetIDs = append(c.SnippetIDs, n)
+			}
+		}
+		e.Collections = append(e.Collections, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.queryEach(`SELECT snippet_id, content, created FROM snippet_revisions
+	WHERE user_id = ? ORDER BY id`, id, func(rows *sql.Rows) error {
+		var r ExportRevision
+		err := rows.Scan(&r.SnippetID, &r.Content, &r.Created)
+		if err != nil {
+			return err
+		}
+		e.Revisions = append(e.Revisions, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.queryEach(`SELECT name, scopes, created, last_used FROM tokens
+	WHERE user_id = ? ORDER BY id`, id, func(rows *sql.Rows) error {
+		var t ExportToken
+		var scopes string
+		var lastUsed sql.NullTime
+		err := rows.Scan(&t.Name, &scopes, &t.Created, &lastUsed)
+		if err != nil {
+			return err
+		}
+		t.Scopes = strings.Split(scopes, ",")
+		if lastUsed.Valid {
+			t.LastUsed = &lastUsed.Time
+		}
+		e.Tokens = append(e.Tokens, t)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.queryEach(`SELECT url, created FROM webhooks
+	WHERE user_id = ? ORDER BY id`, id, func(rows *sql.Rows) error {
+		var w ExportWebhook
+		err := rows.Scan(&w.URL, &w.Created)
+		if err != nil {
+			return err
+		}
+		e.Webhooks = append(e.Webhooks, w)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.queryEach(`SELECT user_agent, ip, created, last_seen FROM user_sessions
+	WHERE user_id = ? ORDER BY id`, id, func(rows *sql.Rows) error {
+		var s ExportSession
+		err := rows.Scan(&s.UserAgent, &s.IP, &s.Created, &s.LastSeen)
+		if err != nil {
+			return err
+		}
+		e.Sessions = append(e.Sessions, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// queryEach는 쿼리 결과의 각 행에 대해 fn을 호출합니다.
+func (m *UserModel) queryEach(stmt string, id int, fn func(rows *sql.Rows) error) error {
+	rows, err := m.DB.Query(stmt, id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err = fn(rows)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Delete는 사용자와 사용자의 컬렉션, API 토큰, 웹훅, 비밀번호 재설정 토큰, 2단계 인증
+// 설정, 로그인 기록, 보내지 않은 메일을 모두 삭제합니다. keepSnippets가 true이면 사용자의
+// 스니펫은 지우지 않고 작성자가 없는 스니펫으로 바꿉니다. 다른 사람의 스니펫에 남긴
+// 리비전도 작성자가 없는 것으로 바꿉니다. 모든 변경은 하나의 트랜잭션에서 이루어지며,
+// 사용자가 없으면 ErrNoRecord를 반환합니다.
+func (m *UserModel) Delete(id int, keepSnippets bool) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmts := []string{}
+
+	if keepSnippets {
+		stmts = append(stmts, "UPDATE snippets SET user_id = 0 WHERE user_id = ?")
+	} else {
+		// SnippetModel.Delete()와 같이 스니펫에 딸린 행을 먼저 지웁니다.
+		for _, table := range []string{"snippet_tags", "collection_snippets", "snippet_views", "snippet_trending", "snippet_revisions", "snippet_collaborators"} {
+			stmts = append(stmts, "DELETE FROM "+table+" WHERE snippet_id IN (SELECT id FROM snippets WHERE user_id = ?)")
+		}
+		stmts = append(stmts, "DELETE FROM snippets WHERE user_id = ?")
+	}
+
+	stmts = append(stmts,
+		"UPDATE snippet_revisions SET user_id = 0 WHERE user_id = ?",
+		"DELETE FROM snippet_collaborators WHERE user_id = ?",
+		"DELETE FROM collection_snippets WHERE collection_id IN (SELECT id FROM collections WHERE user_id = ?)",
+		"DELETE FROM collections WHERE user_id = ?",
+		"DELETE FROM tokens WHERE user_id = ?",
+		"DELETE FROM webhook_deliveries WHERE webhook_id IN (SELECT id FROM webhooks WHERE user_id = ?)",
+		"DELETE FROM webhooks WHERE user_id = ?",
+		"DELETE FROM password_resets WHERE user_id = ?",
+		"DELETE FROM recovery_codes WHERE user_id = ?",
+		"DELETE FROM two_factor WHERE user_id = ?",
+		"DELETE FROM user_sessions WHERE user_id = ?",
+		"DELETE FROM outbox WHERE recipient = (SELECT email FROM users WHERE id = ?)",
+	)
+
+	for _, stmt := range stmts {
+		_, err = tx.Exec(stmt, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.Exec("DELETE FROM users WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoRecord
+	}
+
+	return tx.Commit()
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"database/sql"
+	"fmt"
+	"strings"
 	"testing"
 
 	"snippetbox.wook.net/internal/assert"
@@ -50,3 +53,226 @@ func TestUserModelExists(t *testing.T) {
 		})
 	}
 }
+
+func TestUserModelVerify(t *testing.T) {
+	if testing.Short() {
+		t.Skip("models: skipping integration test")
+	}
+
+	tests := []struct {
+		name         string
+		userID       int
+		email        string
+		wantErr      error
+		wantVerified bool
+	}{
+		{
+			name:         "Unverified user",
+			userID:       2,
+			email:        "bob@example.com",
+			wantErr:      nil,
+			wantVerified: true,
+		},
+		{
+			name:         "Changed email",
+			userID:       2,
+			email:        "bob@example.org",
+			wantErr:      ErrNoRecord,
+			wantVerified: false,
+		},
+		{
+			name:         "Already verified",
+			userID:       1,
+			email:        "alice@example.com",
+			wantErr:      nil,
+			wantVerified: true,
+		},
+		{
+			name:         "Non-existent ID",
+			userID:       99,
+			email:        "bob@example.com",
+			wantErr:      ErrNoRecord,
+			wantVerified: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+
+			_, err := db.Exec(`INSERT INTO users (name, email, hashed_password, created)
+			VALUES ('Bob Smith', 'bob@example.com', '$2a$12$NuTjWXm3KKntReFwyBVHyuf/to.HEwTy.eS206TNfkGfr6HzGJSWG', '2022-01-02 10:00:00')`)
+			assert.NilError(t, err)
+
+			m := UserModel{db}
+
+			err = m.Verify(tt.userID, tt.email)
+			assert.Equal(t, err, tt.wantErr)
+
+			var verified bool
+			err = db.QueryRow("SELECT EXISTS(SELECT true FROM users WHERE id = ? AND verified_at IS NOT NULL)", tt.userID).Scan(&verified)
+			assert.NilError(t, err)
+			assert.Equal(t, verified, tt.wantVerified)
+		})
+	}
+}
+
+func TestUserModelUpdatePassword(t *testing.T) {
+	if testing.Short() {
+		t.Skip("models: skipping integration test")
+	}
+
+	db := newTestDB(t)
+	m := UserModel{db}
+
+	err := m.UpdatePassword(1, "new pa$$word")
+	assert.NilError(t, err)
+
+	id, err := m.Authenticate("alice@example.com", "new pa$$word")
+	assert.NilError(t, err)
+	assert.Equal(t, id, 1)
+
+	_, err = m.Authenticate("alice@example.com", "pa$$word")
+	assert.Equal(t, err, ErrInvalidCredentials)
+
+	err = m.UpdatePassword(99, "new pa$$word")
+	assert.Equal(t, err, ErrNoRecord)
+}
+
+// seedUserData는 Alice(사용자 1)와 Bob(사용자 2)의 스니펫, 컬렉션, 리비전, API 토큰, 웹훅,
+// 로그인 기록, 공동 편집 초대를 만듭니다. 스니펫 1은 Alice의, 스니펫 2는 Bob의 것이며
+// 두 사람은 서로의 스니펫에 리비전을 하나씩 남겼습니다.
+func seedUserData(t *testing.T, db *sql.DB) {
+	for _, stmt := range []string{
+		`INSERT INTO users (name, email, hashed_password, created, verified_at)
+		VALUES ('Bob Smith', 'bob@example.com', '$2a$12$NuTjWXm3KKntReFwyBVHyuf/to.HEwTy.eS206TNfkGfr6HzGJSWG', '2022-01-02 10:00:00', '2022-01-02 10:05:00')`,
+		`INSERT INTO snippets (user_id, title, content, created, expires) VALUES
+		(1, 'Alice''s snippet', 'An old silent pond...', '2022-01-01 11:00:00', DATE_ADD(UTC_TIMESTAMP(), INTERVAL 1 DAY)),
+		(2, 'Bob''s snippet', 'Over the wintry forest...', '2022-01-02 11:00:00', DATE_ADD(UTC_TIMESTAMP(), INTERVAL 1 DAY))`,
+		"INSERT INTO snippet_tags (snippet_id, tag) VALUES (1, 'haiku'), (2, 'haiku')",
+		"INSERT INTO snippet_views (snippet_id, bucket, views) VALUES (1, '2022-01-01 12:00:00', 3), (2, '2022-01-02 12:00:00', 5)",
+		"INSERT INTO collections (user_id, name, public, created) VALUES (1, 'Favourites', TRUE, '2022-01-03 10:00:00')",
+		"INSERT INTO collection_snippets (collection_id, snippet_id, position) VALUES (1, 2, 1), (1, 1, 2)",
+		`INSERT INTO snippet_revisions (snippet_id, user_id, content, created) VALUES
+		(2, 1, 'Over the wintry forest, winds howl in rage', '2022-01-04 10:00:00'),
+		(1, 2, 'An old silent pond, a frog jumps in', '2022-01-04 11:00:00')`,
+		"INSERT INTO snippet_collaborators (snippet_id, user_id, created) VALUES (1, 2, '2022-01-04 09:00:00'), (2, 1, '2022-01-04 09:00:00')",
+		"INSERT INTO tokens (user_id, name, scopes, hash, created) VALUES (1, 'CLI', 'snippets:read,snippets:write', UNHEX(SHA2('alice', 256)), '2022-01-05 10:00:00')",
+		"INSERT INTO webhooks (user_id, url, secret, created) VALUES (1, 'https://example.com/hooks/snippetbox', REPEAT('a', 48), '2022-01-05 11:00:00')",
+		"INSERT INTO user_sessions (user_id, token, user_agent, ip, created, last_seen) VALUES (1, REPEAT('a', 43), 'Go-http-client/1.1', '127.0.0.1', '2022-01-06 10:00:00', '2022-01-06 10:30:00')",
+	} {
+		_, err := db.Exec(stmt)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// countRows는 stmt가 반환하는 COUNT(*) 값을 반환합니다.
+func countRows(t *testing.T, db *sql.DB, stmt string, args ...any) int {
+	var n int
+	err := db.QueryRow(stmt, args...).Scan(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestUserModelExport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("models: skipping integration test")
+	}
+
+	db := newTestDB(t)
+	seedUserData(t, db)
+
+	m := UserModel{db}
+
+	e, err := m.Export(1)
+	assert.NilError(t, err)
+	assert.Equal(t, e.ID, 1)
+	assert.Equal(t, e.Email, "alice@example.com")
+
+	assert.Equal(t, len(e.Snippets), 1)
+	assert.Equal(t, e.Snippets[0].Title, "Alice's snippet")
+	assert.Equal(t, strings.Join(e.Snippets[0].Tags, ","), "haiku")
+
+	assert.Equal(t, len(e.Collections), 1)
+	assert.Equal(t, e.Collections[0].Name, "Favourites")
+	assert.Equal(t, fmt.Sprint(e.Collections[0].SnippetIDs), "[2 1]")
+
+	assert.Equal(t, len(e.Revisions), 1)
+	assert.Equal(t, e.Revisions[0].SnippetID, 2)
+
+	assert.Equal(t, len(e.Tokens), 1)
+	assert.Equal(t, strings.Join(e.Tokens[0].Scopes, ","), "snippets:read,snippets:write")
+	assert.Equal(t, e.Tokens[0].LastUsed == nil, true)
+
+	assert.Equal(t, len(e.Webhooks), 1)
+	assert.Equal(t, len(e.Sessions), 1)
+	assert.Equal(t, e.Sessions[0].IP, "127.0.0.1")
+
+	_, err = m.Export(99)
+	assert.Equal(t, err, ErrNoRecord)
+}
+
+func TestUserModelDelete(t *testing.T) {
+	if testing.Short() {
+		t.Skip("models: skipping integration test")
+	}
+
+	tests := []struct {
+		name            string
+		keepSnippets    bool
+		wantSnippets    int
+		wantTags        int
+		wantRevisions   int
+		wantOrphanedIDs int
+	}{
+		{
+			// Alice의 스니펫과 거기에 딸린 태그, 조회수, 리비전이 모두 지워집니다.
+			name:            "Delete snippets",
+			keepSnippets:    false,
+			wantSnippets:    1,
+			wantTags:        1,
+			wantRevisions:   1,
+			wantOrphanedIDs: 0,
+		},
+		{
+			// Alice의 스니펫은 작성자가 없는 스니펫으로 남습니다.
+			name:            "Keep snippets",
+			keepSnippets:    true,
+			wantSnippets:    2,
+			wantTags:        2,
+			wantRevisions:   2,
+			wantOrphanedIDs: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			seedUserData(t, db)
+
+			m := UserModel{db}
+
+			err := m.Delete(1, tt.keepSnippets)
+			assert.NilError(t, err)
+
+			assert.Equal(t, countRows(t, db, "SELECT COUNT(*) FROM users"), 1)
+			assert.Equal(t, countRows(t, db, "SELECT COUNT(*) FROM snippets"), tt.wantSnippets)
+			assert.Equal(t, countRows(t, db, "SELECT COUNT(*) FROM snippets WHERE user_id = 0"), tt.wantOrphanedIDs)
+			assert.Equal(t, countRows(t, db, "SELECT COUNT(*) FROM snippet_tags"), tt.wantTags)
+			assert.Equal(t, countRows(t, db, "SELECT COUNT(*) FROM snippet_revisions"), tt.wantRevisions)
+
+			// 다른 사람의 스니펫에 남긴 리비전은 작성자가 없는 것으로 바뀝니다.
+			assert.Equal(t, countRows(t, db, "SELECT COUNT(*) FROM snippet_revisions WHERE snippet_id = 2 AND user_id = 0"), 1)
+
+			for _, table := range []string{"collections", "collection_snippets", "tokens", "webhooks", "user_sessions"} {
+				assert.Equal(t, countRows(t, db, "SELECT COUNT(*) FROM "+table), 0)
+			}
+			assert.Equal(t, countRows(t, db, "SELECT COUNT(*) FROM snippet_collaborators WHERE user_id = 1"), 0)
+
+			err = m.Delete(1, tt.keepSnippets)
+			assert.Equal(t, err, ErrNoRecord)
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package models
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// 웹훅으로 전달되는 스니펫 이벤트입니다.
+const (
+	EventSnippetCreated = "snippet.created"
+	EventSnippetUpdated = "snippet.updated"
+	EventSnippetDeleted = "snippet.deleted"
+	EventSnippetExpired = "snippet.expired"
+)
+
+// 웹훅 전달 상태입니다.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+type WebhookModelInterface interface {
+	Insert(userID int, url string) (int, string, error)
+	Get(id int) (*Webhook, error)
+	ForUser(userID int) ([]*Webhook, error)
+	Delete(userID, id int) error
+	Enqueue(event string, snippet *Snippet) error
+	EnqueueExpired() (int, error)
+	Due(limit int) ([]*WebhookDelivery, error)
+	MarkDelivered(id, status int) error
+	MarkFailed(id, status int, message string, next time.Time, giveUp bool) error
+	Deliveries(webhookID, limit int) ([]*WebhookDelivery, error)
+}
+
+// Webhook은 사용자가 등록한 수신 URL입니다. 사용자의 스니펫에 이벤트가 생기면
+// Secret으로 서명한 JSON 본문을 URL로 POST합니다.
+type Webhook struct {
+	ID      int
+	UserID  int
+	URL     string
+	Secret  string
+	Created time.Time
+}
+
+// WebhookDelivery는 전달 대기열의 한 항목이자 전달 기록입니다. 대기열이 데이터베이스에
+// 있으므로 서버가 다시 시작되어도 전달되지 않은 이벤트는 사라지지 않습니다.
+type WebhookDelivery struct {
+	ID          int
+	WebhookID   int
+	URL         string
+	Secret      string
+	Event       string
+	SnippetID   int
+	Payload     []byte
+	Status      string
+	Attempts    int
+	LastStatus  int
+	LastError   string
+	NextAttempt time.Time
+	Created     time.Time
+}
+
+// WebhookPayload는 수신자에게 보내는 JSON 본문입니다.
+type WebhookPayload struct {
+	Event    string    `json:"event"`
+	Occurred time.Time `json:"occurred"`
+	Snippet  *Snippet  `json:"snippet"`
+}
+
+type WebhookModel struct {
+	DB *sql.DB
+}
+
+// Insert는 웹훅을 등록하고 새로 만든 서명 비밀 값을 함께 반환합니다.
+func (m *WebhookModel) Insert(userID int, url string) (int, string, error) {
+	randomBytes := make([]byte, 24)
+
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return 0, "", err
+	}
+
+	secret := hex.EncodeToString(randomBytes)
+
+	stmt := `INSERT INTO webhooks (user_id, url, secret, created)
+	VALUES(?, ?, ?, UTC_TIMESTAMP())`
+
+	result, err := m.DB.Exec(stmt, userID, url, secret)
+	if err != nil {
+		return 0, "", err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, "", err
+	}
+
+	return int(id), secret, nil
+}
+
+func (m *WebhookModel) Get(id int) (*Webhook, error) {
+	h := &Webhook{}
+
+	err := m.DB.QueryRow(`SELECT id, user_id, url, secret, created FROM webhooks
+	WHERE id = ?`, id).Scan(&h.ID, &h.UserID, &h.URL, &h.Secret, &h.Created)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		} else {
+			return nil, err
+		}
+	}
+
+	return h, nil
+}
+
+func (m *WebhookModel) ForUser(userID int) ([]*Webhook, error) {
+	stmt := `SELECT id, user_id, url, secret, created FROM webhooks
+	WHERE user_id = ? ORDER BY created`
+
+	rows, err := m.DB.Query(stmt, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []*Webhook{}
+
+	for rows.Next() {
+		h := &Webhook{}
+		err = rows.Scan(&h.ID, &h.UserID, &h.URL, &h.Secret, &h.Created)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, h)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// Delete는 사용자의 웹훅과 그 전달 기록을 삭제합니다. 해당 사용자의 웹훅이 아니면 ErrNoRecord를 반환합니다.
+func (m *WebhookModel) Delete(userID, id int) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM webhooks WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoRecord
+	}
+
+	_, err = tx.Exec("DELETE FROM webhook_deliveries WHERE webhook_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Enqueue는 스니펫 작성자가 등록한 모든 웹훅에 대해 전달 항목을 대기열에 넣습니다.
+// 작성자가 없거나 웹훅을 등록하지 않았다면 아무 일도 하지 않습니다.
+func (m *WebhookModel) Enqueue(event string, snippet *Snippet) error {
+	if snippet.UserID == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(WebhookPayload{
+		Event:    event,
+		Occurred: time.Now().UTC().Truncate(time.Second),
+		Snippet:  snippet,
+	})
+	if err != nil {
+		return err
+	}
+
+	stmt := `INSERT INTO webhook_deliveries (webhook_id, event, snippet_id, payload, status, next_attempt, created)
+	SELECT id, ?, ?, ?, ?, UTC_TIMESTAMP(), UTC_TIMESTAMP() FROM webhooks WHERE user_id = ?`
+
+	_, err = m.DB.Exec(stmt, event, snippet.ID, payload, DeliveryPending, snippet.UserID)
+	return err
+}
+
+// EnqueueExpired는 만료되었지만 아직 snippet.expired 이벤트를 대기열에 넣지 않은 스니펫을 찾아
+// 이벤트를 추가하고, 추가한 스니펫 수를 반환합니다. 웹훅을 등록하기 전에 만료된 스니펫은 제외합니다.
+func (m *WebhookModel) EnqueueExpired() (int, error) {
+	stmt := `SELECT DISTINCT ` + snippetColumns + `
+	JOIN webhooks w ON w.user_id = s.user_id
+	WHERE s.expires <= UTC_TIMESTAMP() AND s.expires > w.created
+	AND NOT EXISTS (
+		SELECT 1 FROM webhook_deliveries d
+		WHERE d.webhook_id = w.id AND d.snippet_id = s.id AND d.event = ?
+	)
+	LIMIT 100`
+
+	rows, err := m.DB.Query(stmt, EventSnippetExpired)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	snippets := []*Snippet{}
+
+	for rows.Next() {
+		s, err := scanSnippet(rows)
+		if err != nil {
+			return 0, err
+		}
+		snippets = append(snippets, s)
+	}
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, s := range snippets {
+		err = m.Enqueue(EventSnippetExpired, s)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(snippets), nil
+}
+
+const deliveryColumns = `d.id, d.webhook_id, w.url, w.secret, d.event, d.snippet_id, d.payload, d.status,
+	d.attempts, d.last_status, d.last_error, d.next_attempt, d.created
+	FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id`
+
+func scanDelivery(row scanner) (*WebhookDelivery, error) {
+	d := &WebhookDelivery{}
+	err := row.Scan(&d.ID, &d.WebhookID, &d.URL, &d.Secret, &d.Event, &d.SnippetID, &d.Payload, &d.Status,
+		&d.Attempts, &d.LastStatus, &d.LastError, &d.NextAttempt, &d.Created)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (m *WebhookModel) queryDeliveries(stmt string, args ...any) ([]*WebhookDelivery, error) {
+	rows, err := m.DB.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*WebhookDelivery{}
+
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// Due는 다음 시도 시각이 지난 대기 중인 전달 항목을 오래된 순서로 최대 limit개 반환합니다.
+func (m *WebhookModel) Due(limit int) ([]*WebhookDelivery, error) {
+	stmt := `SELECT ` + deliveryColumns + `
+	WHERE d.status = ? AND d.next_attempt <= UTC_TIMESTAMP()
+	ORDER BY d.next_attempt, d.id LIMIT ?`
+
+	return m.queryDeliveries(stmt, DeliveryPending, limit)
+}
+
+// MarkDelivered는 전달 항목을 성공으로 기록합니다. status는 수신자의 HTTP 응답 코드입니다.
+func (m *WebhookModel) MarkDelivered(id, status int) error {
+	stmt := `UPDATE webhook_deliveries SET status = ?, attempts = attempts + 1, last_status = ?, last_error = ''
+	WHERE id = ?`
+
+	_, err := m.DB.Exec(stmt, DeliveryDelivered, status, id)
+	return err
+}
+
+// MarkFailed는 실패한 시도를 기록합니다. giveUp이 false이면 next에 다시 시도하고,
+// true이면 더 이상 시도하지 않습니다. 연결 실패처럼 응답이 없었다면 status는 0입니다.
+func (m *WebhookModel) MarkFailed(id, status int, message string, next time.Time, giveUp bool) error {
+	newStatus := DeliveryPending
+	if giveUp {
+		newStatus = DeliveryFailed
+	}
+
+	if len(message) > 255 {
+		message = message[:255]
+	}
+
+	stmt := `UPDATE webhook_deliveries SET status = ?, attempts = attempts + 1, last_status = ?, last_error = ?,
+	next_attempt = ? WHERE id = ?`
+
+	_, err := m.DB.Exec(stmt, newStatus, status, message, next.UTC(), id)
+	return err
+}
+
+// Deliveries는 웹훅의 최근 전달 기록을 최신순으로 최대 limit개 반환합니다.
+func (m *WebhookModel) Deliveries(webhookID, limit int) ([]*WebhookDelivery, error) {
+	stmt := `SELECT ` + deliveryColumns + `
+	WHERE d.webhook_id = ? ORDER BY d.id DESC LIMIT ?`
+
+	return m.queryDeliveries(stmt, webhookID, limit)
+}
@@ -0,0 +1,328 @@
+// ot 패키지는 여러 사람이 같은 텍스트를 동시에 편집할 수 있도록 하는 운영 변환(operational
+// transformation) 텍스트 모델을 구현합니다. 연산 형식과 변환 규칙은 ot.js와 같으므로
+// 브라우저의 편집기와 서버가 같은 연산을 주고받을 수 있습니다.
+//
+// 모든 길이와 위치는 자바스크립트 문자열과 같이 UTF-16 코드 단위로 셉니다.
+package ot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+)
+
+// MaxLength는 연산이 다룰 수 있는 최대 문서 길이입니다. UnmarshalJSON은 유지나 삭제 부분
+// 하나, 또는 연산의 기준 길이나 결과 길이가 이보다 긴 연산을 거부하므로 길이를 더하는 도중에
+// 정수가 넘치지 않습니다.
+const MaxLength = 1 << 20
+
+var (
+	// ErrBaseLength는 연산의 기준 길이가 문서나 다른 연산과 맞지 않을 때 반환됩니다.
+	ErrBaseLength = errors.New("ot: operation base length does not match")
+	// ErrInvalidOperation은 JSON 표현을 해석할 수 없을 때 반환됩니다.
+	ErrInvalidOperation = errors.New("ot: invalid operation")
+)
+
+// component는 연산의 한 부분입니다. retain, delete, insert 중 하나만 값이 있습니다.
+type component struct {
+	retain int
+	delete int
+	insert string
+}
+
+// Operation은 문서 전체를 앞에서부터 훑으며 유지(retain), 삽입(insert), 삭제(delete)를
+// 차례로 적용하는 연산입니다. 영값은 빈 문서에 적용할 수 있는 빈 연산입니다.
+type Operation struct {
+	ops []component
+	// BaseLength는 연산을 적용할 수 있는 문서의 길이입니다.
+	BaseLength int
+	// TargetLength는 연산을 적용한 뒤의 문서 길이입니다.
+	TargetLength int
+}
+
+// Len은 s의 길이를 UTF-16 코드 단위로 반환합니다.
+func Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// Retain은 다음 n개의 코드 단위를 그대로 두는 부분을 추가합니다.
+func (o *Operation) Retain(n int) *Operation {
+	if n <= 0 {
+		return o
+	}
+	o.BaseLength += n
+	o.TargetLength += n
+
+	if last := len(o.ops) - 1; last >= 0 && o.ops[last].retain > 0 {
+		o.ops[last].retain += n
+	} else {
+		o.ops = append(o.ops, component{retain: n})
+	}
+	return o
+}
+
+// Insert는 현재 위치에 s를 삽입하는 부분을 추가합니다.
+func (o *Operation) Insert(s string) *Operation {
+	if s == "" {
+		return o
+	}
+	o.TargetLength += Len(s)
+
+	last := len(o.ops) - 1
+	switch {
+	case last >= 0 && o.ops[last].insert != "":
+		o.ops[last].insert += s
+	case last >= 0 && o.ops[last].delete > 0:
+		// 삭제와 삽입의 순서는 결과에 영향을 주지 않으므로 항상 삽입을 앞에 두어
+		// 같은 편집이 언제나 같은 형태로 표현되게 합니다.
+		if last >= 1 && o.ops[last-1].insert != "" {
+			o.ops[last-1].insert += s
+		} else {
+			o.ops = append(o.ops, o.ops[last])
+			o.ops[last] = component{insert: s}
+		}
+	default:
+		o.ops = append(o.ops, component{insert: s})
+	}
+	return o
+}
+
+// Delete는 다음 n개의 코드 단위를 지우는 부분을 추가합니다.
+func (o *Operation) Delete(n int) *Operation {
+	if n <= 0 {
+		return o
+	}
+	o.BaseLength += n
+
+	if last := len(o.ops) - 1; last >= 0 && o.ops[last].delete > 0 {
+		o.ops[last].delete += n
+	} else {
+		o.ops = append(o.ops, component{delete: n})
+	}
+	return o
+}
+
+// IsNoop은 연산이 문서를 바꾸지 않으면 true를 반환합니다.
+func (o *Operation) IsNoop() bool {
+	return len(o.ops) == 0 || (len(o.ops) == 1 && o.ops[0].retain > 0)
+}
+
+// Apply는 연산을 doc에 적용한 새 문서를 반환합니다.
+func (o *Operation) Apply(doc []uint16) ([]uint16, error) {
+	if len(doc) != o.BaseLength {
+		return nil, ErrBaseLength
+	}
+
+	out := make([]uint16, 0, o.TargetLength)
+	i := 0
+
+	// BaseLength가 맞더라도 직접 만든 연산의 부분들이 문서를 넘지 않는지 다시 확인합니다.
+	for _, c := range o.ops {
+		switch {
+		case c.retain > 0:
+			if c.retain > len(doc)-i {
+				return nil, ErrBaseLength
+			}
+			out = append(out, doc[i:i+c.retain]...)
+			i += c.retain
+		case c.delete > 0:
+			if c.delete > len(doc)-i {
+				return nil, ErrBaseLength
+			}
+			i += c.delete
+		default:
+			out = append(out, utf16.Encode([]rune(c.insert))...)
+		}
+	}
+
+	return out, nil
+}
+
+// Transform은 같은 문서에 대해 동시에 만들어진 두 연산 a와 b를 받아, a를 적용한 뒤 적용할
+// b'과 b를 적용한 뒤 적용할 a'을 반환합니다. apply(apply(doc, a), b') == apply(apply(doc, b), a')
+// 입니다. 두 연산이 같은 위치에 삽입하면 a의 삽입이 앞에 옵니다.
+func Transform(a, b *Operation) (aPrime, bPrime *Operation, err error) {
+	if a.BaseLength != b.BaseLength {
+		return nil, nil, ErrBaseLength
+	}
+
+	aPrime, bPrime = &Operation{}, &Operation{}
+
+	ops1, ops2 := a.ops, b.ops
+	var op1, op2 *component
+
+	next := func(ops *[]component) *component {
+		if len(*ops) == 0 {
+			return nil
+		}
+		c := (*ops)[0]
+		*ops = (*ops)[1:]
+		return &c
+	}
+
+	op1, op2 = next(&ops1), next(&ops2)
+
+	for op1 != nil || op2 != nil {
+		if op1 != nil && op1.insert != "" {
+			aPrime.Insert(op1.insert)
+			bPrime.Retain(Len(op1.insert))
+			op1 = next(&ops1)
+			continue
+		}
+		if op2 != nil && op2.insert != "" {
+			aPrime.Retain(Len(op2.insert))
+			bPrime.Insert(op2.insert)
+			op2 = next(&ops2)
+			continue
+		}
+		if op1 == nil || op2 == nil {
+			return nil, nil, ErrBaseLength
+		}
+
+		switch {
+		case op1.retain > 0 && op2.retain > 0:
+			n := min(op1.retain, op2.retain)
+			aPrime.Retain(n)
+			bPrime.Retain(n)
+			op1, op2 = consume(op1, n, &ops1, next), consume(op2, n, &ops2, next)
+		case op1.delete > 0 && op2.delete > 0:
+			// 양쪽이 모두 지운 부분은 어느 쪽에서도 다시 지울 필요가 없습니다.
+			n := min(op1.delete, op2.delete)
+			op1, op2 = consume(op1, n, &ops1, next), consume(op2, n, &ops2, next)
+		case op1.delete > 0 && op2.retain > 0:
+			n := min(op1.delete, op2.retain)
+			aPrime.Delete(n)
+			op1, op2 = consume(op1, n, &ops1, next), consume(op2, n, &ops2, next)
+		case op1.retain > 0 && op2.delete > 0:
+			n := min(op1.retain, op2.delete)
+			bPrime.Delete(n)
+			op1, op2 = consume(op1, n, &ops1, next), consume(op2, n, &ops2, next)
+		}
+	}
+
+	return aPrime, bPrime, nil
+}
+
+// consume은 retain 또는 delete 부분 c에서 n만큼을 사용하고, 남은 것이 없으면 다음 부분을 꺼냅니다.
+func consume(c *component, n int, ops *[]component, next func(*[]component) *component) *component {
+	if c.retain > 0 {
+		c.retain -= n
+		if c.retain > 0 {
+			return c
+		}
+	} else {
+		c.delete -= n
+		if c.delete > 0 {
+			return c
+		}
+	}
+	return next(ops)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// TransformIndex는 연산을 적용하기 전 문서의 위치 pos가 적용한 뒤 어디로 옮겨지는지 반환합니다.
+// 커서 위치를 다른 사람의 편집에 맞춰 옮기는 데 사용합니다.
+func (o *Operation) TransformIndex(pos int) int {
+	newPos, i := pos, 0
+
+	for _, c := range o.ops {
+		if i > pos {
+			break
+		}
+		switch {
+		case c.retain > 0:
+			i += c.retain
+		case c.delete > 0:
+			newPos -= min(pos-i, c.delete)
+			i += c.delete
+		default:
+			newPos += Len(c.insert)
+		}
+	}
+
+	return newPos
+}
+
+// MarshalJSON은 연산을 ot.js 형식의 배열로 인코딩합니다. 양수는 유지, 음수는 삭제,
+// 문자열은 삽입입니다.
+func (o *Operation) MarshalJSON() ([]byte, error) {
+	out := make([]any, 0, len(o.ops))
+
+	for _, c := range o.ops {
+		switch {
+		case c.retain > 0:
+			out = append(out, c.retain)
+		case c.delete > 0:
+			out = append(out, -c.delete)
+		default:
+			out = append(out, c.insert)
+		}
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON은 ot.js 형식의 배열을 연산으로 디코딩합니다. 기준 길이나 결과 길이가
+// MaxLength를 넘는 연산은 ErrInvalidOperation으로 거부합니다.
+func (o *Operation) UnmarshalJSON(data []byte) error {
+	var raw []any
+
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	*o = Operation{}
+
+	for _, v := range raw {
+		switch v := v.(type) {
+		case float64:
+			// 범위를 벗어난 float64를 int로 바꾼 결과는 정해져 있지 않으므로 먼저 확인합니다.
+			if v > MaxLength || v < -MaxLength {
+				return fmt.Errorf("%w: %v is too long", ErrInvalidOperation, v)
+			}
+			n := int(v)
+			if float64(n) != v || n == 0 {
+				return fmt.Errorf("%w: %v", ErrInvalidOperation, v)
+			}
+			if n > 0 {
+				if n > MaxLength-o.BaseLength || n > MaxLength-o.TargetLength {
+					return fmt.Errorf("%w: too long", ErrInvalidOperation)
+				}
+				o.Retain(n)
+			} else {
+				if -n > MaxLength-o.BaseLength {
+					return fmt.Errorf("%w: too long", ErrInvalidOperation)
+				}
+				o.Delete(-n)
+			}
+		case string:
+			if v == "" {
+				return fmt.Errorf("%w: empty insert", ErrInvalidOperation)
+			}
+			if Len(v) > MaxLength-o.TargetLength {
+				return fmt.Errorf("%w: too long", ErrInvalidOperation)
+			}
+			o.Insert(v)
+		default:
+			return fmt.Errorf("%w: %v", ErrInvalidOperation, v)
+		}
+	}
+
+	return nil
+}
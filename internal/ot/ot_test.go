@@ -0,0 +1,139 @@
+package ot
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+	"unicode/utf16"
+
+	"snippetbox.wook.net/internal/assert"
+)
+
+func doc(s string) []uint16 {
+	return utf16.Encode([]rune(s))
+}
+
+func str(d []uint16) string {
+	return string(utf16.Decode(d))
+}
+
+// randomOperation은 길이가 n인 문서에 적용할 수 있는 임의의 연산을 만듭니다.
+func randomOperation(r *rand.Rand, n int) *Operation {
+	o := &Operation{}
+	alphabet := []string{"a", "b", "한", "😀", "\n"}
+
+	for left := n; left > 0; {
+		k := 1 + r.Intn(left)
+		switch r.Intn(3) {
+		case 0:
+			o.Retain(k)
+			left -= k
+		case 1:
+			o.Delete(k)
+			left -= k
+		default:
+			o.Insert(alphabet[r.Intn(len(alphabet))])
+		}
+	}
+	if r.Intn(2) == 0 {
+		o.Insert(alphabet[r.Intn(len(alphabet))])
+	}
+
+	return o
+}
+
+func TestApply(t *testing.T) {
+	o := (&Operation{}).Retain(4).Delete(3).Insert("new").Retain(5)
+
+	out, err := o.Apply(doc("the old pond"))
+	assert.NilError(t, err)
+	assert.Equal(t, str(out), "the new pond")
+	assert.Equal(t, o.TargetLength, len(out))
+
+	_, err = o.Apply(doc("too short"))
+	assert.Equal(t, err, ErrBaseLength)
+}
+
+func TestLen(t *testing.T) {
+	assert.Equal(t, Len("abc"), 3)
+	assert.Equal(t, Len("연못"), 2)
+	assert.Equal(t, Len("😀"), 2)
+}
+
+func TestTransformConverges(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	words := []string{"an ", "old ", "silent ", "pond ", "개구리 ", "😀 "}
+
+	for i := 0; i < 500; i++ {
+		s := ""
+		for j := r.Intn(8); j > 0; j-- {
+			s += words[r.Intn(len(words))]
+		}
+		d := doc(s)
+
+		a := randomOperation(r, len(d))
+		b := randomOperation(r, len(d))
+
+		aPrime, bPrime, err := Transform(a, b)
+		assert.NilError(t, err)
+
+		afterA, err := a.Apply(d)
+		assert.NilError(t, err)
+		afterAB, err := bPrime.Apply(afterA)
+		assert.NilError(t, err)
+
+		afterB, err := b.Apply(d)
+		assert.NilError(t, err)
+		afterBA, err := aPrime.Apply(afterB)
+		assert.NilError(t, err)
+
+		assert.Equal(t, str(afterAB), str(afterBA))
+	}
+}
+
+func TestTransformTieBreak(t *testing.T) {
+	a := (&Operation{}).Retain(2).Insert("A")
+	b := (&Operation{}).Retain(2).Insert("B")
+
+	aPrime, _, err := Transform(a, b)
+	assert.NilError(t, err)
+
+	afterB, _ := b.Apply(doc("xx"))
+	out, _ := aPrime.Apply(afterB)
+	assert.Equal(t, str(out), "xxAB")
+}
+
+func TestTransformIndex(t *testing.T) {
+	o := (&Operation{}).Retain(2).Insert("abc").Delete(2).Retain(3)
+
+	assert.Equal(t, o.TransformIndex(0), 0)
+	assert.Equal(t, o.TransformIndex(2), 5)
+	assert.Equal(t, o.TransformIndex(3), 5)
+	assert.Equal(t, o.TransformIndex(5), 6)
+}
+
+func TestJSON(t *testing.T) {
+	o := &Operation{}
+	err := json.Unmarshal([]byte(`[3,"hi",-2,1]`), o)
+	assert.NilError(t, err)
+	assert.Equal(t, o.BaseLength, 6)
+	assert.Equal(t, o.TargetLength, 6)
+
+	js, err := json.Marshal(o)
+	assert.NilError(t, err)
+	assert.Equal(t, string(js), `[3,"hi",-2,1]`)
+
+	err = json.Unmarshal([]byte(`[1048576]`), o)
+	assert.NilError(t, err)
+	assert.Equal(t, o.BaseLength, MaxLength)
+
+	// 길이의 합이 넘쳐 0이 되는 연산도 거부해야 합니다.
+	overflow := `[4611686018427387904,"a",4611686018427387904,"a",4611686018427387904,"a",4611686018427387904]`
+
+	for _, bad := range []string{`[0]`, `[1.5]`, `[""]`, `[true]`, `{}`, `[1048577]`, `[-1048577]`, `[1e300]`, `[1048576,-1]`, `[1048576,"a"]`, overflow} {
+		err = json.Unmarshal([]byte(bad), &Operation{})
+		if err == nil {
+			t.Errorf("expected an error for %s", bad)
+		}
+	}
+}
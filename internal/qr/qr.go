@@ -0,0 +1,545 @@
+// Package qr는 QR 코드(ISO/IEC 18004)를 만듭니다. 바이트 모드와 오류 정정 수준 M만
+// 지원하며, 2단계 인증 등록에 쓰는 otpauth:// URI처럼 짧은 문자열을 담는 용도입니다.
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTooLong은 데이터가 버전 40 QR 코드에도 들어가지 않을 때 반환됩니다.
+var ErrTooLong = errors.New("qr: data too long")
+
+// 버전별 블록당 오류 정정 코드워드 수와 블록 수입니다(오류 정정 수준 M). 0번 항목은 쓰지 않습니다.
+var (
+	eccCodewordsPerBlock = [41]int{0,
+		10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26,
+		26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28}
+	numErrorCorrectionBlocks = [41]int{0,
+		1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16,
+		17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49}
+)
+
+// Code는 완성된 QR 코드입니다. 모듈은 Size×Size 격자이며 바깥의 여백(quiet zone)은
+// 포함하지 않습니다.
+type Code struct {
+	Version int
+	Size    int
+
+	modules    []bool
+	isFunction []bool
+}
+
+// Encode는 data를 담을 수 있는 가장 작은 버전의 QR 코드를 만듭니다.
+func Encode(data string) (*Code, error) {
+	version := 0
+	for v := 1; v <= 40; v++ {
+		if 4+charCountBits(v)+8*len(data) <= numDataCodewords(v)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	// 바이트 모드 지시자, 문자 수, 데이터 순서로 비트를 씁니다.
+	var bb bitBuffer
+	bb.append(0x4, 4)
+	bb.append(len(data), charCountBits(version))
+	for i := 0; i < len(data); i++ {
+		bb.append(int(data[i]), 8)
+	}
+
+	// 종료 패턴과 바이트 경계까지의 0을 붙이고 남는 공간은 0xEC, 0x11로 번갈아 채웁니다.
+	capacity := numDataCodewords(version) * 8
+	terminator := capacity - len(bb)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bb.append(0, terminator)
+	bb.append(0, (8-len(bb)%8)%8)
+	for pad := 0xEC; len(bb) < capacity; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+
+	codewords := make([]byte, len(bb)/8)
+	for i, bit := range bb {
+		if bit {
+			codewords[i>>3] |= 1 << (7 - i&7)
+		}
+	}
+
+	size := version*4 + 17
+	c := &Code{
+		Version:    version,
+		Size:       size,
+		modules:    make([]bool, size*size),
+		isFunction: make([]bool, size*size),
+	}
+
+	c.drawFunctionPatterns()
+	c.drawCodewords(addEccAndInterleave(version, codewords))
+
+	// 벌점이 가장 낮은 마스크를 고릅니다. 마스크는 XOR이므로 두 번 적용하면 원래대로 돌아옵니다.
+	best, minPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(mask)
+		penalty := c.penalty()
+		if minPenalty < 0 || penalty < minPenalty {
+			best, minPenalty = mask, penalty
+		}
+		c.applyMask(mask)
+	}
+	c.applyMask(best)
+	c.drawFormatBits(best)
+
+	return c, nil
+}
+
+// Black은 (x, y) 모듈이 검은색이면 true를 반환합니다. x는 열, y는 행이며 격자 밖의
+// 좌표는 흰색으로 취급합니다.
+func (c *Code) Black(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y*c.Size+x]
+}
+
+// SVG는 QR 코드를 4모듈의 여백을 포함한 SVG 요소로 그립니다. 검은 모듈은 하나의 path로
+// 묶으므로 HTML에 바로 넣을 수 있고 크기는 CSS나 width 속성으로 정합니다.
+func (c *Code) SVG() string {
+	const border = 4
+	dim := c.Size + border*2
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, dim, dim)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="#fff"/><path fill="#000" d="`)
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Black(x, y) {
+				fmt.Fprintf(&b, "M%d,%dh1v1h-1z", x+border, y+border)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>`)
+
+	return b.String()
+}
+
+func (c *Code) set(x, y int, black bool) {
+	c.modules[y*c.Size+x] = black
+}
+
+func (c *Code) setFunction(x, y int, black bool) {
+	c.set(x, y, black)
+	c.isFunction[y*c.Size+x] = true
+}
+
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.Size; i++ {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	c.drawFinderPattern(3, 3)
+	c.drawFinderPattern(c.Size-4, 3)
+	c.drawFinderPattern(3, c.Size-4)
+
+	pos := alignmentPatternPositions(c.Version)
+	n := len(pos)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			// 세 개의 위치 찾기 패턴과 겹치는 자리는 건너뜁니다.
+			if (i == 0 && j == 0) || (i == 0 && j == n-1) || (i == n-1 && j == 0) {
+				continue
+			}
+			c.drawAlignmentPattern(pos[i], pos[j])
+		}
+	}
+
+	// 형식 정보 자리를 기능 모듈로 표시해 두고, 실제 값은 마스크를 고른 뒤에 씁니다.
+	c.drawFormatBits(0)
+	c.drawVersion()
+}
+
+// drawFinderPattern은 (x, y)를 중심으로 위치 찾기 패턴과 그 둘레의 분리 패턴을 그립니다.
+func (c *Code) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= c.Size || yy >= c.Size {
+				continue
+			}
+			dist := chebyshev(dx, dy)
+			c.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (c *Code) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunction(x+dx, y+dy, chebyshev(dx, dy) != 1)
+		}
+	}
+}
+
+// drawFormatBits는 오류 정정 수준과 마스크 번호를 담은 15비트 형식 정보를 두 곳에 씁니다.
+func (c *Code) drawFormatBits(mask int) {
+	bits := formatBits(mask)
+
+	for i := 0; i <= 5; i++ {
+		c.setFunction(8, i, bit(bits, i))
+	}
+	c.setFunction(8, 7, bit(bits, 6))
+	c.setFunction(8, 8, bit(bits, 7))
+	c.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.setFunction(c.Size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.Size-15+i, bit(bits, i))
+	}
+	// 항상 검은색인 모듈입니다.
+	c.setFunction(8, c.Size-8, true)
+}
+
+// drawVersion은 버전 7 이상에서 18비트 버전 정보를 두 곳에 씁니다.
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+
+	bits := versionBits(c.Version)
+	for i := 0; i < 18; i++ {
+		a, b := c.Size-11+i%3, i/3
+		c.setFunction(a, b, bit(bits, i))
+		c.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// drawCodewords는 오른쪽 아래에서 시작하여 두 열씩 지그재그로 오르내리며 기능 모듈이
+// 아닌 자리에 데이터 비트를 채웁니다.
+func (c *Code) drawCodewords(data []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if c.isFunction[y*c.Size+x] || i >= len(data)*8 {
+					continue
+				}
+				c.set(x, y, data[i>>3]>>(7-i&7)&1 == 1)
+				i++
+			}
+		}
+	}
+}
+
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.isFunction[y*c.Size+x] {
+				continue
+			}
+
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				c.modules[y*c.Size+x] = !c.modules[y*c.Size+x]
+			}
+		}
+	}
+}
+
+// penalty는 명세의 네 가지 규칙으로 현재 모양의 벌점을 계산합니다. 벌점이 낮을수록
+// 스캐너가 읽기 쉽습니다.
+func (c *Code) penalty() int {
+	penalty := 0
+
+	// 규칙 1과 3: 같은 색이 다섯 개 이상 이어지는 줄과 위치 찾기 패턴을 닮은 모양.
+	line := make([]bool, c.Size)
+	for _, horizontal := range []bool{true, false} {
+		for i := 0; i < c.Size; i++ {
+			for j := 0; j < c.Size; j++ {
+				if horizontal {
+					line[j] = c.Black(j, i)
+				} else {
+					line[j] = c.Black(i, j)
+				}
+			}
+			penalty += linePenalty(line)
+		}
+	}
+
+	// 규칙 2: 같은 색의 2×2 블록.
+	for y := 0; y < c.Size-1; y++ {
+		for x := 0; x < c.Size-1; x++ {
+			color := c.Black(x, y)
+			if color == c.Black(x+1, y) && color == c.Black(x, y+1) && color == c.Black(x+1, y+1) {
+				penalty += 3
+			}
+		}
+	}
+
+	// 규칙 4: 검은 모듈의 비율이 50%에서 5%씩 벗어날 때마다.
+	dark := 0
+	for _, m := range c.modules {
+		if m {
+			dark++
+		}
+	}
+	total := c.Size * c.Size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	penalty += k * 10
+
+	return penalty
+}
+
+var finderLike = [][]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+func linePenalty(line []bool) int {
+	penalty := 0
+
+	run := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			penalty += 3 + run - 5
+		}
+		run = 1
+	}
+
+	for i := 0; i+11 <= len(line); i++ {
+		for _, pattern := range finderLike {
+			match := true
+			for j, black := range pattern {
+				if line[i+j] != black {
+					match = false
+					break
+				}
+			}
+			if match {
+				penalty += 40
+			}
+		}
+	}
+
+	return penalty
+}
+
+// addEccAndInterleave는 데이터 코드워드를 블록으로 나누어 각 블록에 리드-솔로몬 오류 정정
+// 코드워드를 붙인 뒤, 블록들을 한 코드워드씩 번갈아 섞어 최종 코드워드 열을 만듭니다.
+func addEccAndInterleave(version int, data []byte) []byte {
+	numBlocks := numErrorCorrectionBlocks[version]
+	eccLen := eccCodewordsPerBlock[version]
+	rawCodewords := numRawDataModules(version) / 8
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortBlockLen := rawCodewords / numBlocks
+
+	divisor := reedSolomonDivisor(eccLen)
+
+	dataBlocks := make([][]byte, numBlocks)
+	eccBlocks := make([][]byte, numBlocks)
+	k := 0
+	for i := 0; i < numBlocks; i++ {
+		n := shortBlockLen - eccLen
+		if i >= numShortBlocks {
+			n++
+		}
+		dataBlocks[i] = data[k : k+n]
+		eccBlocks[i] = reedSolomonRemainder(dataBlocks[i], divisor)
+		k += n
+	}
+
+	result := make([]byte, 0, rawCodewords)
+	for i := 0; i <= shortBlockLen-eccLen; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < eccLen; i++ {
+		for _, block := range eccBlocks {
+			result = append(result, block[i])
+		}
+	}
+
+	return result
+}
+
+// reedSolomonDivisor는 차수가 degree인 생성 다항식의 계수를 최고차항을 뺀 채 높은 차수부터
+// 반환합니다.
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+
+	return result
+}
+
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= gfMultiply(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply는 GF(2^8)에서 원시 다항식 x^8 + x^4 + x^3 + x^2 + 1로 두 값을 곱합니다.
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+// alignmentPatternPositions는 정렬 패턴 중심이 놓이는 행(열) 좌표를 반환합니다.
+func alignmentPatternPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+
+	n := version/7 + 2
+	step := (version*4 + n*2 + 1) / (n*2 - 2) * 2
+	if version == 32 {
+		step = 26
+	}
+
+	result := make([]int, n)
+	result[0] = 6
+	pos := version*4 + 17 - 7
+	for i := n - 1; i >= 1; i-- {
+		result[i] = pos
+		pos -= step
+	}
+
+	return result
+}
+
+// numRawDataModules는 기능 패턴을 뺀, 데이터와 오류 정정 코드워드가 들어가는 모듈 수입니다.
+func numRawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		n := version/7 + 2
+		result -= (25*n-10)*n - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+func numDataCodewords(version int) int {
+	return numRawDataModules(version)/8 - eccCodewordsPerBlock[version]*numErrorCorrectionBlocks[version]
+}
+
+func charCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// eccLevelM은 형식 정보에서 오류 정정 수준 M을 나타내는 두 비트입니다.
+const eccLevelM = 0b00
+
+// formatBits는 오류 정정 수준과 마스크 번호에 BCH 코드를 붙이고 고정 마스크를 적용합니다.
+func formatBits(mask int) int {
+	data := eccLevelM<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func versionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	return version<<12 | rem
+}
+
+type bitBuffer []bool
+
+func (bb *bitBuffer) append(val, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*bb = append(*bb, val>>i&1 == 1)
+	}
+}
+
+func bit(x, i int) bool {
+	return x>>i&1 == 1
+}
+
+func chebyshev(dx, dy int) int {
+	dx, dy = abs(dx), abs(dy)
+	if dx > dy {
+		return dx
+	}
+	return dy
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
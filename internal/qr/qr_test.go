@@ -0,0 +1,213 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"snippetbox.wook.net/internal/assert"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// ISO/IEC 18004 부록 I의 "HELLO WORLD" 1-M 예제입니다.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	got := reedSolomonRemainder(data, reedSolomonDivisor(10))
+	if !bytes.Equal(got, want) {
+		t.Errorf("got: %v; want: %v", got, want)
+	}
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	assert.Equal(t, formatBits(0), 0b101010000010010)
+	assert.Equal(t, formatBits(1), 0b101000100100101)
+	assert.Equal(t, formatBits(7), 0b100101010100000)
+
+	assert.Equal(t, versionBits(7), 0b000111110010010100)
+	assert.Equal(t, versionBits(40), 0b101000110001101001)
+}
+
+func TestAlignmentPatternPositions(t *testing.T) {
+	tests := []struct {
+		version int
+		want    string
+	}{
+		{1, "[]"},
+		{2, "[6 18]"},
+		{7, "[6 22 38]"},
+		{32, "[6 34 60 86 112 138]"},
+		{36, "[6 24 50 76 102 128 154]"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, fmt.Sprint(alignmentPatternPositions(tt.version)), tt.want)
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	// 오류 정정 수준 M에서 버전별로 담을 수 있는 최대 바이트 수입니다.
+	tests := []struct {
+		version  int
+		maxBytes int
+	}{
+		{1, 14}, {2, 26}, {5, 84}, {7, 122}, {10, 213}, {40, 2331},
+	}
+
+	for _, tt := range tests {
+		c, err := Encode(strings.Repeat("a", tt.maxBytes))
+		assert.NilError(t, err)
+		assert.Equal(t, c.Version, tt.version)
+		assert.Equal(t, c.Size, tt.version*4+17)
+
+		if tt.version < 40 {
+			c, err = Encode(strings.Repeat("a", tt.maxBytes+1))
+			assert.NilError(t, err)
+			assert.Equal(t, c.Version, tt.version+1)
+		}
+	}
+
+	_, err := Encode(strings.Repeat("a", 2332))
+	if err != ErrTooLong {
+		t.Errorf("got: %v; want: %v", err, ErrTooLong)
+	}
+}
+
+// TestRoundTrip은 만든 코드에서 형식 정보와 코드워드를 다시 읽어 오류 정정 코드와 데이터가
+// 올바른지 확인합니다.
+func TestRoundTrip(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"hello",
+		"otpauth://totp/Snippetbox:alice@example.com?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&issuer=Snippetbox",
+		strings.Repeat("0123456789", 40),
+	} {
+		c, err := Encode(data)
+		assert.NilError(t, err)
+
+		// 두 곳에 쓴 형식 정보가 같아야 합니다.
+		var first, second int
+		for i := 0; i <= 5; i++ {
+			first |= b2i(c.Black(8, i)) << i
+		}
+		first |= b2i(c.Black(8, 7))<<6 | b2i(c.Black(8, 8))<<7 | b2i(c.Black(7, 8))<<8
+		for i := 9; i < 15; i++ {
+			first |= b2i(c.Black(14-i, 8)) << i
+		}
+		for i := 0; i < 8; i++ {
+			second |= b2i(c.Black(c.Size-1-i, 8)) << i
+		}
+		for i := 8; i < 15; i++ {
+			second |= b2i(c.Black(8, c.Size-15+i)) << i
+		}
+		assert.Equal(t, first, second)
+
+		mask := -1
+		for m := 0; m < 8; m++ {
+			if formatBits(m) == first {
+				mask = m
+			}
+		}
+		if mask < 0 {
+			t.Fatalf("unknown format bits %015b", first)
+		}
+
+		// 마스크를 벗기고 코드워드를 놓인 순서대로 읽습니다.
+		c.applyMask(mask)
+		raw := make([]byte, numRawDataModules(c.Version)/8)
+		i := 0
+		for right := c.Size - 1; right >= 1; right -= 2 {
+			if right == 6 {
+				right = 5
+			}
+			for vert := 0; vert < c.Size; vert++ {
+				for j := 0; j < 2; j++ {
+					x, y := right-j, vert
+					if (right+1)&2 == 0 {
+						y = c.Size - 1 - vert
+					}
+					if c.isFunction[y*c.Size+x] || i >= len(raw)*8 {
+						continue
+					}
+					if c.Black(x, y) {
+						raw[i>>3] |= 1 << (7 - i&7)
+					}
+					i++
+				}
+			}
+		}
+		c.applyMask(mask)
+
+		// 번갈아 섞인 블록을 되돌리고 각 블록의 오류 정정 코드워드를 확인합니다.
+		numBlocks := numErrorCorrectionBlocks[c.Version]
+		eccLen := eccCodewordsPerBlock[c.Version]
+		numShortBlocks := numBlocks - len(raw)%numBlocks
+		shortDataLen := len(raw)/numBlocks - eccLen
+
+		blocks := make([][]byte, numBlocks)
+		k := 0
+		for i := 0; i <= shortDataLen; i++ {
+			for j := range blocks {
+				if i < shortDataLen || j >= numShortBlocks {
+					blocks[j] = append(blocks[j], raw[k])
+					k++
+				}
+			}
+		}
+
+		var codewords []byte
+		divisor := reedSolomonDivisor(eccLen)
+		for j, block := range blocks {
+			ecc := make([]byte, eccLen)
+			for i := range ecc {
+				ecc[i] = raw[k+i*numBlocks+j]
+			}
+			if !bytes.Equal(reedSolomonRemainder(block, divisor), ecc) {
+				t.Fatalf("block %d: bad error correction codewords", j)
+			}
+			codewords = append(codewords, block...)
+		}
+
+		// 바이트 모드 지시자, 문자 수, 데이터를 읽습니다.
+		bits := bitReader{data: codewords}
+		assert.Equal(t, bits.read(4), 0x4)
+		n := bits.read(charCountBits(c.Version))
+		got := make([]byte, n)
+		for i := range got {
+			got[i] = byte(bits.read(8))
+		}
+		assert.Equal(t, string(got), data)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	c, err := Encode("hello")
+	assert.NilError(t, err)
+
+	svg := c.SVG()
+	assert.StringContains(t, svg, `viewBox="0 0 29 29"`)
+	// 왼쪽 위 위치 찾기 패턴의 첫 모듈은 여백 4모듈 뒤에 있습니다.
+	assert.StringContains(t, svg, `d="M4,4h1v1h-1z`)
+}
+
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) read(n int) int {
+	v := 0
+	for i := 0; i < n; i++ {
+		v = v<<1 | int(r.data[r.pos>>3]>>(7-r.pos&7)&1)
+		r.pos++
+	}
+	return v
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,6 @@
+// snippetpb 패키지는 snippet.proto에서 생성한 gRPC SnippetService 코드입니다.
+// snippet.proto를 고친 뒤에는 protoc, protoc-gen-go, protoc-gen-go-grpc를 설치하고
+// go generate로 다시 생성합니다.
+package snippetpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative snippet.proto
@@ -0,0 +1,228 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: snippet.proto
+
+package snippetpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	SnippetService_Create_FullMethodName = "/snippetbox.v1.SnippetService/Create"
+	SnippetService_Get_FullMethodName    = "/snippetbox.v1.SnippetService/Get"
+	SnippetService_List_FullMethodName   = "/snippetbox.v1.SnippetService/List"
+	SnippetService_Search_FullMethodName = "/snippetbox.v1.SnippetService/Search"
+)
+
+// SnippetServiceClient is the client API for SnippetService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SnippetServiceClient interface {
+	// Create는 토큰 소유자의 이름으로 스니펫을 만듭니다.
+	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*Snippet, error)
+	// Get은 만료되지 않은 스니펫 하나를 반환합니다. 없으면 NOT_FOUND입니다.
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Snippet, error)
+	// List는 만료되지 않은 스니펫을 최신순으로 반환합니다.
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	// Search는 제목이나 내용에 query가 포함된 스니펫을 최신순으로 반환합니다.
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*ListResponse, error)
+}
+
+type snippetServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSnippetServiceClient(cc grpc.ClientConnInterface) SnippetServiceClient {
+	return &snippetServiceClient{cc}
+}
+
+func (c *snippetServiceClient) Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*Snippet, error) {
+	out := new(Snippet)
+	err := c.cc.Invoke(ctx, SnippetService_Create_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *snippetServiceClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Snippet, error) {
+	out := new(Snippet)
+	err := c.cc.Invoke(ctx, SnippetService_Get_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *snippetServiceClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, SnippetService_List_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *snippetServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, SnippetService_Search_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SnippetServiceServer is the server API for SnippetService service.
+// All implementations must embed UnimplementedSnippetServiceServer
+// for forward compatibility
+type SnippetServiceServer interface {
+	// Create는 토큰 소유자의 이름으로 스니펫을 만듭니다.
+	Create(context.Context, *CreateRequest) (*Snippet, error)
+	// Get은 만료되지 않은 스니펫 하나를 반환합니다. 없으면 NOT_FOUND입니다.
+	Get(context.Context, *GetRequest) (*Snippet, error)
+	// List는 만료되지 않은 스니펫을 최신순으로 반환합니다.
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	// Search는 제목이나 내용에 query가 포함된 스니펫을 최신순으로 반환합니다.
+	Search(context.Context, *SearchRequest) (*ListResponse, error)
+	mustEmbedUnimplementedSnippetServiceServer()
+}
+
+// UnimplementedSnippetServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedSnippetServiceServer struct {
+}
+
+func (UnimplementedSnippetServiceServer) Create(context.Context, *CreateRequest) (*Snippet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedSnippetServiceServer) Get(context.Context, *GetRequest) (*Snippet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedSnippetServiceServer) List(context.Context, *ListRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedSnippetServiceServer) Search(context.Context, *SearchRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedSnippetServiceServer) mustEmbedUnimplementedSnippetServiceServer() {}
+
+// UnsafeSnippetServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SnippetServiceServer will
+// result in compilation errors.
+type UnsafeSnippetServiceServer interface {
+	mustEmbedUnimplementedSnippetServiceServer()
+}
+
+func RegisterSnippetServiceServer(s grpc.ServiceRegistrar, srv SnippetServiceServer) {
+	s.RegisterService(&SnippetService_ServiceDesc, srv)
+}
+
+func _SnippetService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SnippetServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SnippetService_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SnippetServiceServer).Create(ctx, req.(*CreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SnippetService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SnippetServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SnippetService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SnippetServiceServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SnippetService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SnippetServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SnippetService_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SnippetServiceServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SnippetService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SnippetServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SnippetService_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SnippetServiceServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SnippetService_ServiceDesc is the grpc.ServiceDesc for SnippetService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SnippetService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "snippetbox.v1.SnippetService",
+	HandlerType: (*SnippetServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _SnippetService_Create_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _SnippetService_Get_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _SnippetService_List_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _SnippetService_Search_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "snippet.proto",
+}
@@ -0,0 +1,106 @@
+// Package totp는 RFC 6238의 시간 기반 일회용 비밀번호(TOTP)를 구현합니다. 인증 앱과
+// 호환되도록 HMAC-SHA1, 6자리, 30초 주기의 기본 설정만 사용합니다.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Digits는 코드의 자릿수입니다.
+	Digits = 6
+	// Period는 코드가 바뀌는 주기입니다.
+	Period = 30 * time.Second
+	// SecretSize는 NewSecret()이 만드는 비밀 키의 바이트 수입니다(RFC 4226 권장값).
+	SecretSize = 20
+)
+
+// Skew는 Validate()가 현재 시간 단계 앞뒤로 허용하는 단계 수입니다. 기기 시계가 조금
+// 어긋나거나 코드를 입력하는 사이에 주기가 바뀌어도 받아들일 수 있습니다.
+const Skew = 1
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret은 임의의 비밀 키를 만듭니다.
+func NewSecret() ([]byte, error) {
+	secret := make([]byte, SecretSize)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// EncodeSecret은 인증 앱에 직접 입력할 수 있도록 비밀 키를 패딩 없는 base32로 인코딩합니다.
+func EncodeSecret(secret []byte) string {
+	return encoding.EncodeToString(secret)
+}
+
+// DecodeSecret은 EncodeSecret()의 결과를 되돌립니다. 공백과 대소문자는 무시합니다.
+func DecodeSecret(s string) ([]byte, error) {
+	s = strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	return encoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// Step은 t가 속한 시간 단계, 즉 유닉스 시간을 Period로 나눈 값입니다.
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Code는 주어진 시간 단계의 코드를 계산합니다(RFC 4226의 HOTP).
+func Code(secret []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", Digits, value%1000000)
+}
+
+// Validate는 code가 t 전후 Skew 단계 안의 코드와 일치하는지 확인하고 일치한 시간 단계를
+// 반환합니다. 같은 코드를 두 번 쓰지 못하도록 호출하는 쪽에서 마지막으로 사용한 단계를
+// 기록하고, 그보다 큰 단계만 받아들여야 합니다.
+func Validate(secret []byte, code string, t time.Time) (int64, bool) {
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	now := Step(t)
+	for step := now - Skew; step <= now+Skew; step++ {
+		if subtle.ConstantTimeCompare([]byte(Code(secret, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// URI는 인증 앱이 QR 코드로 읽는 otpauth:// URI를 만듭니다.
+// 형식은 https://github.com/google/google-authenticator/wiki/Key-Uri-Format을 따릅니다.
+func URI(issuer, account string, secret []byte) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+
+	q := url.Values{}
+	q.Set("secret", EncodeSecret(secret))
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(Digits))
+	q.Set("period", fmt.Sprint(int(Period/time.Second)))
+
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
@@ -0,0 +1,80 @@
+package totp
+
+import (
+	"testing"
+	"time"
+
+	"snippetbox.wook.net/internal/assert"
+)
+
+// RFC 6238 부록 B의 SHA-1 시험 값에서 마지막 여섯 자리를 사용합니다.
+var rfcSecret = []byte("12345678901234567890")
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, Code(rfcSecret, Step(time.Unix(tt.unix, 0))), tt.want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{"Current step", "050471", Step(now), true},
+		{"With spaces", "050 471", Step(now), true},
+		{"Previous step", Code(rfcSecret, Step(now)-1), Step(now) - 1, true},
+		{"Next step", Code(rfcSecret, Step(now)+1), Step(now) + 1, true},
+		{"Too old", Code(rfcSecret, Step(now)-2), 0, false},
+		{"Wrong code", "123456", 0, false},
+		{"Too short", "05047", 0, false},
+		{"Empty", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now)
+			assert.Equal(t, ok, tt.wantOK)
+			assert.Equal(t, step, tt.wantStep)
+		})
+	}
+}
+
+func TestSecretEncoding(t *testing.T) {
+	encoded := EncodeSecret(rfcSecret)
+	assert.Equal(t, encoded, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+
+	decoded, err := DecodeSecret("gezd gnbv gy3t qojq gezd gnbv gy3t qojq")
+	assert.NilError(t, err)
+	assert.Equal(t, string(decoded), string(rfcSecret))
+
+	_, err = DecodeSecret("not base32!")
+	if err == nil {
+		t.Error("got: nil; want: error for an invalid secret")
+	}
+
+	secret, err := NewSecret()
+	assert.NilError(t, err)
+	assert.Equal(t, len(secret), SecretSize)
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Snippetbox", "alice@example.com", rfcSecret)
+	assert.Equal(t, uri, "otpauth://totp/Snippetbox:alice@example.com?algorithm=SHA1&digits=6&issuer=Snippetbox&period=30&secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+}
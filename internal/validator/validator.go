@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"net/url"
 	"regexp"
 	"strings"
 	"unicode/utf8"
@@ -8,6 +9,9 @@ import (
 
 var EmailRX = regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+\\/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
 
+// TagRX는 소문자, 숫자, 하이픈으로 이루어진 30자 이하의 태그와 일치합니다.
+var TagRX = regexp.MustCompile("^[a-z0-9][a-z0-9-]{0,29}$")
+
 type Validator struct {
 	NonFieldErrors []string
 	FieldErrors    map[string]string
@@ -68,3 +72,12 @@ func MinChars(value string, n int) bool {
 func Matches(value string, rx *regexp.Regexp) bool {
 	return rx.MatchString(value)
 }
+
+// WebURL은 value가 호스트가 있는 절대 http 또는 https URL이면 true를 반환합니다.
+func WebURL(value string) bool {
+	u, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,6 @@
+-- 조회수를 메모리에 모았다가 한꺼번에 기록할 수 있도록 snippets에 views 열을 추가합니다.
+-- 기존 스니펫은 0부터 셉니다.
+ALTER TABLE
+    snippets
+ADD
+    views INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,16 @@
+-- 인기 순위 계산에 쓰는 시간대별 조회수와, 주기적으로 다시 계산하는 순위 캐시 테이블을 만듭니다.
+-- snippet_trending은 다음 Refresh()가 채우므로 비어 있는 채로 시작해도 됩니다.
+CREATE TABLE snippet_views (
+    snippet_id INTEGER NOT NULL,
+    bucket DATETIME NOT NULL,
+    views INTEGER NOT NULL,
+    PRIMARY KEY (snippet_id, bucket)
+);
+
+CREATE TABLE snippet_trending (
+    period VARCHAR(8) NOT NULL,
+    snippet_id INTEGER NOT NULL,
+    score DOUBLE NOT NULL,
+    computed DATETIME NOT NULL,
+    PRIMARY KEY (period, snippet_id)
+);
@@ -0,0 +1,17 @@
+-- 사용자 컬렉션과 컬렉션에 담긴 스니펫의 순서를 저장하는 테이블을 만듭니다.
+CREATE TABLE collections (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    public BOOLEAN NOT NULL DEFAULT FALSE,
+    created DATETIME NOT NULL
+);
+
+CREATE INDEX idx_collections_user_id ON collections(user_id);
+
+CREATE TABLE collection_snippets (
+    collection_id INTEGER NOT NULL,
+    snippet_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (collection_id, snippet_id)
+);
@@ -0,0 +1,18 @@
+-- 스니펫의 작성자와 태그를 저장합니다. 이전에 만든 스니펫은 작성자를 알 수 없으므로
+-- user_id가 0인 작성자 없는 스니펫이 되고, 태그는 없는 채로 남습니다.
+ALTER TABLE
+    snippets
+ADD
+    user_id INTEGER NOT NULL DEFAULT 0
+AFTER
+    id;
+
+CREATE INDEX idx_snippets_user_id ON snippets(user_id);
+
+CREATE TABLE snippet_tags (
+    snippet_id INTEGER NOT NULL,
+    tag VARCHAR(30) NOT NULL,
+    PRIMARY KEY (snippet_id, tag)
+);
+
+CREATE INDEX idx_snippet_tags_tag ON snippet_tags(tag);
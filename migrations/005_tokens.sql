@@ -0,0 +1,13 @@
+-- 개인 액세스 토큰을 저장하는 테이블을 만듭니다. 토큰 자체는 저장하지 않고 SHA-256 해시만 저장합니다.
+CREATE TABLE tokens (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    scopes VARCHAR(255) NOT NULL,
+    hash BINARY(32) NOT NULL,
+    created DATETIME NOT NULL,
+    last_used DATETIME NULL,
+    CONSTRAINT tokens_uc_hash UNIQUE (hash)
+);
+
+CREATE INDEX idx_tokens_user_id ON tokens(user_id);
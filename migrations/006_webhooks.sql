@@ -0,0 +1,28 @@
+-- 웹훅과 전달 대기열 테이블을 만듭니다.
+CREATE TABLE webhooks (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    user_id INTEGER NOT NULL,
+    url VARCHAR(2048) NOT NULL,
+    secret CHAR(48) NOT NULL,
+    created DATETIME NOT NULL
+);
+
+CREATE INDEX idx_webhooks_user_id ON webhooks(user_id);
+
+CREATE TABLE webhook_deliveries (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    webhook_id INTEGER NOT NULL,
+    event VARCHAR(32) NOT NULL,
+    snippet_id INTEGER NOT NULL,
+    payload MEDIUMBLOB NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_status INTEGER NOT NULL DEFAULT 0,
+    last_error VARCHAR(255) NOT NULL DEFAULT '',
+    next_attempt DATETIME NOT NULL,
+    created DATETIME NOT NULL
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt);
+
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, snippet_id, event);
@@ -0,0 +1,19 @@
+-- 공동 편집에서 저장한 리비전과, 작성자가 편집에 초대한 사용자를 저장하는 테이블을 만듭니다.
+CREATE TABLE snippet_revisions (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    snippet_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    created DATETIME NOT NULL
+);
+
+CREATE INDEX idx_snippet_revisions_snippet_id ON snippet_revisions(snippet_id);
+
+CREATE TABLE snippet_collaborators (
+    snippet_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    created DATETIME NOT NULL,
+    PRIMARY KEY (snippet_id, user_id)
+);
+
+CREATE INDEX idx_snippet_collaborators_user_id ON snippet_collaborators(user_id);
@@ -0,0 +1,14 @@
+-- 이메일 인증 기능을 넣기 전에 만든 데이터베이스에 verified_at 열을 추가합니다.
+-- 기존 사용자는 모두 인증된 것으로 간주하고 가입 시각으로 채웁니다. 이 마이그레이션을
+-- 적용하지 않으면 기존 사용자는 로그인할 때 ErrUnverified를 받게 됩니다.
+ALTER TABLE
+    users
+ADD
+    verified_at DATETIME NULL;
+
+UPDATE
+    users
+SET
+    verified_at = created
+WHERE
+    verified_at IS NULL;
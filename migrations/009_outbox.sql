@@ -0,0 +1,15 @@
+-- 보낼 메일을 담아 두는 대기열 테이블을 만듭니다.
+CREATE TABLE outbox (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    recipient VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    text_body MEDIUMTEXT NOT NULL,
+    html_body MEDIUMTEXT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error VARCHAR(255) NOT NULL DEFAULT '',
+    next_attempt DATETIME NOT NULL,
+    created DATETIME NOT NULL
+);
+
+CREATE INDEX idx_outbox_due ON outbox(status, next_attempt);
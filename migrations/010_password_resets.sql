@@ -0,0 +1,9 @@
+-- 비밀번호 재설정 토큰의 해시를 저장하는 테이블을 만듭니다.
+CREATE TABLE password_resets (
+    hash BINARY(32) NOT NULL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    expires DATETIME NOT NULL,
+    created DATETIME NOT NULL
+);
+
+CREATE INDEX idx_password_resets_user_id ON password_resets(user_id);
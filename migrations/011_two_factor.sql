@@ -0,0 +1,13 @@
+-- 2단계 인증 비밀 키와 복구 코드의 해시를 저장하는 테이블을 만듭니다.
+CREATE TABLE two_factor (
+    user_id INTEGER NOT NULL PRIMARY KEY,
+    secret VARBINARY(64) NOT NULL,
+    last_step BIGINT NOT NULL DEFAULT 0,
+    created DATETIME NOT NULL
+);
+
+CREATE TABLE recovery_codes (
+    user_id INTEGER NOT NULL,
+    hash BINARY(32) NOT NULL,
+    PRIMARY KEY (user_id, hash)
+);
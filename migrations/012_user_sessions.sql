@@ -0,0 +1,14 @@
+-- 로그인 세션 목록을 저장하는 테이블을 만듭니다. token은 scs 세션 토큰이며, 세션을 끊을 때
+-- sessions 테이블에서 해당 세션을 지우는 데 사용합니다. 이 마이그레이션 전에 로그인한 세션은
+-- 로그인 기록이 없으므로 다음 요청에서 로그아웃되고, 사용자는 다시 로그인해야 합니다.
+CREATE TABLE user_sessions (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    user_id INTEGER NOT NULL,
+    token CHAR(43) NOT NULL,
+    user_agent VARCHAR(255) NOT NULL,
+    ip VARCHAR(45) NOT NULL,
+    created DATETIME NOT NULL,
+    last_seen DATETIME NOT NULL
+);
+
+CREATE INDEX idx_user_sessions_user_id ON user_sessions(user_id);
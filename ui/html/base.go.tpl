@@ -4,10 +4,21 @@
 
 <head>
     <meta charset='utf-8'>
-    <title> Snippetbox</title>
+    <title>{{template "title" .}} - Snippetbox</title>
+    <!-- Link previews for chat tools; pages can override the "meta" block -->
+    {{block "meta" .}}
+    <meta property='og:site_name' content='Snippetbox'>
+    <meta property='og:type' content='website'>
+    <meta property='og:title' content='Snippetbox'>
+    <meta property='og:url' content='{{.BaseURL}}/'>
+    <meta name='twitter:card' content='summary'>
+    {{end}}
     <!-- Link to the CSS stylesheet and favicon -->
     <link rel='stylesheet' href='/static/css/main.css'>
     <link rel='shortcut icon' href='/static/img/favicon.ico' type='image/x-icon'>
+    <!-- Let feed readers discover the latest snippets feeds -->
+    <link rel='alternate' type='application/atom+xml' title='Latest snippets' href='/feed.atom'>
+    <link rel='alternate' type='application/rss+xml' title='Latest snippets' href='/feed.rss'>
     <!-- Also link to some fonts hosted by Google -->
     <link rel='stylesheet' href='https://fonts.googleapis.com/css?family=Ubuntu+Mono:400,700'>
 </head>
@@ -0,0 +1,24 @@
+{{define "base"}}
+<!doctype html>
+<html lang='en'>
+
+<head>
+    <meta charset='utf-8'>
+    <title>{{.Snippet.Title}} - Snippetbox</title>
+    <link rel='stylesheet' href='/static/css/embed.css'>
+</head>
+
+<body>
+    {{with .Snippet}}
+    <div class='snippet'>
+        <div class='metadata'>
+            <a href='{{$.BaseURL}}/snippet/view/{{.ID}}' target='_blank' rel='noopener'>{{.Title}}</a>
+            <span>Snippetbox</span>
+        </div>
+        <pre><code>{{.Content}}</code></pre>
+    </div>
+    {{end}}
+</body>
+
+</html>
+{{end}}
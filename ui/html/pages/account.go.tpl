@@ -0,0 +1,96 @@
+{{define "title"}}Account{{end}}
+{{define "main"}}
+<h2>Your Account</h2>
+{{with .User}}
+<table>
+    <tr>
+        <th>Name</th>
+        <td>{{.Name}}</td>
+    </tr>
+    <tr>
+        <th>Email</th>
+        <td>{{.Email}}</td>
+    </tr>
+    <tr>
+        <th>Joined</th>
+        <td>{{humanDate .Created}}</td>
+    </tr>
+</table>
+{{end}}
+<h2>Change Name</h2>
+{{with .Form.Name}}
+<form action='/account/name' method='POST' novalidate>
+    <!-- Include the CSRF token -->
+    <input type='hidden' name='csrf_token' value='{{$.CSRFToken}}'>
+    <div>
+        <label>Name:</label>
+        {{with .FieldErrors.name}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='text' name='name' value='{{.Name}}'>
+    </div>
+    <div>
+        <input type='submit' value='Change name'>
+    </div>
+</form>
+{{end}}
+<h2>Change Email</h2>
+{{with .Form.Email}}
+<form action='/account/email' method='POST' novalidate>
+    <!-- Include the CSRF token -->
+    <input type='hidden' name='csrf_token' value='{{$.CSRFToken}}'>
+    <p>We'll send a confirmation link to the new address. Your email address won't change until you open it.</p>
+    <div>
+        <label>New email:</label>
+        {{with .FieldErrors.email}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='email' name='email' value='{{.Email}}'>
+    </div>
+    <div>
+        <label>Current password:</label>
+        {{with .FieldErrors.password}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='password' name='password'>
+    </div>
+    <div>
+        <input type='submit' value='Change email'>
+    </div>
+</form>
+{{end}}
+<h2>Change Password</h2>
+{{with .Form.Password}}
+<form action='/account/password' method='POST' novalidate>
+    <!-- Include the CSRF token -->
+    <input type='hidden' name='csrf_token' value='{{$.CSRFToken}}'>
+    <div>
+        <label>Current password:</label>
+        {{with .FieldErrors.current_password}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='password' name='current_password'>
+    </div>
+    <div>
+        <label>New password:</label>
+        {{with .FieldErrors.new_password}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='password' name='new_password'>
+    </div>
+    <div>
+        <input type='submit' value='Change password'>
+    </div>
+</form>
+{{end}}
+<h2>Two-Factor Authentication</h2>
+{{if .TOTPEnabled}}
+<p><a href='/account/2fa'>Set up two-factor authentication</a> to require a code from an authenticator app when you log in.</p>
+{{else}}
+<p>Two-factor authentication isn't available on this server.</p>
+{{end}}
+<h2>Sessions</h2>
+<p><a href='/account/sessions'>See where you're logged in</a> and log out of other browsers and devices.</p>
+<h2>Your Data</h2>
+<p><a href='/account/export'>Download all your data</a> as a JSON file, or <a href='/account/delete'>delete your account</a>.</p>
+{{end}}
@@ -0,0 +1,30 @@
+{{define "title"}}Delete Account{{end}}
+{{define "main"}}
+<h2>Delete Account</h2>
+<p>Deleting your account is permanent and can't be undone. Your collections, API tokens and webhooks will be deleted and you'll be logged out everywhere.</p>
+<p>Before you go, you may want to <a href='/account/export'>download all your data</a>.</p>
+{{with .Form}}
+<form action='/account/delete' method='POST' novalidate>
+    <!-- Include the CSRF token -->
+    <input type='hidden' name='csrf_token' value='{{$.CSRFToken}}'>
+    <div>
+        <label>Your snippets:</label>
+        {{with .FieldErrors.snippets}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='radio' name='snippets' value='delete' {{if (eq .Snippets "delete")}}checked{{end}}> Delete them
+        <input type='radio' name='snippets' value='anonymise' {{if (eq .Snippets "anonymise")}}checked{{end}}> Keep them without my name
+    </div>
+    <div>
+        <label>Current password:</label>
+        {{with .FieldErrors.password}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='password' name='password'>
+    </div>
+    <div>
+        <input type='submit' value='Delete my account'>
+    </div>
+</form>
+{{end}}
+{{end}}
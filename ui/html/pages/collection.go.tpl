@@ -0,0 +1,53 @@
+{{define "title"}}{{.Collection.Name}}{{end}}
+{{define "main"}}
+{{$csrf := .CSRFToken}}
+{{$owner := .IsOwner}}
+{{with .Collection}}
+<h2>{{.Name}}</h2>
+{{if $owner}}
+<form action='/collection/{{.ID}}/share' method='POST'>
+    <input type='hidden' name='csrf_token' value='{{$csrf}}'>
+    {{if .Public}}
+    <input type='hidden' name='public' value='false'>
+    <p>This collection is public. <button>Make private</button></p>
+    {{else}}
+    <input type='hidden' name='public' value='true'>
+    <p>This collection is private. <button>Share publicly</button></p>
+    {{end}}
+</form>
+{{end}}
+{{if .Snippets}}
+<table>
+    <tr>
+        <th>Title</th>
+        <th>Created</th>
+        {{if $owner}}<th></th>{{end}}
+    </tr>
+    {{$id := .ID}}
+    {{range .Snippets}}
+    <tr>
+        <td><a href='/snippet/view/{{.ID}}'>{{.Title}}</a></td>
+        <td>{{humanDate .Created}}</td>
+        {{if $owner}}
+        <td>
+            <form action='/collection/{{$id}}/move' method='POST' class='inline'>
+                <input type='hidden' name='csrf_token' value='{{$csrf}}'>
+                <input type='hidden' name='snippet_id' value='{{.ID}}'>
+                <button name='direction' value='up'>&uarr;</button>
+                <button name='direction' value='down'>&darr;</button>
+            </form>
+            <form action='/collection/{{$id}}/remove' method='POST' class='inline'>
+                <input type='hidden' name='csrf_token' value='{{$csrf}}'>
+                <input type='hidden' name='snippet_id' value='{{.ID}}'>
+                <button>Remove</button>
+            </form>
+        </td>
+        {{end}}
+    </tr>
+    {{end}}
+</table>
+{{else}}
+<p>This collection is empty.</p>
+{{end}}
+{{end}}
+{{end}}
@@ -0,0 +1,40 @@
+{{define "title"}}My Collections{{end}}
+{{define "main"}}
+<h2>My Collections</h2>
+{{if .Collections}}
+<table>
+    <tr>
+        <th>Name</th>
+        <th>Created</th>
+        <th>Visibility</th>
+    </tr>
+    {{range .Collections}}
+    <tr>
+        <td><a href='/collection/{{.ID}}'>{{.Name}}</a></td>
+        <td>{{humanDate .Created}}</td>
+        <td>{{if .Public}}Public{{else}}Private{{end}}</td>
+    </tr>
+    {{end}}
+</table>
+{{else}}
+<p>You haven't created any collections yet.</p>
+{{end}}
+<h2>New Collection</h2>
+<form action='/collections' method='POST' novalidate>
+    <!-- Include the CSRF token -->
+    <input type='hidden' name='csrf_token' value='{{.CSRFToken}}'>
+    <div>
+        <label>Name:</label>
+        {{with .Form.FieldErrors.name}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='text' name='name' value='{{.Form.Name}}'>
+    </div>
+    <div>
+        <input type='checkbox' name='public' value='true' {{if .Form.Public}}checked{{end}}> Share publicly
+    </div>
+    <div>
+        <input type='submit' value='Create collection'>
+    </div>
+</form>
+{{end}}
@@ -17,6 +17,13 @@
         {{end}}
         <textarea name='content'>{{.Form.Content}}</textarea>
     </div>
+    <div>
+        <label>Tags:</label>
+        {{with .Form.FieldErrors.tags}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='text' name='tags' value='{{.Form.Tags}}' placeholder='postgres, k8s'>
+    </div>
     <div>
         <label>Delete in:</label>
         {{with .Form.FieldErrors.expires}}
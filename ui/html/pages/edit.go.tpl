@@ -0,0 +1,77 @@
+{{define "title"}}Editing {{.Snippet.Title}}{{end}}
+{{define "main"}}
+{{with .Snippet}}
+<h2>Editing <a href='/snippet/view/{{.ID}}'>{{.Title}}</a> <span>#{{.ID}}</span></h2>
+<div id='collab-editor' data-ws='/snippet/edit/{{.ID}}/ws'>
+    <p class='collab-status'>Connecting&hellip;</p>
+    <textarea class='collab-text' disabled>{{.Content}}</textarea>
+    <div class='metadata'>
+        <span>Editing now: <span class='collab-peers'></span></span>
+        {{if $.IsOwner}}<button class='collab-save' type='button' disabled>Save revision</button>{{end}}
+    </div>
+    <pre class='collab-preview'></pre>
+</div>
+{{end}}
+<h2>Revisions</h2>
+{{if .Revisions}}
+<table>
+    <tr>
+        <th>#</th>
+        <th>Saved by</th>
+        <th>Saved</th>
+    </tr>
+    {{range .Revisions}}
+    <tr>
+        <td>{{.ID}}</td>
+        <td>{{.Author}}</td>
+        <td>{{humanDate .Created}}</td>
+    </tr>
+    {{end}}
+</table>
+{{else}}
+<p>No revisions have been saved yet.</p>
+{{end}}
+{{if .IsOwner}}
+<h2>Collaborators</h2>
+{{if .Collaborators}}
+{{$csrf := .CSRFToken}}
+{{$snippetID := .Snippet.ID}}
+<table>
+    <tr>
+        <th>Name</th>
+        <th>Email</th>
+        <th>Invited</th>
+        <th></th>
+    </tr>
+    {{range .Collaborators}}
+    <tr>
+        <td>{{.Name}}</td>
+        <td>{{.Email}}</td>
+        <td>{{humanDate .Created}}</td>
+        <td>
+            <form action='/snippet/edit/{{$snippetID}}/collaborators/{{.UserID}}/remove' method='POST'>
+                <input type='hidden' name='csrf_token' value='{{$csrf}}'>
+                <button>Remove</button>
+            </form>
+        </td>
+    </tr>
+    {{end}}
+</table>
+{{else}}
+<p>Only you can edit this snippet. Invite someone by their email address to edit it together.</p>
+{{end}}
+<form action='/snippet/edit/{{.Snippet.ID}}/collaborators' method='POST' novalidate>
+    <input type='hidden' name='csrf_token' value='{{.CSRFToken}}'>
+    <div>
+        <label>Email:</label>
+        {{with .Form.FieldErrors.email}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='email' name='email' value='{{.Form.Email}}'>
+    </div>
+    <div>
+        <input type='submit' value='Invite'>
+    </div>
+</form>
+{{end}}
+{{end}}
@@ -2,7 +2,7 @@
 {{define "main"}}
 <h2>Latest Snippets</h2>
 {{if .Snippets}}
-<table>
+<table id='latest-snippets'>
     <tr>
         <th>Title</th>
         <th>Created</th>
@@ -20,8 +20,13 @@
         {{end}}
         <input type='password' name='password'>
     </div>
+    <div>
+        <input type='checkbox' name='remember_me' value='true' {{if .Form.RememberMe}}checked{{end}}> Remember me
+    </div>
     <div>
         <input type='submit' value='Login'>
     </div>
 </form>
+<p><a href='/user/password/forgot'>Forgot your password?</a></p>
+<p><a href='/user/verify/resend'>Didn't get a verification email?</a></p>
 {{end}}
\ No newline at end of file
@@ -0,0 +1,18 @@
+{{define "title"}}Two-Factor Authentication{{end}}
+{{define "main"}}
+<form action='/user/login/2fa' method='POST' novalidate>
+    <!-- Include the CSRF token -->
+    <input type='hidden' name='csrf_token' value='{{.CSRFToken}}'>
+    <p>Enter the 6-digit code from your authenticator app. If you've lost your device, enter one of your recovery codes instead.</p>
+    <div>
+        <label>Code:</label>
+        {{with .Form.FieldErrors.code}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='text' name='code' inputmode='numeric' autocomplete='one-time-code' autofocus>
+    </div>
+    <div>
+        <input type='submit' value='Verify'>
+    </div>
+</form>
+{{end}}
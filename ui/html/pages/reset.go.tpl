@@ -0,0 +1,18 @@
+{{define "title"}}Reset password{{end}}
+{{define "main"}}
+<form action='/user/password/reset' method='POST' novalidate>
+    <!-- Include the CSRF token -->
+    <input type='hidden' name='csrf_token' value='{{.CSRFToken}}'>
+    <input type='hidden' name='token' value='{{.Form.Token}}'>
+    <div>
+        <label>New password:</label>
+        {{with .Form.FieldErrors.password}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='password' name='password'>
+    </div>
+    <div>
+        <input type='submit' value='Reset password'>
+    </div>
+</form>
+{{end}}
\ No newline at end of file
@@ -0,0 +1,42 @@
+{{define "title"}}Sessions{{end}}
+{{define "main"}}
+<h2>Your Sessions</h2>
+{{if .Sessions}}
+{{$csrf := .CSRFToken}}
+{{$current := .CurrentSessionID}}
+<table>
+    <tr>
+        <th>Browser</th>
+        <th>IP address</th>
+        <th>Logged in</th>
+        <th>Last seen</th>
+        <th></th>
+    </tr>
+    {{range .Sessions}}
+    <tr>
+        <td>{{with .UserAgent}}{{.}}{{else}}Unknown{{end}}</td>
+        <td>{{.IP}}</td>
+        <td>{{humanDate .Created}}</td>
+        <td>{{if eq .ID $current}}This session{{else}}{{humanDate .LastSeen}}{{end}}</td>
+        <td>
+            <form action='/account/sessions/{{.ID}}/revoke' method='POST'>
+                <input type='hidden' name='csrf_token' value='{{$csrf}}'>
+                <button>{{if eq .ID $current}}Log out{{else}}Revoke{{end}}</button>
+            </form>
+        </td>
+    </tr>
+    {{end}}
+</table>
+{{else}}
+<p>There are no recorded sessions.</p>
+{{end}}
+<h2>Log Out Everywhere</h2>
+<form action='/user/logout/all' method='POST'>
+    <!-- Include the CSRF token -->
+    <input type='hidden' name='csrf_token' value='{{.CSRFToken}}'>
+    <p>This logs you out of every browser and device, including this one.</p>
+    <div>
+        <input type='submit' value='Log out everywhere'>
+    </div>
+</form>
+{{end}}
@@ -0,0 +1,63 @@
+{{define "title"}}API Tokens{{end}}
+{{define "main"}}
+<h2>Personal Access Tokens</h2>
+{{with .NewToken}}
+<div class='flash'>
+    <p>Your new token is shown below. Copy it now &mdash; you won't be able to see it again.</p>
+    <code>{{.}}</code>
+</div>
+{{end}}
+{{if .Tokens}}
+{{$csrf := .CSRFToken}}
+<table>
+    <tr>
+        <th>Name</th>
+        <th>Scopes</th>
+        <th>Created</th>
+        <th>Last used</th>
+        <th></th>
+    </tr>
+    {{range .Tokens}}
+    <tr>
+        <td>{{.Name}}</td>
+        <td>{{range .Scopes}}<code>{{.}}</code> {{end}}</td>
+        <td>{{humanDate .Created}}</td>
+        <td>{{with humanDate .LastUsed}}{{.}}{{else}}Never{{end}}</td>
+        <td>
+            <form action='/account/tokens/{{.ID}}/revoke' method='POST'>
+                <input type='hidden' name='csrf_token' value='{{$csrf}}'>
+                <button>Revoke</button>
+            </form>
+        </td>
+    </tr>
+    {{end}}
+</table>
+{{else}}
+<p>You don't have any tokens yet.</p>
+{{end}}
+<h2>New Token</h2>
+<form action='/account/tokens' method='POST' novalidate>
+    <!-- Include the CSRF token -->
+    <input type='hidden' name='csrf_token' value='{{.CSRFToken}}'>
+    <div>
+        <label>Name:</label>
+        {{with .Form.FieldErrors.name}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='text' name='name' value='{{.Form.Name}}'>
+    </div>
+    <div>
+        <label>Scopes:</label>
+        {{with .Form.FieldErrors.scopes}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        {{$selected := .Form.Scopes}}
+        {{range .Scopes}}
+        <input type='checkbox' name='scopes' value='{{.}}' {{if contains $selected .}}checked{{end}}> {{.}}
+        {{end}}
+    </div>
+    <div>
+        <input type='submit' value='Create token'>
+    </div>
+</form>
+{{end}}
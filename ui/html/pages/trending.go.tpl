@@ -0,0 +1,30 @@
+{{define "title"}}Trending{{end}}
+{{define "main"}}
+<h2>Trending Snippets</h2>
+<p class='periods'>
+    <a href='/trending?period=24h' {{if eq .Period "24h"}}class='live'{{end}}>24 hours</a>
+    <a href='/trending?period=7d' {{if eq .Period "7d"}}class='live'{{end}}>7 days</a>
+    <a href='/trending?period=30d' {{if eq .Period "30d"}}class='live'{{end}}>30 days</a>
+    <a href='/trending?period=all' {{if eq .Period "all"}}class='live'{{end}}>Most viewed</a>
+</p>
+{{if .Rankings}}
+<table>
+    <tr>
+        <th>#</th>
+        <th>Title</th>
+        <th>Views</th>
+        <th>Created</th>
+    </tr>
+    {{range .Rankings}}
+    <tr>
+        <td>{{.Rank}}</td>
+        <td><a href='/snippet/view/{{.Snippet.ID}}'>{{.Snippet.Title}}</a></td>
+        <td>{{.Snippet.Views}}</td>
+        <td>{{humanDate .Snippet.Created}}</td>
+    </tr>
+    {{end}}
+</table>
+{{else}}
+<p>Nothing is trending yet.</p>
+{{end}}
+{{end}}
@@ -0,0 +1,57 @@
+{{define "title"}}Two-Factor Authentication{{end}}
+{{define "main"}}
+<h2>Two-Factor Authentication</h2>
+{{if .RecoveryCodes}}
+<p>Two-factor authentication is now on. Save these recovery codes somewhere safe. Each code can be used once to log in if you lose your device. They won't be shown again.</p>
+<pre><code>{{range .RecoveryCodes}}{{.}}
+{{end}}</code></pre>
+<p><a href='/account'>Back to your account</a></p>
+{{else if .TwoFactor}}
+<p>Two-factor authentication is on since {{humanDate .TwoFactor.Created}}. You have {{.TwoFactor.RecoveryCodes}} recovery codes left.</p>
+<h2>Turn Off</h2>
+<form action='/account/2fa/disable' method='POST' novalidate>
+    <!-- Include the CSRF token -->
+    <input type='hidden' name='csrf_token' value='{{.CSRFToken}}'>
+    <div>
+        <label>Current password:</label>
+        {{with .Form.FieldErrors.password}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='password' name='password'>
+    </div>
+    <div>
+        <input type='submit' value='Turn off two-factor authentication'>
+    </div>
+</form>
+{{else if not .TOTPEnabled}}
+<p>Two-factor authentication isn't available on this server.</p>
+{{else}}
+<p>Scan this QR code with an authenticator app, then enter the 6-digit code it shows.</p>
+<div class='qrcode'>{{.QRCode}}</div>
+{{with .Form}}
+<p>Can't scan it? Enter this key instead: <code>{{.Secret}}</code></p>
+<form action='/account/2fa' method='POST' novalidate>
+    <!-- Include the CSRF token -->
+    <input type='hidden' name='csrf_token' value='{{$.CSRFToken}}'>
+    <input type='hidden' name='secret' value='{{.Secret}}'>
+    <div>
+        <label>Code:</label>
+        {{with .FieldErrors.code}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='text' name='code' inputmode='numeric' autocomplete='one-time-code'>
+    </div>
+    <div>
+        <label>Current password:</label>
+        {{with .FieldErrors.password}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='password' name='password'>
+    </div>
+    <div>
+        <input type='submit' value='Turn on two-factor authentication'>
+    </div>
+</form>
+{{end}}
+{{end}}
+{{end}}
@@ -0,0 +1,21 @@
+{{define "title"}}Verify your email{{end}}
+{{define "main"}}
+<form action='/user/verify/resend' method='POST' novalidate>
+    <!-- Include the CSRF token -->
+    <input type='hidden' name='csrf_token' value='{{.CSRFToken}}'>
+    {{range .Form.NonFieldErrors}}
+    <div class='error'>{{.}}</div>
+    {{end}}
+    <p>Enter the email address you signed up with and we'll send you a new verification link.</p>
+    <div>
+        <label>Email:</label>
+        {{with .Form.FieldErrors.email}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='email' name='email' value='{{.Form.Email}}'>
+    </div>
+    <div>
+        <input type='submit' value='Send verification link'>
+    </div>
+</form>
+{{end}}
\ No newline at end of file
@@ -1,5 +1,21 @@
-{{define "title"}}Snippet #{{.Snippet.ID}}{{end}}
+{{define "title"}}{{.Snippet.Title}}{{end}}
+{{define "meta"}}
+{{$url := printf "%s/snippet/view/%d" .BaseURL .Snippet.ID}}
+<meta name='description' content='{{excerpt .Snippet.Content}}'>
+<meta property='og:site_name' content='Snippetbox'>
+<meta property='og:type' content='article'>
+<meta property='og:title' content='{{.Snippet.Title}}'>
+<meta property='og:description' content='{{excerpt .Snippet.Content}}'>
+<meta property='og:url' content='{{$url}}'>
+<meta name='twitter:card' content='summary'>
+<meta name='twitter:title' content='{{.Snippet.Title}}'>
+<meta name='twitter:description' content='{{excerpt .Snippet.Content}}'>
+<link rel='canonical' href='{{$url}}'>
+<link rel='alternate' type='application/json+oembed' href='{{.BaseURL}}/oembed?url={{$url}}&format=json' title='{{.Snippet.Title}}'>
+{{end}}
 {{define "main"}}
+{{$csrf := .CSRFToken}}
+{{$collections := .Collections}}
 {{with .Snippet}}
 <div class='snippet'>
     <div class='metadata'>
@@ -7,10 +23,35 @@
         <span>#{{.ID}}</span>
     </div>
     <pre><code>{{.Content}}</code></pre>
+    {{if or .Author .Tags}}
+    <div class='metadata'>
+        {{with .Author}}<span>By <a href='/feed/user/{{$.Snippet.UserID}}.atom'>{{.}}</a></span>{{end}}
+        <span>{{range .Tags}}<a href='/feed/tag/{{.}}.atom'>#{{.}}</a> {{end}}</span>
+    </div>
+    {{end}}
     <div class='metadata'>
         <time>Created: {{humanDate .Created}}</time>
         <time>Expires: {{humanDate .Expires}}</time>
+        <span>Views: {{.Views}}</span>
     </div>
 </div>
+{{if $.CanEdit}}
+<p class='edit-link'><a href='/snippet/edit/{{.ID}}'>Edit together</a></p>
+{{end}}
+<div class='embed'>
+    <label>Embed this snippet:</label>
+    <textarea readonly>{{$.EmbedCode}}</textarea>
+</div>
+{{if $collections}}
+{{$snippetID := .ID}}
+<form action='' method='POST' class='add-to-collection'>
+    <input type='hidden' name='csrf_token' value='{{$csrf}}'>
+    <input type='hidden' name='snippet_id' value='{{$snippetID}}'>
+    <label>Add to collection:</label>
+    {{range $collections}}
+    <button formaction='/collection/{{.ID}}/add'>{{.Name}}</button>
+    {{end}}
+</form>
+{{end}}
 {{end}}
 {{end}}
\ No newline at end of file
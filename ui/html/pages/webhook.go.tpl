@@ -0,0 +1,33 @@
+{{define "title"}}Webhook deliveries{{end}}
+{{define "main"}}
+{{with .Webhook}}
+<h2>Deliveries to {{.URL}}</h2>
+{{end}}
+{{if .Deliveries}}
+<table>
+    <tr>
+        <th>#</th>
+        <th>Event</th>
+        <th>Snippet</th>
+        <th>Status</th>
+        <th>Attempts</th>
+        <th>Response</th>
+        <th>Queued</th>
+    </tr>
+    {{range .Deliveries}}
+    <tr>
+        <td>{{.ID}}</td>
+        <td><code>{{.Event}}</code></td>
+        <td>#{{.SnippetID}}</td>
+        <td>{{.Status}}{{if eq .Status "pending"}} (next attempt {{humanDate .NextAttempt}}){{end}}</td>
+        <td>{{.Attempts}}</td>
+        <td>{{with .LastStatus}}{{.}}{{end}} {{.LastError}}</td>
+        <td>{{humanDate .Created}}</td>
+    </tr>
+    {{end}}
+</table>
+{{else}}
+<p>Nothing has been sent to this webhook yet.</p>
+{{end}}
+<p><a href='/account/webhooks'>&larr; Back to webhooks</a></p>
+{{end}}
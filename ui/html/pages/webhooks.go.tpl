@@ -0,0 +1,51 @@
+{{define "title"}}Webhooks{{end}}
+{{define "main"}}
+<h2>Webhooks</h2>
+<p>Each webhook receives a JSON <code>POST</code> when one of your snippets is created, updated, deleted or expires.
+The <code>X-Snippetbox-Signature-256</code> header holds an HMAC-SHA256 of the body, keyed with the webhook's secret.</p>
+{{with .NewSecret}}
+<div class='flash'>
+    <p>Your webhook's signing secret is shown below. Copy it now &mdash; you won't be able to see it again.</p>
+    <code>{{.}}</code>
+</div>
+{{end}}
+{{if .Webhooks}}
+{{$csrf := .CSRFToken}}
+<table>
+    <tr>
+        <th>URL</th>
+        <th>Created</th>
+        <th></th>
+    </tr>
+    {{range .Webhooks}}
+    <tr>
+        <td><a href='/account/webhooks/{{.ID}}'>{{.URL}}</a></td>
+        <td>{{humanDate .Created}}</td>
+        <td>
+            <form action='/account/webhooks/{{.ID}}/delete' method='POST'>
+                <input type='hidden' name='csrf_token' value='{{$csrf}}'>
+                <button>Delete</button>
+            </form>
+        </td>
+    </tr>
+    {{end}}
+</table>
+{{else}}
+<p>You don't have any webhooks yet.</p>
+{{end}}
+<h2>New Webhook</h2>
+<form action='/account/webhooks' method='POST' novalidate>
+    <!-- Include the CSRF token -->
+    <input type='hidden' name='csrf_token' value='{{.CSRFToken}}'>
+    <div>
+        <label>Payload URL:</label>
+        {{with .Form.FieldErrors.url}}
+        <label class='error'>{{.}}</label>
+        {{end}}
+        <input type='text' name='url' value='{{.Form.URL}}'>
+    </div>
+    <div>
+        <input type='submit' value='Add webhook'>
+    </div>
+</form>
+{{end}}
@@ -2,12 +2,17 @@
 <nav>
     <div>
         <a href='/'>Home</a>
+        <a href='/trending'>Trending</a>
         {{if .IsAuthenticated}}
         <a href='/snippet/create'>Create snippet</a>
+        <a href='/collections'>Collections</a>
         {{end}}
     </div>
     <div>
         {{if .IsAuthenticated}}
+        <a href='/account'>Account</a>
+        <a href='/account/tokens'>API tokens</a>
+        <a href='/account/webhooks'>Webhooks</a>
         <form action='/user/logout' method='POST'>
             <!-- Include the CSRF token -->
             <input type='hidden' name='csrf_token' value='{{.CSRFToken}}'>
@@ -5,4 +5,317 @@ for (var i = 0; i < navLinks.length; i++) {
 		link.classList.add("live");
 		break;
 	}
-}
\ No newline at end of file
+}
+
+// 홈 페이지에서는 /events 스트림을 구독해서 새 스니펫을 목록 맨 위에 추가합니다.
+var latest = document.getElementById("latest-snippets");
+if (latest && window.EventSource) {
+	var months = ["Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"];
+	var pad = function(n) { return (n < 10 ? "0" : "") + n; };
+
+	// humanDate 템플릿 함수와 같은 형식입니다.
+	var humanDate = function(s) {
+		var d = new Date(s);
+		return pad(d.getUTCDate()) + " " + months[d.getUTCMonth()] + " " + d.getUTCFullYear() +
+			" at " + pad(d.getUTCHours()) + ":" + pad(d.getUTCMinutes());
+	};
+
+	new EventSource("/events").addEventListener("snippet", function(e) {
+		var snippet = JSON.parse(e.data);
+
+		var row = document.createElement("tr");
+		var title = document.createElement("td");
+		var link = document.createElement("a");
+		link.href = "/snippet/view/" + snippet.id;
+		link.textContent = snippet.title;
+		title.appendChild(link);
+
+		var created = document.createElement("td");
+		created.textContent = humanDate(snippet.created);
+
+		var id = document.createElement("td");
+		id.textContent = "#" + snippet.id;
+
+		row.appendChild(title);
+		row.appendChild(created);
+		row.appendChild(id);
+
+		// 첫 번째 행은 머리글입니다.
+		var header = latest.rows[0];
+		header.parentNode.insertBefore(row, header.nextSibling);
+	});
+}
+
+// 공동 편집 페이지입니다. 연산은 internal/ot 패키지와 같은 ot.js 형식의 배열로, 양수는 유지,
+// 음수는 삭제, 문자열은 삽입입니다. 길이와 위치는 자바스크립트 문자열과 같은 UTF-16 코드 단위입니다.
+var ot = {
+	retain: function(o, n) {
+		if (n <= 0) return o;
+		if (typeof o[o.length - 1] === "number" && o[o.length - 1] > 0) o[o.length - 1] += n;
+		else o.push(n);
+		return o;
+	},
+	insert: function(o, s) {
+		if (s === "") return o;
+		var last = o.length - 1;
+		if (typeof o[last] === "string") {
+			o[last] += s;
+		} else if (typeof o[last] === "number" && o[last] < 0) {
+			// 서버와 같이 삽입을 항상 삭제 앞에 둡니다.
+			if (typeof o[last - 1] === "string") o[last - 1] += s;
+			else { o.push(o[last]); o[last] = s; }
+		} else {
+			o.push(s);
+		}
+		return o;
+	},
+	del: function(o, n) {
+		if (n <= 0) return o;
+		if (typeof o[o.length - 1] === "number" && o[o.length - 1] < 0) o[o.length - 1] -= n;
+		else o.push(-n);
+		return o;
+	},
+	isNoop: function(o) {
+		return o.length === 0 || (o.length === 1 && typeof o[0] === "number" && o[0] > 0);
+	},
+	apply: function(o, s) {
+		var out = "", i = 0;
+		for (var k = 0; k < o.length; k++) {
+			var c = o[k];
+			if (typeof c === "string") out += c;
+			else if (c > 0) { out += s.slice(i, i + c); i += c; }
+			else i -= c;
+		}
+		return out + s.slice(i);
+	},
+	// transform은 같은 문서에 대한 동시 연산 a, b로부터 [a', b']을 만듭니다.
+	// 같은 위치에 삽입하면 a가 앞에 옵니다.
+	transform: function(a, b) {
+		var a1 = [], b1 = [], i = 0, j = 0, x = a[0], y = b[0];
+		while (x !== undefined || y !== undefined) {
+			if (typeof x === "string") { ot.insert(a1, x); ot.retain(b1, x.length); x = a[++i]; continue; }
+			if (typeof y === "string") { ot.retain(a1, y.length); ot.insert(b1, y); y = b[++j]; continue; }
+			if (x === undefined || y === undefined) throw new Error("operations do not match");
+			var n = Math.min(Math.abs(x), Math.abs(y));
+			if (x > 0 && y > 0) { ot.retain(a1, n); ot.retain(b1, n); }
+			else if (x < 0 && y > 0) ot.del(a1, n);
+			else if (x > 0 && y < 0) ot.del(b1, n);
+			x = x > 0 ? x - n : x + n;
+			y = y > 0 ? y - n : y + n;
+			if (x === 0) x = a[++i];
+			if (y === 0) y = b[++j];
+		}
+		return [a1, b1];
+	},
+	// compose는 a를 적용한 뒤 b를 적용하는 것과 같은 연산 하나를 만듭니다.
+	compose: function(a, b) {
+		var out = [], i = 0, j = 0, x = a[0], y = b[0];
+		while (x !== undefined || y !== undefined) {
+			if (typeof x === "number" && x < 0) { ot.del(out, -x); x = a[++i]; continue; }
+			if (typeof y === "string") { ot.insert(out, y); y = b[++j]; continue; }
+			if (x === undefined || y === undefined) throw new Error("operations do not match");
+			if (typeof x === "string") {
+				var n = Math.min(x.length, Math.abs(y));
+				if (y > 0) ot.insert(out, x.slice(0, n));
+				x = x.length > n ? x.slice(n) : a[++i];
+				y = y > 0 ? y - n : y + n;
+				if (y === 0) y = b[++j];
+				continue;
+			}
+			var m = Math.min(x, Math.abs(y));
+			if (y > 0) ot.retain(out, m); else ot.del(out, m);
+			x -= m;
+			y = y > 0 ? y - m : y + m;
+			if (x === 0) x = a[++i];
+			if (y === 0) y = b[++j];
+		}
+		return out;
+	},
+	transformIndex: function(o, pos) {
+		var newPos = pos, i = 0;
+		for (var k = 0; k < o.length && i <= pos; k++) {
+			var c = o[k];
+			if (typeof c === "string") newPos += c.length;
+			else if (c > 0) i += c;
+			else { newPos -= Math.min(pos - i, -c); i -= c; }
+		}
+		return newPos;
+	},
+	// diff는 before를 after로 바꾸는 연산을 공통 접두사와 접미사를 제외한 부분의 교체로 만듭니다.
+	diff: function(before, after) {
+		var start = 0, end = 0;
+		while (start < before.length && start < after.length && before[start] === after[start]) start++;
+		while (end < before.length - start && end < after.length - start &&
+			before[before.length - 1 - end] === after[after.length - 1 - end]) end++;
+		var o = ot.retain([], start);
+		ot.insert(o, after.slice(start, after.length - end));
+		ot.del(o, before.length - start - end);
+		return ot.retain(o, end);
+	}
+};
+
+var editor = document.getElementById("collab-editor");
+if (editor && window.WebSocket) {
+	var text = editor.querySelector(".collab-text");
+	var status = editor.querySelector(".collab-status");
+	var peerList = editor.querySelector(".collab-peers");
+	var preview = editor.querySelector(".collab-preview");
+	var saveButton = editor.querySelector(".collab-save");
+
+	// rev는 서버에서 확인된 마지막 리비전입니다. outstanding은 보냈지만 아직 ack를 받지 못한
+	// 연산이고, buffer는 그동안 쌓인 로컬 편집입니다. 서버는 한 번에 하나의 연산만 받습니다.
+	var rev = 0, outstanding = null, buffer = null, shadow = text.value, cursorDirty = false;
+	var peers = {};
+
+	var proto = window.location.protocol === "https:" ? "wss://" : "ws://";
+	var ws = new WebSocket(proto + window.location.host + editor.dataset.ws);
+
+	var setStatus = function(msg, isError) {
+		status.textContent = msg;
+		status.classList.toggle("error", !!isError);
+	};
+
+	var send = function(msg) {
+		if (ws.readyState === WebSocket.OPEN) ws.send(JSON.stringify(msg));
+	};
+
+	// 다른 편집자의 커서는 서버 리비전 기준이므로 아직 확인되지 않은 로컬 편집에 맞춰 옮깁니다.
+	var localIndex = function(pos) {
+		if (outstanding) pos = ot.transformIndex(outstanding, pos);
+		if (buffer) pos = ot.transformIndex(buffer, pos);
+		return pos;
+	};
+
+	var render = function() {
+		peerList.textContent = "";
+		preview.textContent = "";
+
+		var marks = [];
+		Object.keys(peers).forEach(function(id) {
+			var p = peers[id];
+			var name = document.createElement("span");
+			name.className = "peer peer-" + (id % 6);
+			name.textContent = p.name;
+			peerList.appendChild(name);
+
+			var pos = localIndex(p.pos), end = localIndex(p.end);
+			marks.push({ at: Math.min(pos, end), end: Math.max(pos, end), peer: p, cls: "peer-" + (id % 6) });
+		});
+		marks.sort(function(a, b) { return a.at - b.at; });
+
+		// 미리보기에 각 편집자의 커서와 선택 영역을 표시합니다.
+		var doc = text.value, i = 0;
+		marks.forEach(function(m) {
+			if (m.at < i) return;
+			preview.appendChild(document.createTextNode(doc.slice(i, m.at)));
+			var caret = document.createElement("span");
+			caret.className = "caret " + m.cls;
+			caret.title = m.peer.name;
+			preview.appendChild(caret);
+			var selected = document.createElement("span");
+			selected.className = "selection";
+			selected.textContent = doc.slice(m.at, m.end);
+			preview.appendChild(selected);
+			i = m.end;
+		});
+		preview.appendChild(document.createTextNode(doc.slice(i)));
+	};
+
+	var sendCursor = function() {
+		// 커서 위치는 서버 리비전 기준이어야 하므로 로컬 편집이 모두 확인된 뒤에 보냅니다.
+		if (outstanding) { cursorDirty = true; return; }
+		cursorDirty = false;
+		send({ type: "cursor", rev: rev, pos: text.selectionStart, end: text.selectionEnd });
+	};
+
+	var onLocalChange = function() {
+		var op = ot.diff(shadow, text.value);
+		shadow = text.value;
+		if (ot.isNoop(op)) return;
+
+		if (!outstanding) {
+			outstanding = op;
+			send({ type: "op", rev: rev, op: op });
+		} else {
+			buffer = buffer ? ot.compose(buffer, op) : op;
+		}
+		render();
+	};
+
+	var applyRemote = function(op) {
+		if (outstanding) {
+			var pair = ot.transform(outstanding, op);
+			outstanding = pair[0];
+			op = pair[1];
+		}
+		if (buffer) {
+			pair = ot.transform(buffer, op);
+			buffer = pair[0];
+			op = pair[1];
+		}
+
+		var start = ot.transformIndex(op, text.selectionStart);
+		var end = ot.transformIndex(op, text.selectionEnd);
+		text.value = ot.apply(op, text.value);
+		shadow = text.value;
+		if (document.activeElement === text) text.setSelectionRange(start, end);
+	};
+
+	ws.onmessage = function(e) {
+		var msg = JSON.parse(e.data);
+
+		switch (msg.type) {
+		case "init":
+			rev = msg.rev;
+			text.value = shadow = msg.content || "";
+			text.disabled = false;
+			if (saveButton) saveButton.disabled = !msg.canSave;
+			(msg.peers || []).forEach(function(p) { peers[p.client] = p; });
+			setStatus("Connected. Changes are shared as you type.");
+			break;
+		case "ack":
+			rev = msg.rev;
+			outstanding = buffer;
+			buffer = null;
+			if (outstanding) send({ type: "op", rev: rev, op: outstanding });
+			else if (cursorDirty) sendCursor();
+			break;
+		case "op":
+			rev = msg.rev;
+			applyRemote(msg.op);
+			Object.keys(peers).forEach(function(id) {
+				peers[id].pos = ot.transformIndex(msg.op, peers[id].pos);
+				peers[id].end = ot.transformIndex(msg.op, peers[id].end);
+			});
+			break;
+		case "cursor":
+		case "join":
+			peers[msg.client] = { client: msg.client, name: msg.name, pos: msg.pos, end: msg.end };
+			break;
+		case "leave":
+			delete peers[msg.client];
+			break;
+		case "saved":
+			setStatus("Revision " + msg.revision + " saved by " + msg.name + ".");
+			break;
+		case "error":
+			setStatus(msg.message, true);
+			break;
+		}
+		render();
+	};
+
+	ws.onclose = function() {
+		text.disabled = true;
+		if (saveButton) saveButton.disabled = true;
+		setStatus("Disconnected. Reload the page to continue editing.", true);
+	};
+
+	text.addEventListener("input", onLocalChange);
+	text.addEventListener("select", sendCursor);
+	text.addEventListener("keyup", sendCursor);
+	text.addEventListener("mouseup", sendCursor);
+	if (saveButton) {
+		saveButton.addEventListener("click", function() { send({ type: "save" }); });
+	}
+}